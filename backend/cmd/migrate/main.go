@@ -46,8 +46,12 @@ func runMigrationsUp(c *cli.Context) error {
 	defer database.CloseDatabase(db)
 
 	// 运行迁移
-	migrations := getAllMigrations()
+	migrations := database.GetAllMigrations()
 	for _, migration := range migrations {
+		// 跳过已应用的迁移
+		if migration.Status(db) == "applied" {
+			continue
+		}
 		if err := migration.Up(db); err != nil {
 			return fmt.Errorf("failed to run migration %s: %w", migration.Name(), err)
 		}
@@ -67,7 +71,7 @@ func runMigrationsDown(c *cli.Context) error {
 	defer database.CloseDatabase(db)
 
 	// 回滚最后一个迁移
-	migrations := getAllMigrations()
+	migrations := database.GetAllMigrations()
 	if len(migrations) == 0 {
 		log.Println("No migrations to rollback")
 		return nil
@@ -91,7 +95,7 @@ func showMigrationStatus(c *cli.Context) error {
 	defer database.CloseDatabase(db)
 
 	// 检查迁移状态
-	migrations := getAllMigrations()
+	migrations := database.GetAllMigrations()
 	for _, migration := range migrations {
 		status := migration.Status(db)
 		log.Printf("Migration %s: %s", migration.Name(), status)
@@ -1,21 +1,52 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"container-platform-backend/internal/api"
+	"container-platform-backend/internal/database"
+	"container-platform-backend/internal/k8s"
+	"container-platform-backend/internal/middleware"
+	"container-platform-backend/internal/services"
 )
 
 func main() {
 	log.Println("Starting Container Platform Backend Server...")
 
+	// 连接数据库
+	db, err := database.NewDatabase(getDatabaseConfig())
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.CloseDatabase(db)
+
+	// 注册内置的 local 连接
+	bootstrapLocalConnection(services.NewConnectionService(db))
+
+	// JWT 认证
+	jwtConfig := middleware.DefaultJWTConfig()
+	jwtConfig.SecretKey = getEnvOrDefault("JWT_SECRET", jwtConfig.SecretKey)
+	jwtAuth := middleware.NewJWTAuth(jwtConfig)
+
 	// 创建路由器
-	router := api.NewRouter()
+	router := api.NewRouter(db, jwtAuth)
 
 	// 设置路由
 	router.Setup()
 
+	// 启动集群健康检查
+	healthMonitor := router.GetHealthMonitor()
+	healthMonitor.Start(getDurationEnv("CLUSTER_HEALTH_INTERVAL", time.Minute))
+	defer healthMonitor.Stop()
+
+	// 继续跟踪重启前未完成的镜像发布
+	rolloutService := router.GetRolloutService()
+	rolloutService.Start()
+	defer rolloutService.Stop()
+
 	// 启动服务器
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -26,4 +57,76 @@ func main() {
 	if err := router.GetEngine().Run(":" + port); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-}
\ No newline at end of file
+}
+
+// bootstrapLocalConnection 根据运行模式注册内置的 local 连接
+// LOCAL_CLUSTER_MODE: auto（默认，在集群内运行或配置了 KUBECONFIG_PATH 时启用）、enabled、disabled
+func bootstrapLocalConnection(connectionService *services.ConnectionService) {
+	mode := getEnvOrDefault("LOCAL_CLUSTER_MODE", "auto")
+	configPath := os.Getenv("KUBECONFIG_PATH")
+
+	switch mode {
+	case "disabled":
+		return
+	case "auto":
+		if configPath == "" && os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+			return
+		}
+	case "enabled":
+	default:
+		log.Printf("Warning: unknown LOCAL_CLUSTER_MODE %q, local connection disabled", mode)
+		return
+	}
+
+	namespace := getEnvOrDefault("LOCAL_CLUSTER_NAMESPACE", "default")
+	client, err := k8s.NewClient(configPath, namespace)
+	if err != nil {
+		log.Printf("Warning: failed to load local cluster config: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.HealthCheck(ctx); err != nil {
+		// 集群暂时不可用时仍注册连接，由健康检查反映状态
+		log.Printf("Warning: local cluster health check failed: %v", err)
+	}
+
+	connection, err := connectionService.EnsureLocalConnection(configPath, client.GetNamespace(), client.GetConfig().Host)
+	if err != nil {
+		log.Printf("Warning: failed to register local connection: %v", err)
+		return
+	}
+	log.Printf("Local Kubernetes connection registered: %s (%s)", connection.Name, connection.Endpoint)
+}
+
+func getDatabaseConfig() *database.Config {
+	config := database.DefaultConfig()
+	config.Host = getEnvOrDefault("DATABASE_HOST", config.Host)
+	config.Port = getEnvOrDefault("DATABASE_PORT", config.Port)
+	config.Name = getEnvOrDefault("DATABASE_NAME", config.Name)
+	config.User = getEnvOrDefault("DATABASE_USER", config.User)
+	config.Password = getEnvOrDefault("DATABASE_PASSWORD", config.Password)
+	config.SSLMode = getEnvOrDefault("DATABASE_SSLMODE", config.SSLMode)
+	return config
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/model"
+	"container-platform-backend/internal/services"
+)
+
+// newOperationLog 根据请求上下文构建操作日志，用户信息来自认证中间件
+func newOperationLog(ctx *gin.Context, action, resourceType, resourceName string) *model.OperationLog {
+	entry := &model.OperationLog{
+		Action:        action,
+		ResourceType:  resourceType,
+		ResourceName:  resourceName,
+		RequestMethod: ctx.Request.Method,
+		RequestPath:   ctx.Request.URL.Path,
+		ClientIP:      ctx.ClientIP(),
+		UserAgent:     ctx.Request.UserAgent(),
+		StartedAt:     time.Now(),
+		Metadata:      model.JSONB{},
+	}
+
+	if userID, ok := ctx.Get("user_id"); ok {
+		if id, ok := userID.(uint); ok {
+			entry.UserID = &id
+		}
+	}
+	entry.Username = ctx.GetString("username")
+
+	return entry
+}
+
+// recordOperation 写入操作日志，失败只记录日志，不影响请求结果
+func recordOperation(operationLogService *services.OperationLogService, entry *model.OperationLog) {
+	if operationLogService == nil {
+		return
+	}
+
+	if entry.CompletedAt == nil {
+		now := time.Now()
+		entry.CompletedAt = &now
+	}
+	if err := operationLogService.Record(entry); err != nil {
+		log.Printf("Failed to record operation %s on %s %s: %v", entry.Action, entry.ResourceType, entry.ResourceName, err)
+	}
+}
+
+// finishOperationLog 补全耗时和结果并写入审计日志
+func finishOperationLog(operationLogService *services.OperationLogService, entry *model.OperationLog, err error) {
+	now := time.Now()
+	duration := int(now.Sub(entry.StartedAt).Milliseconds())
+	entry.CompletedAt = &now
+	entry.DurationMs = &duration
+
+	statusCode := http.StatusOK
+	if err != nil {
+		statusCode = containerErrorStatus(err)
+		entry.ErrorMessage = err.Error()
+	}
+	entry.StatusCode = &statusCode
+	recordOperation(operationLogService, entry)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/services"
+)
+
+const (
+	// defaultHealthHistoryLimit 默认返回的健康检查记录数量
+	defaultHealthHistoryLimit = 50
+	// maxHealthHistoryLimit 单次返回的健康检查记录数量上限
+	maxHealthHistoryLimit = 1000
+)
+
+// ClusterHealthController 集群健康状态控制器
+type ClusterHealthController struct {
+	connectionService *services.ConnectionService
+	healthMonitor     *services.HealthMonitor
+}
+
+// NewClusterHealthController 创建集群健康状态控制器
+func NewClusterHealthController(connectionService *services.ConnectionService, healthMonitor *services.HealthMonitor) *ClusterHealthController {
+	return &ClusterHealthController{
+		connectionService: connectionService,
+		healthMonitor:     healthMonitor,
+	}
+}
+
+// GetHealth 获取连接的当前健康状态
+// @Summary 获取集群健康状态
+// @Description 获取连接最近一次后台检查得到的健康状态（unknown/healthy/degraded/unreachable）
+// @Tags k8s
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} APIResponse{data=services.ClusterHealth}
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id}/health [get]
+func (c *ClusterHealthController) GetHealth(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	connection, err := c.connectionService.GetConnection(id)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to get connection", err)
+		return
+	}
+
+	health, err := c.healthMonitor.GetHealth(connection)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get cluster health", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Cluster health retrieved successfully", health)
+}
+
+// GetHealthHistory 获取连接的健康检查历史
+// @Summary 获取集群健康检查历史
+// @Description 按检查时间倒序返回连接的健康检查记录（版本、延迟、可达性、认证错误）
+// @Tags k8s
+// @Produce json
+// @Param id path int true "连接ID"
+// @Param limit query int false "返回数量" default(50)
+// @Success 200 {object} APIResponse{data=[]model.ClusterHealthCheck}
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id}/health/history [get]
+func (c *ClusterHealthController) GetHealthHistory(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	limit := defaultHealthHistoryLimit
+	if value := ctx.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid limit", err)
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxHealthHistoryLimit {
+		limit = maxHealthHistoryLimit
+	}
+
+	if _, err := c.connectionService.GetConnection(id); err != nil {
+		connectionErrorResponse(ctx, "Failed to get connection", err)
+		return
+	}
+
+	history, err := c.healthMonitor.GetHistory(id, limit)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get cluster health history", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Cluster health history retrieved successfully", history)
+}
+
+// CheckHealth 立即检查连接的健康状态
+// @Summary 立即检查集群健康状态
+// @Description 立即探测集群并记录检查结果，不等待后台检查周期
+// @Tags k8s
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} APIResponse{data=model.ClusterHealthCheck}
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id}/health/check [post]
+func (c *ClusterHealthController) CheckHealth(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	connection, err := c.connectionService.GetConnection(id)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to get connection", err)
+		return
+	}
+
+	check, err := c.healthMonitor.Check(connection)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check cluster health", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Cluster health checked successfully", check)
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/model"
+	"container-platform-backend/internal/services"
+)
+
+// maxKubeconfigSize 上传的 kubeconfig 文件大小上限
+const maxKubeconfigSize = 1 << 20
+
+// KubeconfigRequest kubeconfig 解析请求
+type KubeconfigRequest struct {
+	Config string `json:"config" binding:"required"`
+}
+
+// ConnectionController Kubernetes 连接管理控制器
+type ConnectionController struct {
+	connectionService   *services.ConnectionService
+	k8sService          *services.K8sService
+	operationLogService *services.OperationLogService
+}
+
+// NewConnectionController 创建连接管理控制器
+func NewConnectionController(
+	connectionService *services.ConnectionService,
+	k8sService *services.K8sService,
+	operationLogService *services.OperationLogService,
+) *ConnectionController {
+	return &ConnectionController{
+		connectionService:   connectionService,
+		k8sService:          k8sService,
+		operationLogService: operationLogService,
+	}
+}
+
+// ListConnections 获取连接列表
+// @Summary 获取连接列表
+// @Description 获取所有已注册的 Kubernetes 集群连接（不含凭据）
+// @Tags k8s
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]model.K8sConnection}
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/connections [get]
+func (c *ConnectionController) ListConnections(ctx *gin.Context) {
+	connections, err := c.connectionService.ListConnections()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list connections", err)
+		return
+	}
+
+	result := make([]model.K8sConnection, 0, len(connections))
+	for _, connection := range connections {
+		result = append(result, services.SanitizeConnection(connection))
+	}
+
+	SuccessResponse(ctx, "Connections retrieved successfully", result)
+}
+
+// GetConnection 获取连接详情
+// @Summary 获取连接详情
+// @Description 获取指定的 Kubernetes 集群连接（不含凭据）
+// @Tags k8s
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} APIResponse{data=model.K8sConnection}
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id} [get]
+func (c *ConnectionController) GetConnection(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	connection, err := c.connectionService.GetConnection(id)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to get connection", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Connection retrieved successfully", services.SanitizeConnection(*connection))
+}
+
+// CreateConnection 创建连接
+// @Summary 创建连接
+// @Description 注册一个新的 Kubernetes 集群连接
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param connection body services.ConnectionRequest true "连接配置"
+// @Success 200 {object} APIResponse{data=model.K8sConnection}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/connections [post]
+func (c *ConnectionController) CreateConnection(ctx *gin.Context) {
+	var req services.ConnectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	connection, err := c.connectionService.CreateConnection(&req)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to create connection", err)
+		return
+	}
+	c.auditInsecureTLS(ctx, "create", connection)
+
+	SuccessResponse(ctx, "Connection created successfully", services.SanitizeConnection(*connection))
+}
+
+// UpdateConnection 更新连接
+// @Summary 更新连接
+// @Description 更新指定的 Kubernetes 集群连接，凭据为空时保留原值
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param id path int true "连接ID"
+// @Param connection body services.ConnectionRequest true "连接配置"
+// @Success 200 {object} APIResponse{data=model.K8sConnection}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id} [put]
+func (c *ConnectionController) UpdateConnection(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	var req services.ConnectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	connection, err := c.connectionService.UpdateConnection(id, &req)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to update connection", err)
+		return
+	}
+	c.k8sService.InvalidateConnection(id)
+	c.auditInsecureTLS(ctx, "update", connection)
+
+	SuccessResponse(ctx, "Connection updated successfully", services.SanitizeConnection(*connection))
+}
+
+// DeleteConnection 删除连接
+// @Summary 删除连接
+// @Description 删除指定的 Kubernetes 集群连接，内置连接不可删除
+// @Tags k8s
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id} [delete]
+func (c *ConnectionController) DeleteConnection(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.connectionService.DeleteConnection(id); err != nil {
+		connectionErrorResponse(ctx, "Failed to delete connection", err)
+		return
+	}
+	c.k8sService.InvalidateConnection(id)
+
+	SuccessResponse(ctx, "Connection deleted successfully", nil)
+}
+
+// ActivateConnection 激活连接
+// @Summary 激活连接
+// @Description 将指定连接设为默认连接，未指定 connectionId 的容器接口将使用该连接
+// @Tags k8s
+// @Produce json
+// @Param id path int true "连接ID"
+// @Success 200 {object} APIResponse{data=model.K8sConnection}
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/connections/{id}/activate [post]
+func (c *ConnectionController) ActivateConnection(ctx *gin.Context) {
+	id, ok := parseConnectionID(ctx)
+	if !ok {
+		return
+	}
+
+	connection, err := c.connectionService.ActivateConnection(id)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to activate connection", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Connection activated successfully", services.SanitizeConnection(*connection))
+}
+
+// ListKubeconfigContexts 列出 kubeconfig 中的上下文
+// @Summary 列出 kubeconfig 上下文
+// @Description 解析上传的 kubeconfig（YAML 或 JSON），返回其中的上下文供创建连接时选择
+// @Tags k8s
+// @Accept json,mpfd
+// @Produce json
+// @Param kubeconfig body KubeconfigRequest false "kubeconfig 内容"
+// @Param file formData file false "kubeconfig 文件"
+// @Success 200 {object} APIResponse{data=[]services.KubeconfigContext}
+// @Failure 400 {object} APIResponse
+// @Router /api/k8s/kubeconfig/contexts [post]
+func (c *ConnectionController) ListKubeconfigContexts(ctx *gin.Context) {
+	var config string
+	if file, err := ctx.FormFile("file"); err == nil {
+		if file.Size > maxKubeconfigSize {
+			ErrorResponse(ctx, http.StatusBadRequest, "Kubeconfig file is too large", nil)
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Failed to read kubeconfig file", err)
+			return
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(io.LimitReader(f, maxKubeconfigSize))
+		if err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Failed to read kubeconfig file", err)
+			return
+		}
+		config = string(data)
+	} else {
+		var req KubeconfigRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		config = req.Config
+	}
+
+	contexts, err := services.ListKubeconfigContexts(config)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to parse kubeconfig", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Kubeconfig contexts retrieved successfully", contexts)
+}
+
+// auditInsecureTLS 连接显式跳过 TLS 校验时写入审计日志
+func (c *ConnectionController) auditInsecureTLS(ctx *gin.Context, action string, connection *model.K8sConnection) {
+	if !connection.InsecureSkipTLSVerify {
+		return
+	}
+
+	entry := newOperationLog(ctx, action, "k8s_connection", connection.Name)
+	entry.ResourceID = &connection.ID
+	entry.Metadata["insecureSkipTLSVerify"] = true
+	entry.Metadata["endpoint"] = connection.Endpoint
+	recordOperation(c.operationLogService, entry)
+}
+
+// parseConnectionID 解析路径中的连接ID
+func parseConnectionID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid connection id", err)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// connectionErrorResponse 根据连接服务错误返回对应的状态码
+func connectionErrorResponse(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrConnectionNotFound), errors.Is(err, services.ErrNoActiveConnection):
+		ErrorResponse(ctx, http.StatusNotFound, message, err)
+	case errors.Is(err, services.ErrInvalidConnection):
+		ErrorResponse(ctx, http.StatusBadRequest, message, err)
+	case errors.Is(err, services.ErrBuiltInConnection):
+		ErrorResponse(ctx, http.StatusForbidden, message, err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
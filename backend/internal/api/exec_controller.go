@@ -0,0 +1,289 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/model"
+	"container-platform-backend/internal/services"
+)
+
+const (
+	// defaultExecTimeout 一次性命令的默认超时时间
+	defaultExecTimeout = 30 * time.Second
+	// maxExecTimeout 一次性命令允许的最长超时时间
+	maxExecTimeout = 5 * time.Minute
+	// maxExecOutputBytes 一次性命令每个输出流最多返回的字节数
+	maxExecOutputBytes = 1 << 20
+)
+
+// ExecController 容器命令执行和交互式终端控制器
+type ExecController struct {
+	k8sService          *services.K8sService
+	connectionService   *services.ConnectionService
+	namespaceAccess     *services.NamespaceAccessService
+	sessions            *services.ExecSessionStore
+	operationLogService *services.OperationLogService
+}
+
+// NewExecController 创建容器命令执行控制器
+func NewExecController(
+	k8sService *services.K8sService,
+	connectionService *services.ConnectionService,
+	namespaceAccess *services.NamespaceAccessService,
+	sessions *services.ExecSessionStore,
+	operationLogService *services.OperationLogService,
+) *ExecController {
+	return &ExecController{
+		k8sService:          k8sService,
+		connectionService:   connectionService,
+		namespaceAccess:     namespaceAccess,
+		sessions:            sessions,
+		operationLogService: operationLogService,
+	}
+}
+
+// ExecSessionRequest 创建交互式终端会话的请求
+type ExecSessionRequest struct {
+	Namespace string `json:"namespace"`
+	PodName   string `json:"podName"`
+	services.ExecOptions
+}
+
+// ExecSessionResponse 已创建的终端会话，需在过期前连接 websocketUrl
+type ExecSessionResponse struct {
+	SessionID    string    `json:"sessionId"`
+	WebsocketURL string    `json:"websocketUrl"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// ExecCommandRequest 一次性执行命令的请求
+type ExecCommandRequest struct {
+	Container string   `json:"container"`
+	Command   []string `json:"command" binding:"required"`
+	// 超时秒数，默认 30 秒，最长 300 秒
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+// ExecCommandResponse 一次性执行命令的结果
+type ExecCommandResponse struct {
+	services.ExecResult
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+	// 输出超过 1MB 时被截断
+	Truncated bool `json:"truncated"`
+}
+
+// CreateExecSession 创建交互式终端会话
+// @Summary 创建交互式终端会话
+// @Description 校验命名空间写权限后创建一次性的终端会话，客户端需在 1 分钟内连接返回的 websocketUrl。
+// @Description 未指定 command 时启动 shell（优先 bash）；tty=true 时分配终端，stdin=true 时转发输入。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param session body ExecSessionRequest true "会话参数"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=ExecSessionResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/exec [post]
+func (c *ExecController) CreateExecSession(ctx *gin.Context) {
+	var req ExecSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	// 兼容前端的请求格式：只提供 container 时将其视为 Pod 名称
+	if req.PodName == "" {
+		req.PodName, req.Container = req.Container, ""
+	}
+	if req.PodName == "" {
+		ValidationError(ctx, services.ValidationErrors{{Field: "podName", Message: "is required"}})
+		return
+	}
+	if len(req.Command) == 0 {
+		req.Command = services.DefaultShellCommand
+		req.TTY, req.Stdin = true, true
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+	if req.Namespace == "" {
+		req.Namespace = connection.Namespace
+	}
+	if req.Namespace == "" {
+		req.Namespace = "default"
+	}
+
+	if err := c.namespaceAccess.Authorize(requestContext(ctx), req.Namespace, services.NamespacePermissionWrite); err != nil {
+		containerErrorResponse(ctx, "Not allowed to exec into containers in this namespace", err)
+		return
+	}
+
+	user, _ := services.PlatformUserFromContext(requestContext(ctx))
+	session := c.sessions.Create(&services.ExecSession{
+		Connection: connection,
+		Namespace:  req.Namespace,
+		PodName:    req.PodName,
+		Options:    req.ExecOptions,
+		User:       user,
+		Audit:      c.execOperationLog(ctx, connection, req.Namespace, req.PodName, &req.ExecOptions),
+	})
+
+	SuccessResponse(ctx, "Exec session created successfully", ExecSessionResponse{
+		SessionID:    session.ID,
+		WebsocketURL: "/api/k8s/containers/exec/" + session.ID,
+		ExpiresAt:    session.ExpiresAt,
+	})
+}
+
+// ExecTerminal 连接交互式终端
+// @Summary 连接交互式终端
+// @Description WebSocket 接口。客户端发送 {"type":"stdin","data":"..."} 输入和 {"type":"resize","cols":80,"rows":24} 调整终端尺寸；
+// @Description 服务端推送 {"type":"stdout"|"stderr","data":"..."}，命令结束时推送 {"type":"exit","code":0}，失败时推送 {"type":"error","data":"..."}，随后关闭连接。
+// @Description 客户端断开时终止会话，会话结束后写入操作日志。
+// @Tags k8s
+// @Param sessionId path string true "会话ID"
+// @Success 101
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/containers/exec/{sessionId} [get]
+func (c *ExecController) ExecTerminal(ctx *gin.Context) {
+	session, ok := c.sessions.Take(ctx.Param("sessionId"))
+	if !ok {
+		ErrorResponse(ctx, http.StatusNotFound, "Exec session not found or expired", nil)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade exec session %s to websocket: %v", session.ID, err)
+		return
+	}
+
+	// WebSocket 升级后请求上下文不再随客户端断开而取消，由终端读取协程负责取消
+	execCtx, cancel := context.WithCancel(services.WithPlatformUser(context.Background(), session.User))
+	defer cancel()
+
+	terminal := newTerminalSession(conn, session.Options.Stdin, cancel)
+	defer terminal.close()
+
+	result, err := c.k8sService.ExecContainer(execCtx, session.Connection, session.Namespace, session.PodName, &session.Options, terminal.streams(session.Options.TTY))
+	if err != nil && execCtx.Err() == nil {
+		terminal.fail(err)
+	} else if result != nil {
+		terminal.exit(result.ExitCode)
+	}
+
+	c.finishExecOperationLog(session.Audit, result, err)
+}
+
+// ExecCommand 在容器中执行一次性命令
+// @Summary 在容器中执行一次性命令
+// @Description 校验命名空间写权限后执行命令并返回退出码和输出（不分配终端、无标准输入），每个输出流最多返回 1MB。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param command body ExecCommandRequest true "命令"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=ExecCommandResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 504 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/exec [post]
+func (c *ExecController) ExecCommand(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var req ExecCommandRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	timeout := defaultExecTimeout
+	if req.TimeoutSeconds != 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	if timeout <= 0 || timeout > maxExecTimeout {
+		ValidationError(ctx, services.ValidationErrors{{Field: "timeoutSeconds", Message: fmt.Sprintf("must be between 1 and %d", int(maxExecTimeout.Seconds()))}})
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	if err := c.namespaceAccess.Authorize(requestContext(ctx), namespace, services.NamespacePermissionWrite); err != nil {
+		containerErrorResponse(ctx, "Not allowed to exec into containers in this namespace", err)
+		return
+	}
+
+	opts := &services.ExecOptions{Container: req.Container, Command: req.Command}
+	entry := c.execOperationLog(ctx, connection, namespace, podName, opts)
+
+	execCtx, cancel := context.WithTimeout(requestContext(ctx), timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxExecOutputBytes}
+	stderr := &limitedBuffer{limit: maxExecOutputBytes}
+	result, err := c.k8sService.ExecContainer(execCtx, connection, namespace, podName, opts, services.ExecStreams{Stdout: stdout, Stderr: stderr})
+	c.finishExecOperationLog(entry, result, err)
+	if err != nil {
+		if errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+			ErrorResponse(ctx, http.StatusGatewayTimeout, "Command timed out", err)
+			return
+		}
+		containerErrorResponse(ctx, "Failed to exec command", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Command executed successfully", ExecCommandResponse{
+		ExecResult: *result,
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		Truncated:  stdout.truncated || stderr.truncated,
+	})
+}
+
+// execOperationLog 构建 exec 的审计日志，结果在执行结束后补全
+func (c *ExecController) execOperationLog(ctx *gin.Context, connection *model.K8sConnection, namespace, podName string, opts *services.ExecOptions) *model.OperationLog {
+	entry := newOperationLog(ctx, "exec", "container", namespace+"/"+podName)
+	entry.OperationID = services.NewOperationID()
+	entry.Metadata["connectionId"] = connection.ID
+	entry.Metadata["cluster"] = connection.Name
+	entry.Metadata["container"] = opts.Container
+	entry.Metadata["command"] = opts.Command
+	entry.Metadata["tty"] = opts.TTY
+	entry.Metadata["stdin"] = opts.Stdin
+	return entry
+}
+
+// finishExecOperationLog 补全 exec 的结果并写入审计日志
+func (c *ExecController) finishExecOperationLog(entry *model.OperationLog, result *services.ExecResult, err error) {
+	if result != nil {
+		entry.Metadata["pod"] = result.PodName
+		entry.Metadata["container"] = result.Container
+		entry.Metadata["exitCode"] = result.ExitCode
+	}
+	finishOperationLog(c.operationLogService, entry, err)
+}
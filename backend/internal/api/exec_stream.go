@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"container-platform-backend/internal/services"
+)
+
+// 终端 WebSocket 消息类型
+const (
+	terminalStdin  = "stdin"
+	terminalResize = "resize"
+	terminalStdout = "stdout"
+	terminalStderr = "stderr"
+	terminalExit   = "exit"
+	terminalError  = "error"
+)
+
+// terminalMessage 终端 WebSocket 消息
+type terminalMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	Code *int   `json:"code,omitempty"`
+}
+
+// terminalSession 将 WebSocket 连接桥接为 exec 的输入输出和终端尺寸队列
+type terminalSession struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	// 会话未开启 stdin 时为空，客户端发送的输入被丢弃
+	stdinR *io.PipeReader
+	stdinW *io.PipeWriter
+	sizes  chan remotecommand.TerminalSize
+	done   chan struct{}
+}
+
+// newTerminalSession 创建终端会话并开始读取客户端消息，客户端断开时调用 cancel
+// stdin 为 false 时 exec 不会读取输入，不创建输入管道，避免写入阻塞读取循环
+func newTerminalSession(conn *websocket.Conn, stdin bool, cancel context.CancelFunc) *terminalSession {
+	t := &terminalSession{
+		conn:  conn,
+		sizes: make(chan remotecommand.TerminalSize, 1),
+		done:  make(chan struct{}),
+	}
+	if stdin {
+		t.stdinR, t.stdinW = io.Pipe()
+	}
+	go t.readLoop(cancel)
+	go t.pingLoop(cancel)
+	return t
+}
+
+// streams 返回 exec 使用的输入输出
+func (t *terminalSession) streams(tty bool) services.ExecStreams {
+	streams := services.ExecStreams{
+		Stdout: &terminalWriter{session: t, stream: terminalStdout},
+		Stderr: &terminalWriter{session: t, stream: terminalStderr},
+	}
+	if t.stdinR != nil {
+		streams.Stdin = t.stdinR
+	}
+	if tty {
+		streams.Resize = t
+	}
+	return streams
+}
+
+// Next 实现 remotecommand.TerminalSizeQueue，会话结束时返回 nil
+func (t *terminalSession) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-t.sizes:
+		return &size
+	case <-t.done:
+		return nil
+	}
+}
+
+// readLoop 读取客户端消息：stdin 写入输入管道（未开启 stdin 时丢弃），resize 放入尺寸队列（只保留最新的尺寸）
+func (t *terminalSession) readLoop(cancel context.CancelFunc) {
+	defer cancel()
+	if t.stdinW != nil {
+		defer t.stdinW.Close()
+	}
+
+	for {
+		_, data, err := t.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg terminalMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case terminalStdin:
+			if t.stdinW == nil {
+				continue
+			}
+			if _, err := t.stdinW.Write([]byte(msg.Data)); err != nil {
+				return
+			}
+		case terminalResize:
+			if msg.Cols == 0 || msg.Rows == 0 {
+				continue
+			}
+			size := remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
+			select {
+			case <-t.sizes:
+			default:
+			}
+			t.sizes <- size
+		}
+	}
+}
+
+// pingLoop 定时发送心跳，发现已断开的客户端
+func (t *terminalSession) pingLoop(cancel context.CancelFunc) {
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			if err := t.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// send 发送一条消息，多个输出流并发写入时串行化
+func (t *terminalSession) send(msg terminalMessage) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return t.conn.WriteJSON(msg)
+}
+
+// exit 通知客户端命令已结束
+func (t *terminalSession) exit(code int) {
+	t.send(terminalMessage{Type: terminalExit, Code: &code})
+}
+
+// fail 通知客户端执行失败
+func (t *terminalSession) fail(err error) {
+	t.send(terminalMessage{Type: terminalError, Data: err.Error()})
+}
+
+// close 结束会话并关闭连接
+func (t *terminalSession) close() {
+	close(t.done)
+	if t.stdinR != nil {
+		t.stdinR.Close()
+	}
+	t.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(streamWriteTimeout))
+	t.conn.Close()
+}
+
+// terminalWriter 将输出流写成终端消息，跨两次写入的多字节字符会被拼接后再发送
+type terminalWriter struct {
+	session *terminalSession
+	stream  string
+	pending []byte
+}
+
+func (w *terminalWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	cut := incompleteRuneStart(data)
+	w.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+
+	if err := w.session.send(terminalMessage{Type: w.stream, Data: string(data[:cut])}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// incompleteRuneStart 返回末尾不完整的 UTF-8 字符的起始位置，没有时返回 len(data)
+func incompleteRuneStart(data []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		start := len(data) - i
+		if utf8.RuneStart(data[start]) {
+			if !utf8.FullRune(data[start:]) {
+				return start
+			}
+			break
+		}
+	}
+	return len(data)
+}
+
+// limitedBuffer 最多保存 limit 字节的缓冲区，超出部分丢弃并标记为截断
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.Buffer.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
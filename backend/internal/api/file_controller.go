@@ -0,0 +1,267 @@
+package api
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/model"
+	"container-platform-backend/internal/services"
+)
+
+const (
+	// maxFileDownloadBytes 单次从容器下载的最大字节数
+	maxFileDownloadBytes int64 = 2 << 30
+	// maxFileUploadBytes 单次上传到容器的最大字节数
+	maxFileUploadBytes int64 = 1 << 30
+)
+
+// 文件传输格式
+const (
+	fileFormatTar = "tar"
+	fileFormatRaw = "raw"
+)
+
+// FileController 容器文件上传和下载控制器
+type FileController struct {
+	k8sService          *services.K8sService
+	connectionService   *services.ConnectionService
+	namespaceAccess     *services.NamespaceAccessService
+	operationLogService *services.OperationLogService
+}
+
+// NewFileController 创建容器文件传输控制器
+func NewFileController(
+	k8sService *services.K8sService,
+	connectionService *services.ConnectionService,
+	namespaceAccess *services.NamespaceAccessService,
+	operationLogService *services.OperationLogService,
+) *FileController {
+	return &FileController{
+		k8sService:          k8sService,
+		connectionService:   connectionService,
+		namespaceAccess:     namespaceAccess,
+		operationLogService: operationLogService,
+	}
+}
+
+// DownloadFile 从容器下载文件或目录
+// @Summary 从容器下载文件或目录
+// @Description 校验命名空间写权限后通过 exec 流式下载，不在服务端缓存内容。format=tar（默认）返回 tar 归档（与 kubectl cp 相同，支持目录），
+// @Description format=raw 返回单个文件的内容。最大 2GB（tar 包括头部和补齐），超过时返回 413；传输开始后出错时连接被中断。下载结果写入操作日志。
+// @Tags k8s
+// @Produce application/x-tar
+// @Produce application/octet-stream
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param path query string true "容器中的绝对路径"
+// @Param container query string false "容器名称，默认第一个容器"
+// @Param format query string false "tar 或 raw，默认 tar"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {file} file
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 413 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/files [get]
+func (c *FileController) DownloadFile(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+	filePath := ctx.Query("path")
+	if filePath == "" {
+		ValidationError(ctx, services.ValidationErrors{{Field: "path", Message: "is required"}})
+		return
+	}
+	format := ctx.DefaultQuery("format", fileFormatTar)
+	if format != fileFormatTar && format != fileFormatRaw {
+		ValidationError(ctx, services.ValidationErrors{{Field: "format", Message: "must be tar or raw"}})
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	if err := c.namespaceAccess.Authorize(requestContext(ctx), namespace, services.NamespacePermissionWrite); err != nil {
+		containerErrorResponse(ctx, "Not allowed to copy files in this namespace", err)
+		return
+	}
+
+	entry := c.fileOperationLog(ctx, "file_download", connection, namespace, podName, filePath)
+	entry.Metadata["format"] = format
+
+	file, err := c.k8sService.StatContainerPath(requestContext(ctx), connection, namespace, podName, ctx.Query("container"), filePath)
+	if err != nil {
+		finishOperationLog(c.operationLogService, entry, err)
+		containerErrorResponse(ctx, "Failed to download file", err)
+		return
+	}
+	entry.Metadata["pod"] = file.PodName
+	entry.Metadata["container"] = file.Container
+	entry.Metadata["dir"] = file.Dir
+	entry.Metadata["size"] = file.Size
+
+	// 文件在获取大小后仍可能变化，不发送 Content-Length，以分块编码传输实际读取的内容
+	writer := &attachmentWriter{ctx: ctx, contentType: "application/x-tar", filename: path.Base(file.Path) + ".tar"}
+	if format == fileFormatRaw {
+		writer.contentType, writer.filename = "application/octet-stream", path.Base(file.Path)
+	}
+
+	transfer, err := c.k8sService.DownloadFromContainer(requestContext(ctx), connection, namespace, file, format == fileFormatTar, maxFileDownloadBytes, writer)
+	if transfer != nil {
+		entry.Metadata["bytes"] = transfer.Bytes
+	}
+	finishOperationLog(c.operationLogService, entry, err)
+	switch {
+	case err == nil && !writer.started:
+		// 空文件
+		writer.start()
+	case err != nil && !writer.started:
+		containerErrorResponse(ctx, "Failed to download file", err)
+	case err != nil:
+		log.Printf("File download of %s from %s/%s interrupted: %v", file.Path, namespace, file.PodName, err)
+		abortResponse(ctx)
+	}
+}
+
+// UploadFile 上传文件或 tar 归档到容器
+// @Summary 上传文件或 tar 归档到容器
+// @Description 校验命名空间写权限后通过 exec 流式上传，不在服务端缓存内容。请求体为 tar 归档（Content-Type 为 application/x-tar 或 format=tar）时解压到 path 目录；
+// @Description 否则请求体为单个文件的内容，写入 path（必须提供 Content-Length）。目标目录不存在时自动创建，最大 1GB。上传结果写入操作日志。
+// @Tags k8s
+// @Accept application/octet-stream
+// @Accept application/x-tar
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param path query string true "容器中的绝对路径：单个文件为文件路径，tar 归档为解压到的目录"
+// @Param container query string false "容器名称，默认第一个容器"
+// @Param format query string false "tar 或 raw，默认按 Content-Type 判断"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.FileTransfer}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 411 {object} APIResponse
+// @Failure 413 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/files [post]
+func (c *FileController) UploadFile(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+	filePath := ctx.Query("path")
+	if filePath == "" {
+		ValidationError(ctx, services.ValidationErrors{{Field: "path", Message: "is required"}})
+		return
+	}
+	format := ctx.Query("format")
+	if format == "" {
+		format = fileFormatRaw
+		if mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type")); mediaType == "application/x-tar" {
+			format = fileFormatTar
+		}
+	}
+	if format != fileFormatTar && format != fileFormatRaw {
+		ValidationError(ctx, services.ValidationErrors{{Field: "format", Message: "must be tar or raw"}})
+		return
+	}
+	if format == fileFormatRaw && ctx.Request.ContentLength < 0 {
+		ErrorResponse(ctx, http.StatusLengthRequired, "Content-Length is required when uploading a single file", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	if err := c.namespaceAccess.Authorize(requestContext(ctx), namespace, services.NamespacePermissionWrite); err != nil {
+		containerErrorResponse(ctx, "Not allowed to copy files in this namespace", err)
+		return
+	}
+
+	entry := c.fileOperationLog(ctx, "file_upload", connection, namespace, podName, filePath)
+	entry.Metadata["format"] = format
+	entry.Metadata["contentLength"] = ctx.Request.ContentLength
+
+	transfer, err := c.k8sService.UploadToContainer(requestContext(ctx), connection, namespace, podName, &services.FileUpload{
+		Container: ctx.Query("container"),
+		Path:      filePath,
+		Archive:   format == fileFormatTar,
+		Content:   ctx.Request.Body,
+		Size:      ctx.Request.ContentLength,
+		MaxBytes:  maxFileUploadBytes,
+	})
+	if transfer != nil {
+		entry.Metadata["pod"] = transfer.PodName
+		entry.Metadata["container"] = transfer.Container
+		entry.Metadata["bytes"] = transfer.Bytes
+	}
+	finishOperationLog(c.operationLogService, entry, err)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to upload file", err)
+		return
+	}
+
+	SuccessResponse(ctx, "File uploaded successfully", transfer)
+}
+
+// fileOperationLog 构建文件传输的审计日志，结果在传输结束后补全
+func (c *FileController) fileOperationLog(ctx *gin.Context, action string, connection *model.K8sConnection, namespace, podName, filePath string) *model.OperationLog {
+	entry := newOperationLog(ctx, action, "container", namespace+"/"+podName)
+	entry.OperationID = services.NewOperationID()
+	entry.Metadata["connectionId"] = connection.ID
+	entry.Metadata["cluster"] = connection.Name
+	entry.Metadata["container"] = ctx.Query("container")
+	entry.Metadata["path"] = filePath
+	return entry
+}
+
+// attachmentWriter 写入第一个字节时才发送下载的响应头，开始传输前出错时仍可以返回 JSON 错误
+type attachmentWriter struct {
+	ctx         *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *attachmentWriter) start() {
+	w.started = true
+	w.ctx.Header("Content-Type", w.contentType)
+	w.ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": w.filename}))
+	w.ctx.Status(http.StatusOK)
+	w.ctx.Writer.WriteHeaderNow()
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.start()
+	}
+	return w.ctx.Writer.Write(p)
+}
+
+// abortResponse 中断已开始发送的响应，使客户端发现下载不完整
+func abortResponse(ctx *gin.Context) {
+	conn, _, err := ctx.Writer.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
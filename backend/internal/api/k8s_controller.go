@@ -1,20 +1,37 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"container-platform-backend/internal/model"
 	"container-platform-backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 type K8sController struct {
-	k8sService *services.K8sService
+	k8sService          *services.K8sService
+	connectionService   *services.ConnectionService
+	containerRecords    *services.ContainerRecordService
+	operationLogService *services.OperationLogService
 }
 
-func NewK8sController() *K8sController {
+func NewK8sController(k8sService *services.K8sService, connectionService *services.ConnectionService, containerRecords *services.ContainerRecordService, operationLogService *services.OperationLogService) *K8sController {
 	return &K8sController{
-		k8sService: services.NewK8sService(),
+		k8sService:          k8sService,
+		connectionService:   connectionService,
+		containerRecords:    containerRecords,
+		operationLogService: operationLogService,
 	}
 }
 
@@ -24,31 +41,29 @@ func NewK8sController() *K8sController {
 // @Tags k8s
 // @Accept json
 // @Produce json
-// @Param namespace query string false "命名空间" default(default)
+// @Param namespace query string false "命名空间，默认使用连接配置的命名空间"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
 // @Success 200 {object} APIResponse{data=[]services.ContainerInfo}
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Router /api/k8s/containers [get]
 func (c *K8sController) GetContainers(ctx *gin.Context) {
-	namespace := ctx.DefaultQuery("namespace", "default")
-
-	// 这里需要从请求中获取连接信息，或者从用户配置中获取
-	// 暂时使用一个示例连接
-	connection := &model.K8sConnection{
-		Name:       "default-cluster",
-		Endpoint:   "https://kubernetes.default.svc:443",
-		ConfigType: "kubeconfig",
-		Namespace:  namespace,
-	}
-
 	// 连接到集群
-	if err := c.k8sService.ConnectToCluster(connection); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
 		return
 	}
 
+	namespace := ctx.Query("namespace")
+	if namespace == "" {
+		namespace = connection.Namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
 	// 获取容器列表
-	containers, err := c.k8sService.ListContainers(namespace)
+	containers, err := c.k8sService.ListContainers(requestContext(ctx), connection, namespace)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list containers", err)
 		return
@@ -57,57 +72,369 @@ func (c *K8sController) GetContainers(ctx *gin.Context) {
 	SuccessResponse(ctx, "Containers retrieved successfully", containers)
 }
 
+// GetAggregatedContainers 跨集群获取容器列表
+// @Summary 跨集群获取容器列表
+// @Description 并行查询多个已注册集群及命名空间的容器，单个集群不可达时返回部分结果和该集群的错误
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param connectionIds query string false "逗号分隔的连接ID，默认查询所有已注册的连接"
+// @Param namespaces query string false "逗号分隔的命名空间，* 表示所有命名空间，默认使用各连接配置的命名空间"
+// @Success 200 {object} APIResponse{data=services.AggregatedContainers}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/aggregate [get]
+func (c *K8sController) GetAggregatedContainers(ctx *gin.Context) {
+	var connections []model.K8sConnection
+	var err error
+
+	if ids := splitQueryList(ctx.Query("connectionIds")); len(ids) > 0 {
+		connectionIDs := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			parsed, parseErr := strconv.ParseUint(id, 10, 64)
+			if parseErr != nil {
+				ErrorResponse(ctx, http.StatusBadRequest, "Invalid connection id", parseErr)
+				return
+			}
+			connectionIDs = append(connectionIDs, uint(parsed))
+		}
+		connections, err = c.connectionService.GetConnections(connectionIDs)
+	} else {
+		connections, err = c.connectionService.ListConnections()
+	}
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to resolve Kubernetes connections", err)
+		return
+	}
+
+	namespaces := splitQueryList(ctx.Query("namespaces"))
+	targets := make([]services.ClusterTarget, 0, len(connections))
+	for i := range connections {
+		targets = append(targets, services.ClusterTarget{
+			Connection: &connections[i],
+			Namespaces: namespaces,
+		})
+	}
+
+	result := c.k8sService.ListContainersAcrossClusters(requestContext(ctx), targets)
+	SuccessResponse(ctx, "Containers retrieved successfully", result)
+}
+
+// GetContainer 获取容器详情
+// @Summary 获取容器详情
+// @Description 获取容器所在 Pod 的详细状态：Pod 状况、QoS 等级、IP、节点、各容器（含初始化容器）的当前和上一次状态及原因、属主和最近事件。
+// @Description podName 也可以是 Deployment 或 Job 名称，此时返回其最新创建的 Pod。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ContainerDetail}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName} [get]
+func (c *K8sController) GetContainer(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	detail, err := c.k8sService.GetContainerDetail(requestContext(ctx), connection, namespace, podName)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to get container", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container retrieved successfully", detail)
+}
+
+// CreateContainerResponse 创建容器的结果
+type CreateContainerResponse struct {
+	Container *model.Container          `json:"container,omitempty"`
+	Service   *services.ServiceExposure `json:"service,omitempty"`
+}
+
 // CreateContainer 创建容器
 // @Summary 创建容器
-// @Description 创建一个新的容器
+// @Description 创建一个新的容器，默认以 Deployment 运行（kind=pod 时创建独立 Pod）。
+// @Description command/args 为数组，env、ports、resources 为结构化对象；旧版字符串格式（version=v1）仍然兼容。
+// @Description expose 不为空时创建同名 Service（ClusterIP/NodePort/LoadBalancer），分配的地址和节点端口写回端口映射。
+// @Description volumes 可挂载 pvc、configMap、secret、emptyDir 或 hostPath，引用的 PVC 不存在时拒绝创建。
+// @Description livenessProbe/readinessProbe/startupProbe 支持 http、tcp、exec 和 grpc 检查，lifecycle 可配置 postStart 和 preStop 钩子。
+// @Description 字段校验失败时返回 VALIDATION_FAILED 及字段错误列表。
 // @Tags k8s
 // @Accept json
 // @Produce json
 // @Param container body services.CreateContainerRequest true "容器信息"
-// @Success 201 {object} APIResponse
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 201 {object} APIResponse{data=CreateContainerResponse}
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Router /api/k8s/containers [post]
 func (c *K8sController) CreateContainer(ctx *gin.Context) {
 	var req services.CreateContainerRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
+		var validationErrs services.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			ValidationError(ctx, validationErrs)
+			return
+		}
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		containerErrorResponse(ctx, "Invalid container request", err)
+		return
+	}
+
+	// 连接到集群
+	connectionID := ctx.Query("connectionId")
+	if connectionID == "" && req.ConnectionID != 0 {
+		connectionID = strconv.FormatUint(uint64(req.ConnectionID), 10)
+	}
+	connection, ok := c.connect(ctx, connectionID)
+	if !ok {
+		return
+	}
+
+	if response, ok := c.createContainer(ctx, connection, &req); ok {
+		SuccessResponse(ctx, "Container created successfully", response)
+	}
+}
+
+// createContainer 创建容器、写入容器记录并按需暴露 Service，失败时写入错误响应
+func (c *K8sController) createContainer(ctx *gin.Context, connection *model.K8sConnection, req *services.CreateContainerRequest) (*CreateContainerResponse, bool) {
+	if err := c.k8sService.CreateContainer(requestContext(ctx), connection, req); err != nil {
+		containerErrorResponse(ctx, "Failed to create container", err)
+		return nil, false
+	}
+
+	// 工作负载已创建，记录失败不影响请求结果
+	response := CreateContainerResponse{}
+	record, err := c.containerRecords.RecordCreated(requestContext(ctx), connection, req)
+	if err != nil {
+		log.Printf("Failed to record container %s/%s: %v", req.Namespace, req.Name, err)
+	}
+	response.Container = record
+
+	if req.Expose != nil {
+		exposure, err := c.k8sService.ExposeContainer(requestContext(ctx), connection, req.Namespace, req.WorkloadName(), req.Expose)
+		if err != nil {
+			containerErrorResponse(ctx, "Container created but failed to expose service", err)
+			return nil, false
+		}
+		c.recordExposure(ctx, connection, exposure)
+		response.Service = exposure
+	}
+
+	return &response, true
+}
+
+// ValidateContainer 校验容器配置
+// @Summary 校验容器配置
+// @Description 构建与创建容器时完全相同的 Deployment/Pod，检查命名、资源数量、引用的 PVC 和命名空间配额，
+// @Description 再以 DryRun=All 提交给 API Server 执行校验和准入控制，不会创建任何资源。
+// @Description 请求体与创建容器相同，也可包装在 config 字段中；返回字段错误列表和将要提交的 YAML 清单。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param container body services.CreateContainerRequest true "容器信息"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ContainerValidation}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/validate [post]
+func (c *K8sController) ValidateContainer(ctx *gin.Context) {
+	data, err := ctx.GetRawData()
+	if err != nil {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	var wrapper struct {
+		Config json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err == nil && len(wrapper.Config) > 0 {
+		data = wrapper.Config
+	}
 
-	// 这里需要从请求中获取连接信息，或者从用户配置中获取
-	connection := &model.K8sConnection{
-		Name:       "default-cluster",
-		Endpoint:   "https://kubernetes.default.svc:443",
-		ConfigType: "kubeconfig",
-		Namespace:  req.Namespace,
+	var req services.CreateContainerRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		var validationErrs services.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			SuccessResponse(ctx, "Container validation failed", services.ContainerValidation{Errors: validationErrs})
+			return
+		}
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
 	}
 
 	// 连接到集群
-	if err := c.k8sService.ConnectToCluster(connection); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+	connectionID := ctx.Query("connectionId")
+	if connectionID == "" && req.ConnectionID != 0 {
+		connectionID = strconv.FormatUint(uint64(req.ConnectionID), 10)
+	}
+	connection, ok := c.connect(ctx, connectionID)
+	if !ok {
 		return
 	}
 
-	// 创建容器
-	if err := c.k8sService.CreateContainer(&req); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create container", err)
+	result, err := c.k8sService.ValidateContainer(requestContext(ctx), connection, &req)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to validate container", err)
 		return
 	}
 
-	SuccessResponse(ctx, "Container created successfully", nil)
+	message := "Container validation passed"
+	if !result.Valid {
+		message = "Container validation failed"
+	}
+	SuccessResponse(ctx, message, result)
+}
+
+// ExportContainer 导出容器的 YAML 清单
+// @Summary 导出容器的 YAML 清单
+// @Description 将 Deployment 或独立 Pod 连同选择它的同名 Service、引用的 ConfigMap 和 PVC 导出为多文档 YAML，可直接用于 kubectl apply。
+// @Description 状态、命名空间、UID、资源版本、ClusterIP、节点端口等由集群生成的字段会被移除，Secret 只保留引用。
+// @Description download=true 时以附件形式下载 YAML 文件。
+// @Tags k8s
+// @Produce json
+// @Produce application/yaml
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 或工作负载名称"
+// @Param download query bool false "以附件形式下载"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ContainerExport}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/export [get]
+func (c *K8sController) ExportContainer(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	export, err := c.k8sService.ExportContainer(requestContext(ctx), connection, namespace, podName)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to export container", err)
+		return
+	}
+
+	if ctx.Query("download") == "true" {
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.yaml"`, export.Name))
+		ctx.Data(http.StatusOK, "application/yaml; charset=utf-8", []byte(export.Manifest))
+		return
+	}
+	SuccessResponse(ctx, "Container exported successfully", export)
+}
+
+// ImportContainerResponse 导入容器的结果，dryRun 时包含校验结果而不创建容器
+type ImportContainerResponse struct {
+	CreateContainerResponse
+	Import     *services.ContainerImport     `json:"import"`
+	Validation *services.ContainerValidation `json:"validation,omitempty"`
+}
+
+// ImportContainer 从 YAML 清单导入容器
+// @Summary 从 YAML 清单导入容器
+// @Description 解析多文档 YAML（一个单容器的 Deployment 或 Pod，可选一个同名 Service 以及 ConfigMap 和 PVC），
+// @Description 映射为创建容器请求后创建或更新 ConfigMap、创建不存在的 PVC，再按创建容器的流程创建工作负载、写入容器记录并暴露 Service。
+// @Description namespace 为空时使用清单中的命名空间，其次使用连接的默认命名空间；平台不支持而被忽略的设置在 import.warnings 中返回。
+// @Description 应用前先以 DryRun 校验整个清单（包括配额和同名工作负载），校验失败时不修改任何资源；同名 ConfigMap 不由平台管理时返回 409。
+// @Description dryRun=true 时只校验，不创建任何资源。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param import body services.ImportContainerRequest true "清单"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=ImportContainerResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/import [post]
+func (c *K8sController) ImportContainer(ctx *gin.Context) {
+	var req services.ImportContainerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	imported, err := services.ParseContainerManifest(string(req.Config), req.Namespace)
+	if err != nil {
+		containerErrorResponse(ctx, "Invalid container manifest", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+	if imported.Request.Namespace == "" {
+		imported.Request.Namespace = connection.Namespace
+	}
+	if imported.Request.Namespace == "" {
+		imported.Request.Namespace = "default"
+	}
+
+	// 先以 DryRun 校验整个清单，工作负载无法创建时不修改集群中的 ConfigMap 和 PVC
+	validation, err := c.k8sService.ValidateImport(requestContext(ctx), connection, imported)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to validate container manifest", err)
+		return
+	}
+	if req.DryRun {
+		SuccessResponse(ctx, "Container manifest validated", ImportContainerResponse{Import: imported, Validation: validation})
+		return
+	}
+	if !validation.Valid {
+		ValidationError(ctx, validation.Errors)
+		return
+	}
+
+	if err := c.k8sService.ApplyImportResources(requestContext(ctx), connection, imported, false); err != nil {
+		containerErrorResponse(ctx, "Failed to apply container manifest", err)
+		return
+	}
+	response, ok := c.createContainer(ctx, connection, imported.Request)
+	if !ok {
+		return
+	}
+	SuccessResponse(ctx, "Container imported successfully", ImportContainerResponse{CreateContainerResponse: *response, Import: imported})
 }
 
 // StartContainer 启动容器
 // @Summary 启动容器
-// @Description 启动指定的容器
+// @Description 将容器所属的 Deployment 恢复到停止前的副本数，podName 也可以是已停止的 Deployment 名称
 // @Tags k8s
 // @Accept json
 // @Produce json
 // @Param namespace path string true "命名空间"
 // @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
 // @Success 200 {object} APIResponse
 // @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Router /api/k8s/containers/{namespace}/{podName}/start [post]
 func (c *K8sController) StartContainer(ctx *gin.Context) {
@@ -120,21 +447,14 @@ func (c *K8sController) StartContainer(ctx *gin.Context) {
 	}
 
 	// 连接到集群
-	connection := &model.K8sConnection{
-		Name:       "default-cluster",
-		Endpoint:   "https://kubernetes.default.svc:443",
-		ConfigType: "kubeconfig",
-		Namespace:  namespace,
-	}
-
-	if err := c.k8sService.ConnectToCluster(connection); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
 		return
 	}
 
 	// 启动容器
-	if err := c.k8sService.StartContainer(namespace, podName); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start container", err)
+	if err := c.k8sService.StartContainer(requestContext(ctx), connection, namespace, podName); err != nil {
+		containerErrorResponse(ctx, "Failed to start container", err)
 		return
 	}
 
@@ -143,14 +463,16 @@ func (c *K8sController) StartContainer(ctx *gin.Context) {
 
 // StopContainer 停止容器
 // @Summary 停止容器
-// @Description 停止指定的容器
+// @Description 记录副本数后将容器所属的 Deployment 缩容到 0
 // @Tags k8s
 // @Accept json
 // @Produce json
 // @Param namespace path string true "命名空间"
 // @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
 // @Success 200 {object} APIResponse
 // @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Router /api/k8s/containers/{namespace}/{podName}/stop [post]
 func (c *K8sController) StopContainer(ctx *gin.Context) {
@@ -163,37 +485,117 @@ func (c *K8sController) StopContainer(ctx *gin.Context) {
 	}
 
 	// 连接到集群
-	connection := &model.K8sConnection{
-		Name:       "default-cluster",
-		Endpoint:   "https://kubernetes.default.svc:443",
-		ConfigType: "kubeconfig",
-		Namespace:  namespace,
-	}
-
-	if err := c.k8sService.ConnectToCluster(connection); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
 		return
 	}
 
 	// 停止容器
-	if err := c.k8sService.StopContainer(namespace, podName); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to stop container", err)
+	if err := c.k8sService.StopContainer(requestContext(ctx), connection, namespace, podName); err != nil {
+		containerErrorResponse(ctx, "Failed to stop container", err)
 		return
 	}
 
 	SuccessResponse(ctx, "Container stopped successfully", nil)
 }
 
+// PauseContainer 暂停容器
+// @Summary 暂停容器
+// @Description 暂停容器并保留完整的工作负载定义：Deployment 记录副本数后缩容到 0，Job/CronJob 设置 suspend。平台创建的容器记录标记为 paused
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ContainerWorkload}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/pause [post]
+func (c *K8sController) PauseContainer(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	// 暂停容器
+	workload, err := c.k8sService.PauseContainer(requestContext(ctx), connection, namespace, podName)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to pause container", err)
+		return
+	}
+	if err := c.containerRecords.RecordPaused(requestContext(ctx), connection, namespace, workload.Name); err != nil {
+		log.Printf("Failed to mark container record %s/%s as paused: %v", namespace, workload.Name, err)
+	}
+
+	SuccessResponse(ctx, "Container paused successfully", workload)
+}
+
+// ResumeContainer 恢复容器
+// @Summary 恢复容器
+// @Description 恢复已暂停的容器：Deployment 恢复暂停前的副本数，Job/CronJob 取消 suspend。平台创建的容器记录恢复为 running
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ContainerWorkload}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/resume [post]
+func (c *K8sController) ResumeContainer(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	// 恢复容器
+	workload, err := c.k8sService.ResumeContainer(requestContext(ctx), connection, namespace, podName)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to resume container", err)
+		return
+	}
+	if err := c.containerRecords.RecordResumed(requestContext(ctx), connection, namespace, workload.Name); err != nil {
+		log.Printf("Failed to mark container record %s/%s as resumed: %v", namespace, workload.Name, err)
+	}
+
+	SuccessResponse(ctx, "Container resumed successfully", workload)
+}
+
 // RestartContainer 重启容器
 // @Summary 重启容器
-// @Description 重启指定的容器
+// @Description 对容器所属的 Deployment 执行滚动重启
 // @Tags k8s
 // @Accept json
 // @Produce json
 // @Param namespace path string true "命名空间"
 // @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
 // @Success 200 {object} APIResponse
 // @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Router /api/k8s/containers/{namespace}/{podName}/restart [post]
 func (c *K8sController) RestartContainer(ctx *gin.Context) {
@@ -206,37 +608,233 @@ func (c *K8sController) RestartContainer(ctx *gin.Context) {
 	}
 
 	// 连接到集群
-	connection := &model.K8sConnection{
-		Name:       "default-cluster",
-		Endpoint:   "https://kubernetes.default.svc:443",
-		ConfigType: "kubeconfig",
-		Namespace:  namespace,
-	}
-
-	if err := c.k8sService.ConnectToCluster(connection); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
 		return
 	}
 
 	// 重启容器
-	if err := c.k8sService.RestartContainer(namespace, podName); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to restart container", err)
+	if err := c.k8sService.RestartContainer(requestContext(ctx), connection, namespace, podName); err != nil {
+		containerErrorResponse(ctx, "Failed to restart container", err)
 		return
 	}
 
 	SuccessResponse(ctx, "Container restarted successfully", nil)
 }
 
+// ExposeContainer 暴露容器端口
+// @Summary 暴露容器端口
+// @Description 为容器所属的 Deployment 或独立 Pod 创建或更新同名 Service，ports 为空时暴露容器声明的全部端口。
+// @Description 集群分配的 ClusterIP 和节点端口写回端口映射。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param expose body services.ExposeSpec true "暴露配置"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ServiceExposure}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/service [put]
+func (c *K8sController) ExposeContainer(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var spec services.ExposeSpec
+	if err := ctx.ShouldBindJSON(&spec); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	exposure, err := c.k8sService.ExposeContainer(requestContext(ctx), connection, namespace, podName, &spec)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to expose container", err)
+		return
+	}
+	c.recordExposure(ctx, connection, exposure)
+
+	SuccessResponse(ctx, "Container exposed successfully", exposure)
+}
+
+// recordExposure 将 Service 信息写回端口映射，失败只记录日志
+func (c *K8sController) recordExposure(ctx *gin.Context, connection *model.K8sConnection, exposure *services.ServiceExposure) {
+	if err := c.containerRecords.RecordExposure(requestContext(ctx), connection, exposure); err != nil {
+		log.Printf("Failed to record service %s/%s: %v", exposure.Namespace, exposure.ServiceName, err)
+	}
+}
+
+// UpdateContainerHealth 更新容器健康检查
+// @Summary 更新容器健康检查
+// @Description 替换容器所属 Deployment 的存活、就绪、启动探针、生命周期钩子和优雅退出时间，未提供的项会被清除，会触发滚动更新。
+// @Description 探针类型支持 http、tcp、exec 和 grpc，生命周期钩子支持 http 和 exec。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param health body services.HealthSpec true "探针和生命周期配置"
+// @Param container query string false "容器名称，默认第一个容器"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/probes [put]
+func (c *K8sController) UpdateContainerHealth(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var spec services.HealthSpec
+	if err := ctx.ShouldBindJSON(&spec); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	if err := c.k8sService.UpdateContainerHealth(requestContext(ctx), connection, namespace, podName, ctx.Query("container"), &spec); err != nil {
+		containerErrorResponse(ctx, "Failed to update container probes", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container probes updated successfully", nil)
+}
+
+// maxInlineLogBytes 非流式、非下载模式下最多返回的日志字节数
+const maxInlineLogBytes = 10 << 20
+
+// ContainerLogsResponse 容器日志
+type ContainerLogsResponse struct {
+	PodName   string `json:"podName"`
+	Container string `json:"container"`
+	Logs      string `json:"logs"`
+}
+
+// GetContainerLogs 获取容器日志
+// @Summary 获取容器日志
+// @Description 默认一次性返回日志（最多 10MB，可用 limitBytes 调整）。
+// @Description follow=true 时持续推送新日志：携带 WebSocket 升级头时以文本消息逐行推送，否则使用 Server-Sent Events（事件 log/error/end），客户端断开后停止读取。
+// @Description download=true 时以附件形式流式下载完整日志，忽略 follow。
+// @Tags k8s
+// @Produce json
+// @Produce text/plain
+// @Produce text/event-stream
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param container query string false "容器名称，默认第一个容器"
+// @Param follow query bool false "持续推送新日志"
+// @Param tailLines query int false "只返回最后若干行"
+// @Param sinceSeconds query int false "只返回最近若干秒的日志"
+// @Param timestamps query bool false "每行前加上时间戳"
+// @Param previous query bool false "返回容器重启前的日志"
+// @Param limitBytes query int false "最多返回的字节数"
+// @Param download query bool false "以附件形式下载"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=ContainerLogsResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/logs [get]
+func (c *K8sController) GetContainerLogs(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var opts services.LogOptions
+	if err := ctx.ShouldBindQuery(&opts); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	download := ctx.Query("download") == "true"
+	if download {
+		opts.Follow = false
+	} else if !opts.Follow && opts.LimitBytes == nil {
+		limit := int64(maxInlineLogBytes)
+		opts.LimitBytes = &limit
+	}
+
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	// WebSocket 升级后请求上下文不再随客户端断开而取消，由读取协程负责取消
+	streamCtx, cancel := context.WithCancel(requestContext(ctx))
+	defer cancel()
+
+	stream, err := c.k8sService.StreamContainerLogs(streamCtx, connection, namespace, podName, &opts)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to get container logs", err)
+		return
+	}
+	defer stream.Close()
+
+	switch {
+	case download:
+		ctx.Header("Content-Type", "text/plain; charset=utf-8")
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.log"`, stream.PodName, stream.Container))
+		ctx.Status(http.StatusOK)
+		if _, err := io.Copy(ctx.Writer, stream); err != nil {
+			log.Printf("Log download of %s/%s interrupted: %v", stream.PodName, stream.Container, err)
+		}
+	case opts.Follow && websocket.IsWebSocketUpgrade(ctx.Request):
+		streamLogsWebSocket(ctx, stream, cancel)
+	case opts.Follow:
+		streamLogsSSE(ctx, stream)
+	default:
+		logs, err := io.ReadAll(stream)
+		if err != nil {
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to read container logs", err)
+			return
+		}
+		SuccessResponse(ctx, "Container logs retrieved successfully", ContainerLogsResponse{
+			PodName:   stream.PodName,
+			Container: stream.Container,
+			Logs:      string(logs),
+		})
+	}
+}
+
 // DeleteContainer 删除容器
 // @Summary 删除容器
-// @Description 删除指定的容器
+// @Description 删除容器所属的 Deployment（独立 Pod 直接删除），同时删除平台为其创建的 Service
 // @Tags k8s
 // @Accept json
 // @Produce json
 // @Param namespace path string true "命名空间"
 // @Param podName path string true "Pod 名称"
-// @Success 200 {object} APIResponse
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.DeletedContainer}
 // @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Router /api/k8s/containers/{namespace}/{podName} [delete]
 func (c *K8sController) DeleteContainer(ctx *gin.Context) {
@@ -249,30 +847,145 @@ func (c *K8sController) DeleteContainer(ctx *gin.Context) {
 	}
 
 	// 连接到集群
-	connection := &model.K8sConnection{
-		Name:       "default-cluster",
-		Endpoint:   "https://kubernetes.default.svc:443",
-		ConfigType: "kubeconfig",
-		Namespace:  namespace,
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
 	}
 
-	if err := c.k8sService.ConnectToCluster(connection); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+	// 删除容器，工作负载删除后即清理容器记录（即使 Service 删除失败）
+	deleted, err := c.k8sService.DeleteContainer(requestContext(ctx), connection, namespace, podName)
+	if deleted != nil {
+		if recordErr := c.containerRecords.RecordDeleted(requestContext(ctx), connection, namespace, deleted.Name); recordErr != nil {
+			log.Printf("Failed to delete container record %s/%s: %v", namespace, deleted.Name, recordErr)
+		}
+	}
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to delete container", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container deleted successfully", deleted)
+}
+
+// BatchContainers 批量操作容器
+// @Summary 批量操作容器
+// @Description 对 targets/ids 指定的容器或 labelSelector 选中的工作负载执行同一操作（start、stop、restart、pause、resume、delete，destroy 等同于 delete），
+// @Description 并发执行且单个容器失败不影响其他容器，返回每个容器的结果。dryRun=true 时只检查目标是否存在以及是否支持该操作。
+// @Description 批量操作和每个容器的操作都写入操作日志，通过 operationId 关联。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param batch body services.BatchRequest true "批量操作参数"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.BatchResult}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/batch [post]
+func (c *K8sController) BatchContainers(ctx *gin.Context) {
+	var req services.BatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
 
-	// 删除容器
-	if err := c.k8sService.DeleteContainer(namespace, podName); err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete container", err)
+	// 连接到集群
+	connection, ok := c.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+	if req.Namespace == "" {
+		req.Namespace = connection.Namespace
+	}
+	if req.Namespace == "" {
+		req.Namespace = "default"
+	}
+
+	started := time.Now()
+	result, err := c.k8sService.BatchContainers(requestContext(ctx), connection, &req)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to run batch operation", err)
 		return
 	}
 
-	SuccessResponse(ctx, "Container deleted successfully", nil)
+	if !result.DryRun {
+		c.recordBatchResults(ctx, connection, result)
+	}
+	c.recordBatchOperation(ctx, connection, &req, result, started)
+
+	SuccessResponse(ctx, "Batch operation completed", result)
+}
+
+// recordBatchResults 同步容器记录：清理已删除工作负载的记录，更新暂停和恢复的状态
+func (c *K8sController) recordBatchResults(ctx *gin.Context, connection *model.K8sConnection, result *services.BatchResult) {
+	for _, item := range result.Items {
+		if item.Deleted != nil {
+			if err := c.containerRecords.RecordDeleted(requestContext(ctx), connection, item.Namespace, item.Deleted.Name); err != nil {
+				log.Printf("Failed to delete container record %s/%s: %v", item.Namespace, item.Deleted.Name, err)
+			}
+		}
+		if !item.Success {
+			continue
+		}
+
+		var err error
+		switch result.Action {
+		case services.BatchActionPause:
+			err = c.containerRecords.RecordPaused(requestContext(ctx), connection, item.Namespace, item.WorkloadName)
+		case services.BatchActionResume:
+			err = c.containerRecords.RecordResumed(requestContext(ctx), connection, item.Namespace, item.WorkloadName)
+		}
+		if err != nil {
+			log.Printf("Failed to update container record %s/%s: %v", item.Namespace, item.WorkloadName, err)
+		}
+	}
+}
+
+// recordBatchOperation 写入批量操作的操作日志，实际执行时每个容器另写一条日志并指向批量操作的操作ID
+func (c *K8sController) recordBatchOperation(ctx *gin.Context, connection *model.K8sConnection, req *services.BatchRequest, result *services.BatchResult, started time.Time) {
+	now := time.Now()
+	duration := int(now.Sub(started).Milliseconds())
+	statusCode := http.StatusOK
+
+	parent := newOperationLog(ctx, "batch_"+result.Action, "container", req.Namespace)
+	parent.OperationID = result.OperationID
+	parent.StartedAt = started
+	parent.CompletedAt = &now
+	parent.DurationMs = &duration
+	parent.StatusCode = &statusCode
+	parent.Metadata["connectionId"] = connection.ID
+	parent.Metadata["cluster"] = connection.Name
+	parent.Metadata["dryRun"] = result.DryRun
+	parent.Metadata["labelSelector"] = req.LabelSelector
+	parent.Metadata["total"] = result.Total
+	parent.Metadata["succeeded"] = result.Succeeded
+	parent.Metadata["failed"] = result.Failed
+	recordOperation(c.operationLogService, parent)
+
+	if result.DryRun {
+		return
+	}
+	for _, item := range result.Items {
+		entry := newOperationLog(ctx, result.Action, "container", item.Namespace+"/"+item.Name)
+		entry.ParentOperationID = result.OperationID
+		entry.StartedAt = started
+		entry.CompletedAt = &now
+		entry.Metadata["connectionId"] = connection.ID
+		entry.Metadata["cluster"] = connection.Name
+		entry.Metadata["kind"] = item.Kind
+
+		itemStatus := http.StatusOK
+		if item.Err != nil {
+			itemStatus = containerErrorStatus(item.Err)
+			entry.ErrorMessage = item.Error
+		}
+		entry.StatusCode = &itemStatus
+		recordOperation(c.operationLogService, entry)
+	}
 }
 
 // TestConnection 测试 K8s 连接
 // @Summary 测试 K8s 连接
-// @Description 测试 Kubernetes 集群连接
+// @Description 测试 Kubernetes 集群连接，不支持内置连接的 local 配置类型
 // @Tags k8s
 // @Accept json
 // @Produce json
@@ -289,10 +1002,102 @@ func (c *K8sController) TestConnection(ctx *gin.Context) {
 	}
 
 	// 测试连接
-	if err := c.k8sService.TestConnection(&connection); err != nil {
+	if err := c.k8sService.TestConnection(requestContext(ctx), &connection); err != nil {
+		if errors.Is(err, services.ErrInvalidConnection) {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid connection config", err)
+			return
+		}
+		var certErr *services.CertificateError
+		if errors.As(err, &certErr) {
+			ErrorResponse(ctx, http.StatusBadGateway, "TLS certificate verification failed: "+certErr.Reason, err)
+			return
+		}
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
 		return
 	}
 
 	SuccessResponse(ctx, "Connection test successful", nil)
-}
\ No newline at end of file
+}
+
+// connect 解析请求对应的集群连接（未指定时回退到激活的连接）并连接到集群
+func (c *K8sController) connect(ctx *gin.Context, connectionID string) (*model.K8sConnection, bool) {
+	return connectCluster(ctx, c.connectionService, c.k8sService, connectionID)
+}
+
+// connectCluster 解析连接并连接到集群，失败时写入错误响应并返回 false
+func connectCluster(ctx *gin.Context, connectionService *services.ConnectionService, k8sService *services.K8sService, connectionID string) (*model.K8sConnection, bool) {
+	connection, err := connectionService.ResolveConnection(connectionID)
+	if err != nil {
+		connectionErrorResponse(ctx, "Failed to resolve Kubernetes connection", err)
+		return nil, false
+	}
+
+	if err := k8sService.ConnectToCluster(requestContext(ctx), connection); err != nil {
+		if errors.Is(err, services.ErrPlatformUserRequired) {
+			ErrorResponse(ctx, http.StatusUnauthorized, "Authentication required to access this cluster", err)
+			return nil, false
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to connect to Kubernetes cluster", err)
+		return nil, false
+	}
+
+	return connection, true
+}
+
+// containerErrorResponse 根据容器操作错误返回对应的状态码
+func containerErrorResponse(ctx *gin.Context, message string, err error) {
+	var validationErrs services.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		ValidationError(ctx, validationErrs)
+		return
+	}
+	ErrorResponse(ctx, containerErrorStatus(err), message, err)
+}
+
+// containerErrorStatus 容器操作错误对应的 HTTP 状态码
+func containerErrorStatus(err error) int {
+	var validationErrs services.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidContainerRequest), errors.Is(err, services.ErrUnsupportedWorkload):
+		return http.StatusBadRequest
+	case apierrors.IsInvalid(err):
+		return http.StatusBadRequest
+	case apierrors.IsNotFound(err), errors.Is(err, services.ErrRevisionNotFound), errors.Is(err, services.ErrFileNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrContainerNotPaused), errors.Is(err, services.ErrServiceConflict), errors.Is(err, services.ErrConfigMapConflict), errors.Is(err, services.ErrPodNotRunning), apierrors.IsAlreadyExists(err):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrNamespaceAccessDenied), errors.Is(err, services.ErrPortForwardNotOwner), apierrors.IsForbidden(err):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// requestContext 返回携带当前平台用户（由认证中间件设置）的请求上下文
+func requestContext(ctx *gin.Context) context.Context {
+	username := ctx.GetString("username")
+	if username == "" {
+		return ctx.Request.Context()
+	}
+
+	return services.WithPlatformUser(ctx.Request.Context(), &services.PlatformUser{
+		ID:       ctx.GetUint("user_id"),
+		Username: username,
+		Role:     ctx.GetString("role"),
+	})
+}
+
+// splitQueryList 解析逗号分隔的查询参数
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
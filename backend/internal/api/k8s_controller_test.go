@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/services"
+)
+
+func TestTestConnectionRejectsLocalConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	k8sService := services.NewK8sServiceWithFactory(func(*rest.Config) (kubernetes.Interface, error) {
+		t.Fatal("local config should be rejected before creating a client")
+		return nil, nil
+	})
+	controller := NewK8sController(k8sService, nil, nil, nil)
+	engine := gin.New()
+	engine.POST("/api/k8s/test-connection", controller.TestConnection)
+
+	bodies := []string{
+		`{"name":"local","configType":"local","config":"/etc/kubernetes/admin.conf"}`,
+		`{"name":"in-cluster","configType":"local"}`,
+		`{"name":"built-in","configType":"token","endpoint":"https://kubernetes.example.com","token":"token","builtIn":true}`,
+	}
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/api/k8s/test-connection", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("POST %s = %d %s, want 400", body, recorder.Code, recorder.Body.String())
+		}
+	}
+}
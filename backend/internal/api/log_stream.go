@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxLogLineBytes 单行日志的最大长度，超出部分拆成多行发送
+	maxLogLineBytes = 1 << 20
+	// streamPingInterval WebSocket 心跳间隔，用于发现已断开的客户端
+	streamPingInterval = 30 * time.Second
+	// streamWriteTimeout 单次写入的超时时间
+	streamWriteTimeout = 10 * time.Second
+)
+
+// upgrader WebSocket 升级配置，跨域策略与 CORS 中间件一致（完全开放）
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// streamLogsSSE 以 Server-Sent Events 逐行推送日志，客户端断开时请求上下文取消，日志流随之结束
+// 事件类型：log 为一行日志，error 为读取失败，end 为日志结束（容器退出）
+func streamLogsSSE(ctx *gin.Context, stream io.Reader) {
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// 禁止 nginx 等反向代理缓冲
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	err := readLogLines(stream, func(line string) error {
+		ctx.SSEvent("log", line)
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	})
+	if ctx.Request.Context().Err() != nil {
+		// 客户端已断开
+		return
+	}
+	if err != nil {
+		ctx.SSEvent("error", err.Error())
+	} else {
+		ctx.SSEvent("end", "")
+	}
+	ctx.Writer.Flush()
+}
+
+// streamLogsWebSocket 将请求升级为 WebSocket 并逐行以文本消息推送日志
+// 客户端断开或关闭连接时调用 cancel 结束日志流；日志结束时以正常关闭码关闭连接
+func streamLogsWebSocket(ctx *gin.Context, stream io.Reader, cancel context.CancelFunc) {
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// Upgrade 已向客户端返回错误
+		log.Printf("Failed to upgrade log stream to websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go watchWebSocket(conn, cancel, done)
+
+	err = readLogLines(stream, func(line string) error {
+		conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, []byte(line))
+	})
+
+	closeCode, reason := websocket.CloseNormalClosure, ""
+	if err != nil && !errors.Is(err, context.Canceled) {
+		closeCode, reason = websocket.CloseInternalServerErr, err.Error()
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, reason), time.Now().Add(streamWriteTimeout))
+}
+
+// watchWebSocket 读取客户端消息以处理关闭帧，并定时发送心跳；连接断开时调用 cancel
+func watchWebSocket(conn *websocket.Conn, cancel context.CancelFunc, done <-chan struct{}) {
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// readLogLines 逐行读取日志并交给 send，send 返回错误时停止；正常读到结尾时返回 nil
+func readLogLines(stream io.Reader, send func(string) error) error {
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineBytes)
+	scanner.Split(scanLogLines)
+	for scanner.Scan() {
+		if err := send(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// scanLogLines 与 bufio.ScanLines 相同，但超长的行在缓冲区满时直接切分，而不是报错
+func scanLogLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if advance == 0 && token == nil && err == nil && len(data) >= maxLogLineBytes {
+		return len(data), data, nil
+	}
+	return advance, token, err
+}
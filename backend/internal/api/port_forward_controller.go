@@ -0,0 +1,357 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"container-platform-backend/internal/services"
+)
+
+// portForwardBufferSize TCP 隧道每条 WebSocket 消息最多携带的字节数
+const portForwardBufferSize = 32 * 1024
+
+// PortForwardController 容器端口转发控制器
+type PortForwardController struct {
+	k8sService          *services.K8sService
+	connectionService   *services.ConnectionService
+	namespaceAccess     *services.NamespaceAccessService
+	sessions            *services.PortForwardSessionStore
+	operationLogService *services.OperationLogService
+}
+
+// NewPortForwardController 创建容器端口转发控制器
+func NewPortForwardController(
+	k8sService *services.K8sService,
+	connectionService *services.ConnectionService,
+	namespaceAccess *services.NamespaceAccessService,
+	sessions *services.PortForwardSessionStore,
+	operationLogService *services.OperationLogService,
+) *PortForwardController {
+	return &PortForwardController{
+		k8sService:          k8sService,
+		connectionService:   connectionService,
+		namespaceAccess:     namespaceAccess,
+		sessions:            sessions,
+		operationLogService: operationLogService,
+	}
+}
+
+// PortForwardRequest 创建端口转发会话的请求
+type PortForwardRequest struct {
+	// 容器声明的 TCP 端口
+	Port int32 `json:"port" binding:"required"`
+}
+
+// PortForwardResponse 已创建的端口转发会话
+type PortForwardResponse struct {
+	SessionID string                     `json:"sessionId"`
+	Target    services.PortForwardTarget `json:"target"`
+	// HTTP 代理地址，需携带令牌访问，路径之后的部分转发为 Pod 中的路径
+	ProxyURL string `json:"proxyUrl"`
+	// TCP 隧道的 WebSocket 地址，二进制消息即 TCP 数据
+	WebsocketURL       string `json:"websocketUrl"`
+	IdleTimeoutSeconds int    `json:"idleTimeoutSeconds"`
+}
+
+// CreatePortForward 创建端口转发会话
+// @Summary 创建端口转发会话
+// @Description 校验命名空间写权限后为容器声明的 TCP 端口创建端口转发会话，Deployment 或 Job 转发到其最新创建的 Pod。
+// @Description 会话在空闲超时前可多次使用：proxyUrl 经 API Server 代理转发 HTTP 请求（支持 WebSocket），websocketUrl 经 portforward 子资源建立 TCP 隧道。
+// @Description 会话和每个连接在空闲超时（默认 10 分钟）内没有数据传输时关闭。使用和关闭会话需携带令牌，且只有创建者或平台管理员可以使用。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param portForward body PortForwardRequest true "端口"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=PortForwardResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/portforward [post]
+func (c *PortForwardController) CreatePortForward(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var req PortForwardRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	if err := c.namespaceAccess.Authorize(requestContext(ctx), namespace, services.NamespacePermissionWrite); err != nil {
+		containerErrorResponse(ctx, "Not allowed to forward ports in this namespace", err)
+		return
+	}
+
+	entry := newOperationLog(ctx, "port_forward", "container", namespace+"/"+podName)
+	entry.OperationID = services.NewOperationID()
+	entry.Metadata["connectionId"] = connection.ID
+	entry.Metadata["cluster"] = connection.Name
+	entry.Metadata["port"] = req.Port
+
+	target, err := c.k8sService.ResolvePortForward(requestContext(ctx), connection, namespace, podName, req.Port)
+	finishOperationLog(c.operationLogService, entry, err)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to forward port", err)
+		return
+	}
+
+	user, _ := services.PlatformUserFromContext(requestContext(ctx))
+	session := c.sessions.Create(&services.PortForwardSession{
+		Connection:  connection,
+		Target:      *target,
+		User:        user,
+		OperationID: entry.OperationID,
+	})
+
+	SuccessResponse(ctx, "Port forward session created successfully", PortForwardResponse{
+		SessionID:          session.ID,
+		Target:             session.Target,
+		ProxyURL:           "/api/k8s/portforward/" + session.ID + "/proxy/",
+		WebsocketURL:       "/api/k8s/portforward/" + session.ID + "/tunnel",
+		IdleTimeoutSeconds: int(c.sessions.IdleTimeout().Seconds()),
+	})
+}
+
+// ProxyPortForward 通过端口转发会话代理 HTTP 请求
+// @Summary 通过端口转发会话代理 HTTP 请求
+// @Description 将请求经 API Server 的 Pod 代理转发到会话的端口，{path} 为 Pod 中的请求路径，支持所有方法和 WebSocket 升级。
+// @Description 平台的 Authorization 头不会转发到 Pod。响应带有 Content-Security-Policy: sandbox 且去掉 Set-Cookie，Pod 的页面不能以平台的源执行脚本或写入 Cookie。
+// @Tags k8s
+// @Param sessionId path string true "会话ID"
+// @Param path path string true "Pod 中的请求路径"
+// @Success 200
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 502
+// @Router /api/k8s/portforward/{sessionId}/proxy/{path} [get]
+func (c *PortForwardController) ProxyPortForward(ctx *gin.Context) {
+	session, ok := c.sessions.Acquire(ctx.Param("sessionId"))
+	if !ok {
+		ErrorResponse(ctx, http.StatusNotFound, "Port forward session not found or expired", nil)
+		return
+	}
+	defer c.sessions.Release(session)
+
+	if !c.authorizeSession(ctx, session) {
+		return
+	}
+
+	proxyCtx := requestContext(ctx)
+	proxy, err := c.k8sService.PodProxy(proxyCtx, session.Connection, &session.Target, c.sessions.IdleTimeout())
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to proxy request", err)
+		return
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.Printf("Port forward %s proxy to %s/%s:%d failed: %v", session.ID, session.Target.Namespace, session.Target.PodName, session.Target.Port, err)
+		w.WriteHeader(http.StatusBadGateway)
+	}
+
+	req := ctx.Request.Clone(proxyCtx)
+	req.URL.Path = ctx.Param("path")
+	req.URL.RawPath = ""
+	proxy.ServeHTTP(ctx.Writer, req)
+}
+
+// PortForwardTunnel 通过端口转发会话建立 TCP 隧道
+// @Summary 通过端口转发会话建立 TCP 隧道
+// @Description WebSocket 接口。客户端发送的二进制（或文本）消息原样写入 Pod 端口，Pod 端口返回的数据以二进制消息推送。
+// @Description 任一方向结束或空闲超时时关闭连接，空闲超时的关闭原因为 "idle timeout"。连接结束后写入操作日志。
+// @Tags k8s
+// @Param sessionId path string true "会话ID"
+// @Success 101
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/portforward/{sessionId}/tunnel [get]
+func (c *PortForwardController) PortForwardTunnel(ctx *gin.Context) {
+	session, ok := c.sessions.Acquire(ctx.Param("sessionId"))
+	if !ok {
+		ErrorResponse(ctx, http.StatusNotFound, "Port forward session not found or expired", nil)
+		return
+	}
+	defer c.sessions.Release(session)
+
+	if !c.authorizeSession(ctx, session) {
+		return
+	}
+	user, _ := services.PlatformUserFromContext(requestContext(ctx))
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade port forward session %s to websocket: %v", session.ID, err)
+		return
+	}
+	defer conn.Close()
+
+	entry := newOperationLog(ctx, "port_forward_tunnel", "container", session.Target.Namespace+"/"+session.Target.PodName)
+	entry.ParentOperationID = session.OperationID
+	entry.Metadata["connectionId"] = session.Connection.ID
+	entry.Metadata["port"] = session.Target.Port
+
+	// WebSocket 升级后请求上下文不再随客户端断开而取消
+	tunnelCtx := services.WithPlatformUser(context.Background(), user)
+	stream, err := c.k8sService.DialPort(tunnelCtx, session.Connection, &session.Target, c.sessions.IdleTimeout())
+	if err != nil {
+		closeTunnel(conn, websocket.CloseInternalServerErr, err.Error())
+		finishOperationLog(c.operationLogService, entry, err)
+		return
+	}
+
+	var sent, received atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer stream.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType != websocket.BinaryMessage && messageType != websocket.TextMessage {
+				continue
+			}
+			if _, err := stream.Write(data); err != nil {
+				return
+			}
+			sent.Add(int64(len(data)))
+		}
+	}()
+	go pingTunnel(conn, done)
+
+	buf := make([]byte, portForwardBufferSize)
+	for {
+		n, readErr := stream.Read(buf)
+		if n > 0 {
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				break
+			}
+			received.Add(int64(n))
+		}
+		if readErr != nil {
+			err = readErr
+			break
+		}
+	}
+	stream.Close()
+
+	entry.Metadata["bytesSent"] = sent.Load()
+	entry.Metadata["bytesReceived"] = received.Load()
+	switch {
+	case errors.Is(err, services.ErrPortForwardIdle):
+		entry.Metadata["idleTimeout"] = true
+		closeTunnel(conn, websocket.CloseNormalClosure, "idle timeout")
+		err = nil
+	case err != nil && !clientClosed(done):
+		closeTunnel(conn, websocket.CloseInternalServerErr, err.Error())
+	default:
+		err = nil
+		closeTunnel(conn, websocket.CloseNormalClosure, "")
+	}
+	finishOperationLog(c.operationLogService, entry, err)
+}
+
+// DeletePortForward 关闭端口转发会话
+// @Summary 关闭端口转发会话
+// @Description 删除会话后不能再建立新的代理请求和隧道，已建立的连接在结束或空闲超时前不受影响
+// @Tags k8s
+// @Produce json
+// @Param sessionId path string true "会话ID"
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/portforward/{sessionId} [delete]
+func (c *PortForwardController) DeletePortForward(ctx *gin.Context) {
+	session, ok := c.sessions.Get(ctx.Param("sessionId"))
+	if !ok {
+		ErrorResponse(ctx, http.StatusNotFound, "Port forward session not found or expired", nil)
+		return
+	}
+	if !c.authorizeSession(ctx, session) {
+		return
+	}
+
+	c.sessions.Delete(session.ID)
+
+	SuccessResponse(ctx, "Port forward session closed successfully", nil)
+}
+
+// authorizeSession 校验请求用户是会话的创建者或平台管理员，并重新校验命名空间写权限，失败时写入错误响应并返回 false
+func (c *PortForwardController) authorizeSession(ctx *gin.Context, session *services.PortForwardSession) bool {
+	user, ok := services.PlatformUserFromContext(requestContext(ctx))
+	if !ok {
+		ErrorResponse(ctx, http.StatusUnauthorized, "Authentication required to use port forward sessions", nil)
+		return false
+	}
+	if err := session.AuthorizeUser(user); err != nil {
+		containerErrorResponse(ctx, "Not allowed to use this port forward session", err)
+		return false
+	}
+	// 会话创建后权限可能已被收回
+	if err := c.namespaceAccess.Authorize(requestContext(ctx), session.Target.Namespace, services.NamespacePermissionWrite); err != nil {
+		containerErrorResponse(ctx, "Not allowed to forward ports in this namespace", err)
+		return false
+	}
+	return true
+}
+
+// pingTunnel 定时发送心跳，避免中间代理断开没有数据传输的隧道
+func pingTunnel(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// clientClosed 客户端是否已断开，此时读取 Pod 端口的错误由本端关闭流引起
+func clientClosed(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeTunnel 发送关闭原因，原因超过 WebSocket 控制帧的长度限制时截断
+func closeTunnel(conn *websocket.Conn, code int, reason string) {
+	if len(reason) > 120 {
+		reason = reason[:120]
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(streamWriteTimeout))
+}
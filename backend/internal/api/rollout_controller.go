@@ -0,0 +1,230 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/model"
+	"container-platform-backend/internal/services"
+)
+
+// RolloutController 容器镜像修改、发布进度和回滚控制器
+type RolloutController struct {
+	rollouts            *services.ImageRolloutService
+	connectionService   *services.ConnectionService
+	k8sService          *services.K8sService
+	operationLogService *services.OperationLogService
+}
+
+// NewRolloutController 创建容器镜像发布控制器
+func NewRolloutController(
+	rollouts *services.ImageRolloutService,
+	connectionService *services.ConnectionService,
+	k8sService *services.K8sService,
+	operationLogService *services.OperationLogService,
+) *RolloutController {
+	return &RolloutController{
+		rollouts:            rollouts,
+		connectionService:   connectionService,
+		k8sService:          k8sService,
+		operationLogService: operationLogService,
+	}
+}
+
+// UpdateImage 修改容器镜像
+// @Summary 修改容器镜像
+// @Description 修改容器所属 Deployment（触发滚动更新）或独立 Pod 中容器的镜像，并记录新的修订。
+// @Description 发布进度在后台跟踪，直到可用、失败或超时，可通过 rollout 接口查询；修改和发布结果都写入操作日志，通过 operationId 关联。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param image body services.UpdateImageRequest true "镜像"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=model.ContainerRevision}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/image [put]
+func (c *RolloutController) UpdateImage(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var req services.UpdateImageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	entry := c.rolloutOperationLog(ctx, "update_image", connection, namespace, podName)
+	entry.Metadata["container"] = req.Container
+	entry.Metadata["image"] = req.Image
+
+	revision, err := c.rollouts.UpdateImage(requestContext(ctx), connection, namespace, podName, &req)
+	c.finishRolloutOperationLog(entry, revision, err)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to update container image", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container image updated successfully", revision)
+}
+
+// RollbackImage 回滚容器镜像
+// @Summary 回滚容器镜像
+// @Description 将容器镜像恢复为指定修订的镜像，回滚本身记录为新的修订，发布进度同样在后台跟踪。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param rollback body services.RollbackRequest true "回滚到的修订号"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=model.ContainerRevision}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/rollback [post]
+func (c *RolloutController) RollbackImage(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	var req services.RollbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	entry := c.rolloutOperationLog(ctx, "rollback_image", connection, namespace, podName)
+	entry.Metadata["rollbackTo"] = req.Revision
+
+	revision, err := c.rollouts.Rollback(requestContext(ctx), connection, namespace, podName, req.Revision)
+	c.finishRolloutOperationLog(entry, revision, err)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to roll back container image", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container image rolled back successfully", revision)
+}
+
+// GetRollout 获取容器镜像发布进度
+// @Summary 获取容器镜像发布进度
+// @Description 返回实时的发布状态（progressing、available 或 failed），Deployment 包含副本数，以及最新的修订记录
+// @Tags k8s
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param container query string false "容器名称，默认第一个容器"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=services.ContainerRollout}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/rollout [get]
+func (c *RolloutController) GetRollout(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	rollout, err := c.rollouts.GetRollout(requestContext(ctx), connection, namespace, podName, ctx.Query("container"))
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to get rollout status", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Rollout status retrieved successfully", rollout)
+}
+
+// ListRevisions 获取容器镜像修订历史
+// @Summary 获取容器镜像修订历史
+// @Description 按修订号倒序返回容器所属工作负载的镜像修订，包括镜像、操作人、时间和发布结果。首次修改镜像前的原始镜像记录为 create 修订。
+// @Tags k8s
+// @Produce json
+// @Param namespace path string true "命名空间"
+// @Param podName path string true "Pod 名称"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=[]model.ContainerRevision}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/{namespace}/{podName}/revisions [get]
+func (c *RolloutController) ListRevisions(ctx *gin.Context) {
+	namespace := ctx.Param("namespace")
+	podName := ctx.Param("podName")
+
+	if namespace == "" || podName == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Namespace and pod name are required", nil)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := connectCluster(ctx, c.connectionService, c.k8sService, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+
+	revisions, err := c.rollouts.ListRevisions(requestContext(ctx), connection, namespace, podName)
+	if err != nil {
+		containerErrorResponse(ctx, "Failed to list container revisions", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container revisions retrieved successfully", revisions)
+}
+
+// rolloutOperationLog 构建修改或回滚镜像的审计日志，结果在操作结束后补全
+func (c *RolloutController) rolloutOperationLog(ctx *gin.Context, action string, connection *model.K8sConnection, namespace, podName string) *model.OperationLog {
+	entry := newOperationLog(ctx, action, "container", namespace+"/"+podName)
+	entry.Metadata["connectionId"] = connection.ID
+	entry.Metadata["cluster"] = connection.Name
+	return entry
+}
+
+// finishRolloutOperationLog 补全修订信息并写入审计日志，后台跟踪的发布结果以该日志的操作ID为父操作
+func (c *RolloutController) finishRolloutOperationLog(entry *model.OperationLog, revision *model.ContainerRevision, err error) {
+	if revision != nil {
+		entry.OperationID = revision.OperationID
+		entry.Metadata["kind"] = revision.WorkloadKind
+		entry.Metadata["workload"] = revision.WorkloadName
+		entry.Metadata["container"] = revision.ContainerName
+		entry.Metadata["revision"] = revision.Revision
+		entry.Metadata["image"] = revision.Image
+		entry.Metadata["previousImage"] = revision.PreviousImage
+	}
+	finishOperationLog(c.operationLogService, entry, err)
+}
@@ -3,22 +3,54 @@ package api
 import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"container-platform-backend/internal/middleware"
+	"container-platform-backend/internal/services"
 )
 
 // Router 路由器
 type Router struct {
-	engine        *gin.Engine
-	k8sController *K8sController
+	engine                *gin.Engine
+	jwtAuth               *middleware.JWTAuth
+	k8sController         *K8sController
+	templateController    *TemplateController
+	execController        *ExecController
+	rolloutController     *RolloutController
+	portForwardController *PortForwardController
+	fileController        *FileController
+	connectionController  *ConnectionController
+	healthController      *ClusterHealthController
+	healthMonitor         *services.HealthMonitor
+	rolloutService        *services.ImageRolloutService
 }
 
 // NewRouter 创建路由器
-func NewRouter() *Router {
+func NewRouter(db *gorm.DB, jwtAuth *middleware.JWTAuth) *Router {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 
+	connectionService := services.NewConnectionService(db)
+	k8sService := services.NewK8sService()
+	operationLogService := services.NewOperationLogService(db)
+	healthMonitor := services.NewHealthMonitor(db, k8sService)
+	namespaceAccess := services.NewNamespaceAccessService(db)
+	k8sController := NewK8sController(k8sService, connectionService, services.NewContainerRecordService(db), operationLogService)
+	rolloutService := services.NewImageRolloutService(db, k8sService, connectionService, operationLogService)
+
 	return &Router{
-		engine:        engine,
-		k8sController: NewK8sController(),
+		engine:                engine,
+		jwtAuth:               jwtAuth,
+		k8sController:         k8sController,
+		templateController:    NewTemplateController(services.NewTemplateService(db), namespaceAccess, k8sController),
+		execController:        NewExecController(k8sService, connectionService, namespaceAccess, services.NewExecSessionStore(0), operationLogService),
+		rolloutController:     NewRolloutController(rolloutService, connectionService, k8sService, operationLogService),
+		portForwardController: NewPortForwardController(k8sService, connectionService, namespaceAccess, services.NewPortForwardSessionStore(0), operationLogService),
+		fileController:        NewFileController(k8sService, connectionService, namespaceAccess, operationLogService),
+		connectionController:  NewConnectionController(connectionService, k8sService, operationLogService),
+		healthController:      NewClusterHealthController(connectionService, healthMonitor),
+		healthMonitor:         healthMonitor,
+		rolloutService:        rolloutService,
 	}
 }
 
@@ -37,6 +69,16 @@ func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
 
+// GetHealthMonitor 获取集群健康检查
+func (r *Router) GetHealthMonitor() *services.HealthMonitor {
+	return r.healthMonitor
+}
+
+// GetRolloutService 获取镜像发布服务
+func (r *Router) GetRolloutService() *services.ImageRolloutService {
+	return r.rolloutService
+}
+
 // setupGlobalMiddleware 设置全局中间件
 func (r *Router) setupGlobalMiddleware() {
 	// 日志中间件
@@ -58,15 +100,68 @@ func (r *Router) setupRoutes(rg *gin.RouterGroup) {
 
 	// K8s 容器管理 API
 	k8s := rg.Group("/k8s")
+	// 可选认证：携带令牌时识别平台用户，用于身份模拟和审计
+	k8s.Use(r.jwtAuth.OptionalAuth())
 	{
 		// 容器管理
 		k8s.GET("/containers", r.k8sController.GetContainers)
+		k8s.GET("/containers/aggregate", r.k8sController.GetAggregatedContainers)
 		k8s.POST("/containers", r.k8sController.CreateContainer)
+		k8s.POST("/containers/validate", r.k8sController.ValidateContainer)
+		k8s.POST("/containers/batch", r.k8sController.BatchContainers)
+		k8s.POST("/containers/import", r.k8sController.ImportContainer)
+		k8s.GET("/containers/:namespace/:podName", r.k8sController.GetContainer)
+		k8s.GET("/containers/:namespace/:podName/logs", r.k8sController.GetContainerLogs)
+		k8s.GET("/containers/:namespace/:podName/export", r.k8sController.ExportContainer)
 		k8s.POST("/containers/:namespace/:podName/start", r.k8sController.StartContainer)
 		k8s.POST("/containers/:namespace/:podName/stop", r.k8sController.StopContainer)
+		k8s.POST("/containers/:namespace/:podName/pause", r.k8sController.PauseContainer)
+		k8s.POST("/containers/:namespace/:podName/resume", r.k8sController.ResumeContainer)
 		k8s.POST("/containers/:namespace/:podName/restart", r.k8sController.RestartContainer)
+		k8s.PUT("/containers/:namespace/:podName/service", r.k8sController.ExposeContainer)
+		k8s.PUT("/containers/:namespace/:podName/probes", r.k8sController.UpdateContainerHealth)
 		k8s.DELETE("/containers/:namespace/:podName", r.k8sController.DeleteContainer)
 
+		// 容器模板
+		k8s.GET("/containers/templates", r.templateController.ListTemplates)
+		k8s.POST("/containers/templates", r.templateController.CreateTemplate)
+		k8s.GET("/containers/templates/:name", r.templateController.GetTemplate)
+		k8s.PUT("/containers/templates/:name", r.templateController.UpdateTemplate)
+		k8s.DELETE("/containers/templates/:name", r.templateController.DeleteTemplate)
+		k8s.GET("/containers/templates/:name/versions", r.templateController.ListTemplateVersions)
+		k8s.POST("/containers/templates/:name/instantiate", r.templateController.InstantiateTemplate)
+
+		// 容器命令执行和交互式终端
+		k8s.POST("/containers/exec", r.execController.CreateExecSession)
+		k8s.GET("/containers/exec/:sessionId", r.execController.ExecTerminal)
+		k8s.POST("/containers/:namespace/:podName/exec", r.execController.ExecCommand)
+
+		// 容器镜像修改、发布进度和回滚
+		k8s.PUT("/containers/:namespace/:podName/image", r.rolloutController.UpdateImage)
+		k8s.GET("/containers/:namespace/:podName/rollout", r.rolloutController.GetRollout)
+		k8s.GET("/containers/:namespace/:podName/revisions", r.rolloutController.ListRevisions)
+		k8s.POST("/containers/:namespace/:podName/rollback", r.rolloutController.RollbackImage)
+
+		// 端口转发：HTTP 代理和 TCP 隧道
+		k8s.POST("/containers/:namespace/:podName/portforward", r.portForwardController.CreatePortForward)
+		k8s.Any("/portforward/:sessionId/proxy/*path", r.portForwardController.ProxyPortForward)
+		k8s.GET("/portforward/:sessionId/tunnel", r.portForwardController.PortForwardTunnel)
+		k8s.DELETE("/portforward/:sessionId", r.portForwardController.DeletePortForward)
+		k8s.GET("/containers/:namespace/:podName/files", r.fileController.DownloadFile)
+		k8s.POST("/containers/:namespace/:podName/files", r.fileController.UploadFile)
+
+		// 连接管理
+		k8s.GET("/connections", r.connectionController.ListConnections)
+		k8s.POST("/connections", r.connectionController.CreateConnection)
+		k8s.GET("/connections/:id", r.connectionController.GetConnection)
+		k8s.PUT("/connections/:id", r.connectionController.UpdateConnection)
+		k8s.DELETE("/connections/:id", r.connectionController.DeleteConnection)
+		k8s.POST("/connections/:id/activate", r.connectionController.ActivateConnection)
+		k8s.GET("/connections/:id/health", r.healthController.GetHealth)
+		k8s.GET("/connections/:id/health/history", r.healthController.GetHealthHistory)
+		k8s.POST("/connections/:id/health/check", r.healthController.CheckHealth)
+		k8s.POST("/kubeconfig/contexts", r.connectionController.ListKubeconfigContexts)
+
 		// 连接测试
 		k8s.POST("/test-connection", r.k8sController.TestConnection)
 	}
@@ -83,4 +178,4 @@ func (r *Router) healthCheck(c *gin.Context) {
 			"service":   "container-platform-backend",
 		},
 	})
-}
\ No newline at end of file
+}
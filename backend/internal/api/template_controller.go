@@ -0,0 +1,303 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"container-platform-backend/internal/services"
+)
+
+// TemplateController 容器模板控制器
+type TemplateController struct {
+	templateService *services.TemplateService
+	namespaceAccess *services.NamespaceAccessService
+	containers      *K8sController
+}
+
+// NewTemplateController 创建容器模板控制器，使用模板创建容器时复用 containers 的创建流程
+func NewTemplateController(
+	templateService *services.TemplateService,
+	namespaceAccess *services.NamespaceAccessService,
+	containers *K8sController,
+) *TemplateController {
+	return &TemplateController{
+		templateService: templateService,
+		namespaceAccess: namespaceAccess,
+		containers:      containers,
+	}
+}
+
+// ListTemplates 获取容器模板列表
+// @Summary 获取容器模板列表
+// @Description 获取各模板的最新版本；指定 namespace 时只返回全局模板和该命名空间的模板
+// @Tags k8s
+// @Produce json
+// @Param namespace query string false "命名空间"
+// @Success 200 {object} APIResponse{data=[]model.ContainerTemplate}
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/templates [get]
+func (c *TemplateController) ListTemplates(ctx *gin.Context) {
+	templates, err := c.templateService.ListTemplates(requestContext(ctx), ctx.Query("namespace"))
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to list container templates", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container templates retrieved successfully", templates)
+}
+
+// GetTemplate 获取容器模板
+// @Summary 获取容器模板
+// @Description 获取模板的指定版本（默认最新版本）；指定 namespace 时优先使用该命名空间的模板，不存在时使用同名的全局模板
+// @Tags k8s
+// @Produce json
+// @Param name path string true "模板名称"
+// @Param namespace query string false "命名空间"
+// @Param version query int false "版本，默认最新版本"
+// @Success 200 {object} APIResponse{data=model.ContainerTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/containers/templates/{name} [get]
+func (c *TemplateController) GetTemplate(ctx *gin.Context) {
+	version, ok := parseTemplateVersion(ctx)
+	if !ok {
+		return
+	}
+
+	template, err := c.templateService.GetTemplate(requestContext(ctx), ctx.Query("namespace"), ctx.Param("name"), version)
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to get container template", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container template retrieved successfully", template)
+}
+
+// ListTemplateVersions 获取容器模板的版本历史
+// @Summary 获取容器模板的版本历史
+// @Description 获取模板在指定作用域（namespace 为空时为全局）下的所有版本，最新版本在前
+// @Tags k8s
+// @Produce json
+// @Param name path string true "模板名称"
+// @Param namespace query string false "命名空间，为空时为全局模板"
+// @Success 200 {object} APIResponse{data=[]model.ContainerTemplate}
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/containers/templates/{name}/versions [get]
+func (c *TemplateController) ListTemplateVersions(ctx *gin.Context) {
+	templates, err := c.templateService.ListTemplateVersions(requestContext(ctx), ctx.Query("namespace"), ctx.Param("name"))
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to list container template versions", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container template versions retrieved successfully", templates)
+}
+
+// CreateTemplate 创建容器模板
+// @Summary 创建容器模板
+// @Description 创建模板的第一个版本。spec 与创建容器的请求相同，字符串中可用 ${变量名} 引用 variables 中声明的变量。
+// @Description 全局模板只有平台管理员可以创建，命名空间模板需要该命名空间的写权限。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param template body services.TemplateRequest true "模板"
+// @Success 200 {object} APIResponse{data=model.ContainerTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /api/k8s/containers/templates [post]
+func (c *TemplateController) CreateTemplate(ctx *gin.Context) {
+	var req services.TemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	if !c.authorize(ctx, req.Namespace) {
+		return
+	}
+
+	template, err := c.templateService.CreateTemplate(requestContext(ctx), &req)
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to create container template", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container template created successfully", template)
+}
+
+// UpdateTemplate 修改容器模板
+// @Summary 修改容器模板
+// @Description 为模板生成新版本，旧版本保持不变，可继续按版本使用。权限要求与创建模板相同。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param name path string true "模板名称"
+// @Param template body services.TemplateRequest true "模板"
+// @Success 200 {object} APIResponse{data=model.ContainerTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/containers/templates/{name} [put]
+func (c *TemplateController) UpdateTemplate(ctx *gin.Context) {
+	var req services.TemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	req.Name = ctx.Param("name")
+	if !c.authorize(ctx, req.Namespace) {
+		return
+	}
+
+	template, err := c.templateService.UpdateTemplate(requestContext(ctx), &req)
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to update container template", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container template updated successfully", template)
+}
+
+// DeleteTemplate 删除容器模板
+// @Summary 删除容器模板
+// @Description 删除模板在指定作用域（namespace 为空时为全局）下的所有版本。权限要求与创建模板相同。
+// @Tags k8s
+// @Produce json
+// @Param name path string true "模板名称"
+// @Param namespace query string false "命名空间，为空时为全局模板"
+// @Success 200 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /api/k8s/containers/templates/{name} [delete]
+func (c *TemplateController) DeleteTemplate(ctx *gin.Context) {
+	namespace := ctx.Query("namespace")
+	if !c.authorize(ctx, namespace) {
+		return
+	}
+
+	if err := c.templateService.DeleteTemplate(requestContext(ctx), namespace, ctx.Param("name")); err != nil {
+		templateErrorResponse(ctx, "Failed to delete container template", err)
+		return
+	}
+
+	SuccessResponse(ctx, "Container template deleted successfully", nil)
+}
+
+// InstantiateTemplate 使用模板创建容器
+// @Summary 使用模板创建容器
+// @Description 使用参数渲染模板（未提供的参数使用默认值）并创建容器，结果与创建容器接口相同。
+// @Description 优先使用目标命名空间的同名模板，其次使用全局模板；dryRun=true 时只校验渲染结果，返回校验结果和 YAML 清单。
+// @Tags k8s
+// @Accept json
+// @Produce json
+// @Param name path string true "模板名称"
+// @Param instantiate body services.InstantiateTemplateRequest true "参数"
+// @Param connectionId query int false "连接ID，默认使用激活的连接"
+// @Success 200 {object} APIResponse{data=CreateContainerResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/k8s/containers/templates/{name}/instantiate [post]
+func (c *TemplateController) InstantiateTemplate(ctx *gin.Context) {
+	var req services.InstantiateTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// 连接到集群
+	connection, ok := c.containers.connect(ctx, ctx.Query("connectionId"))
+	if !ok {
+		return
+	}
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = connection.Namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	template, err := c.templateService.GetTemplate(requestContext(ctx), namespace, ctx.Param("name"), req.Version)
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to get container template", err)
+		return
+	}
+
+	containerReq, err := services.RenderTemplate(template, req.Parameters)
+	if err != nil {
+		templateErrorResponse(ctx, "Failed to render container template", err)
+		return
+	}
+	containerReq.Namespace = namespace
+	if req.Name != "" {
+		containerReq.Name = req.Name
+	}
+	if containerReq.Name == "" {
+		containerReq.Name = template.Name
+	}
+
+	if req.DryRun {
+		result, err := c.containers.k8sService.ValidateContainer(requestContext(ctx), connection, containerReq)
+		if err != nil {
+			containerErrorResponse(ctx, "Failed to validate container", err)
+			return
+		}
+		SuccessResponse(ctx, "Container template rendered successfully", result)
+		return
+	}
+
+	if response, ok := c.containers.createContainer(ctx, connection, containerReq); ok {
+		SuccessResponse(ctx, "Container created successfully", response)
+	}
+}
+
+// authorize 校验修改模板的权限：全局模板需要平台管理员，命名空间模板需要该命名空间的写权限
+func (c *TemplateController) authorize(ctx *gin.Context, namespace string) bool {
+	var err error
+	if namespace == "" {
+		err = c.namespaceAccess.AuthorizePlatform(requestContext(ctx))
+	} else {
+		err = c.namespaceAccess.Authorize(requestContext(ctx), namespace, services.NamespacePermissionWrite)
+	}
+	if err != nil {
+		templateErrorResponse(ctx, "Not allowed to modify this container template", err)
+		return false
+	}
+	return true
+}
+
+// parseTemplateVersion 解析查询参数中的模板版本，未指定时为 0
+func parseTemplateVersion(ctx *gin.Context) (int, bool) {
+	value := ctx.Query("version")
+	if value == "" {
+		return 0, true
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid template version", err)
+		return 0, false
+	}
+	return version, true
+}
+
+// templateErrorResponse 根据模板服务错误返回对应的状态码
+func templateErrorResponse(ctx *gin.Context, message string, err error) {
+	var validationErrs services.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrs):
+		ValidationError(ctx, validationErrs)
+	case errors.Is(err, services.ErrTemplateNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, message, err)
+	case errors.Is(err, services.ErrTemplateExists):
+		ErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, services.ErrNamespaceAccessDenied):
+		ErrorResponse(ctx, http.StatusForbidden, message, err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
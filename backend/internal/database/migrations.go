@@ -24,7 +24,7 @@ type migrationRecord struct {
 
 // getAllMigrations 获取所有迁移
 func getAllMigrations() []Migration {
-	return []Migration{
+	migrations := []Migration{
 		&CreateUsersTable{},
 		&CreateRolesTable{},
 		&CreateNamespacesTable{},
@@ -35,7 +35,35 @@ func getAllMigrations() []Migration {
 		&CreateVolumesTable{},
 		&CreateOperationLogsTable{},
 		&CreateResourceUsageTable{},
+		&CreateK8sConnectionsTable{},
+		&AddK8sConnectionContextFields{},
+		&AddK8sConnectionTLSFields{},
+		&AddK8sConnectionImpersonationFields{},
+		&CreateClusterHealthChecksTable{},
+		&AddK8sConnectionBuiltInField{},
+		&AddContainerPausedAtField{},
+		&CreateContainerVolumesTable{},
+		&CreatePortMappingsTable{},
+		&CreateNamespacePermissionsTable{},
+		&AddOperationLogParentOperationID{},
+		&CreateContainerTemplatesTable{},
+		&CreateEnvironmentVariablesTable{},
+		&CreateContainerRevisionsTable{},
 	}
+
+	// 嵌入的 BaseMigration 无法感知外层重写的 Name()，
+	// 在这里同步名称，保证迁移记录和状态查询使用正确的名称
+	for _, migration := range migrations {
+		if named, ok := migration.(interface{ setName(string) }); ok {
+			named.setName(migration.Name())
+		}
+	}
+	return migrations
+}
+
+// GetAllMigrations 获取所有迁移（按执行顺序）
+func GetAllMigrations() []Migration {
+	return getAllMigrations()
 }
 
 // CreateMigrationTable 创建迁移记录表
@@ -58,7 +86,7 @@ func isMigrationApplied(db *gorm.DB, name string) (bool, error) {
 	}
 
 	var count int64
-	err := db.Model(&migrationRecord{}).Where("name = ?", name).Count(&count)
+	err := db.Model(&migrationRecord{}).Where("name = ?", name).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
@@ -92,6 +120,10 @@ func (m *BaseMigration) Name() string {
 	return m.name
 }
 
+func (m *BaseMigration) setName(name string) {
+	m.name = name
+}
+
 func (m *BaseMigration) Status(db *gorm.DB) string {
 	applied, err := isMigrationApplied(db, m.Name())
 	if err != nil {
@@ -2,6 +2,8 @@ package database
 
 import (
 	"gorm.io/gorm"
+
+	"container-platform-backend/internal/model"
 )
 
 // CreateUsersTable 创建用户表
@@ -250,4 +252,358 @@ func (m *CreateResourceUsageTable) Down(db *gorm.DB) error {
 		return err
 	}
 	return m.removeRecord(db)
-}
\ No newline at end of file
+}
+
+// CreateK8sConnectionsTable 创建 Kubernetes 连接表
+type CreateK8sConnectionsTable struct {
+	BaseMigration
+}
+
+func (m *CreateK8sConnectionsTable) Name() string {
+	return "create_k8s_connections_table"
+}
+
+func (m *CreateK8sConnectionsTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.K8sConnection{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateK8sConnectionsTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("k8s_connections"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// AddK8sConnectionContextFields 为 Kubernetes 连接表添加 kubeconfig 上下文选择字段
+type AddK8sConnectionContextFields struct {
+	BaseMigration
+}
+
+func (m *AddK8sConnectionContextFields) Name() string {
+	return "add_k8s_connection_context_fields"
+}
+
+func (m *AddK8sConnectionContextFields) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.K8sConnection{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *AddK8sConnectionContextFields) Down(db *gorm.DB) error {
+	for _, column := range []string{"context_name", "cluster_name", "auth_info_name"} {
+		if err := db.Migrator().DropColumn(&model.K8sConnection{}, column); err != nil {
+			return err
+		}
+	}
+	return m.removeRecord(db)
+}
+
+// AddK8sConnectionTLSFields 为 Kubernetes 连接表添加 TLS 及客户端证书字段
+type AddK8sConnectionTLSFields struct {
+	BaseMigration
+}
+
+func (m *AddK8sConnectionTLSFields) Name() string {
+	return "add_k8s_connection_tls_fields"
+}
+
+func (m *AddK8sConnectionTLSFields) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.K8sConnection{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *AddK8sConnectionTLSFields) Down(db *gorm.DB) error {
+	columns := []string{"ca_data", "client_cert_data", "client_key_data", "tls_server_name", "insecure_skip_tls_verify"}
+	for _, column := range columns {
+		if err := db.Migrator().DropColumn(&model.K8sConnection{}, column); err != nil {
+			return err
+		}
+	}
+	return m.removeRecord(db)
+}
+
+// AddK8sConnectionImpersonationFields 为 Kubernetes 连接表添加身份模拟字段
+type AddK8sConnectionImpersonationFields struct {
+	BaseMigration
+}
+
+func (m *AddK8sConnectionImpersonationFields) Name() string {
+	return "add_k8s_connection_impersonation_fields"
+}
+
+func (m *AddK8sConnectionImpersonationFields) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.K8sConnection{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *AddK8sConnectionImpersonationFields) Down(db *gorm.DB) error {
+	for _, column := range []string{"impersonation_mode", "impersonate_user", "impersonate_groups"} {
+		if err := db.Migrator().DropColumn(&model.K8sConnection{}, column); err != nil {
+			return err
+		}
+	}
+	return m.removeRecord(db)
+}
+
+// CreateClusterHealthChecksTable 创建集群健康检查记录表，并为连接表添加健康状态字段
+type CreateClusterHealthChecksTable struct {
+	BaseMigration
+}
+
+func (m *CreateClusterHealthChecksTable) Name() string {
+	return "create_cluster_health_checks_table"
+}
+
+func (m *CreateClusterHealthChecksTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.K8sConnection{}, &model.ClusterHealthCheck{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateClusterHealthChecksTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("cluster_health_checks"); err != nil {
+		return err
+	}
+	for _, column := range []string{"status", "status_message", "last_checked_at"} {
+		if err := db.Migrator().DropColumn(&model.K8sConnection{}, column); err != nil {
+			return err
+		}
+	}
+	return m.removeRecord(db)
+}
+
+// AddK8sConnectionBuiltInField 为 Kubernetes 连接表添加内置连接标记
+type AddK8sConnectionBuiltInField struct {
+	BaseMigration
+}
+
+func (m *AddK8sConnectionBuiltInField) Name() string {
+	return "add_k8s_connection_built_in_field"
+}
+
+func (m *AddK8sConnectionBuiltInField) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.K8sConnection{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *AddK8sConnectionBuiltInField) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&model.K8sConnection{}, "built_in"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// AddContainerPausedAtField 为容器表添加暂停时间字段
+type AddContainerPausedAtField struct {
+	BaseMigration
+}
+
+func (m *AddContainerPausedAtField) Name() string {
+	return "add_container_paused_at_field"
+}
+
+func (m *AddContainerPausedAtField) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.Container{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *AddContainerPausedAtField) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&model.Container{}, "paused_at"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// CreateContainerVolumesTable 创建容器存储卷关联表，并为容器表添加所属连接字段
+type CreateContainerVolumesTable struct {
+	BaseMigration
+}
+
+func (m *CreateContainerVolumesTable) Name() string {
+	return "create_container_volumes_table"
+}
+
+func (m *CreateContainerVolumesTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.Container{}, &model.ContainerVolume{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateContainerVolumesTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("container_volumes"); err != nil {
+		return err
+	}
+	if err := db.Migrator().DropColumn(&model.Container{}, "connection_id"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// CreatePortMappingsTable 创建端口映射表
+type CreatePortMappingsTable struct {
+	BaseMigration
+}
+
+func (m *CreatePortMappingsTable) Name() string {
+	return "create_port_mappings_table"
+}
+
+func (m *CreatePortMappingsTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.PortMapping{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreatePortMappingsTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("port_mappings"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// CreateNamespacePermissionsTable 补全命名空间权限表的权限等级、授权人和过期时间字段
+// namespaces 表迁移时只创建了多对多关联的连接列
+type CreateNamespacePermissionsTable struct {
+	BaseMigration
+}
+
+func (m *CreateNamespacePermissionsTable) Name() string {
+	return "create_namespace_permissions_table"
+}
+
+func (m *CreateNamespacePermissionsTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.NamespacePermission{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateNamespacePermissionsTable) Down(db *gorm.DB) error {
+	for _, column := range []string{"permission_level", "granted_by", "granted_at", "expires_at"} {
+		if err := db.Migrator().DropColumn(&model.NamespacePermission{}, column); err != nil {
+			return err
+		}
+	}
+	return m.removeRecord(db)
+}
+
+// AddOperationLogParentOperationID 为操作日志增加父操作ID，关联批量操作及其中的单个操作
+type AddOperationLogParentOperationID struct {
+	BaseMigration
+}
+
+func (m *AddOperationLogParentOperationID) Name() string {
+	return "add_operation_log_parent_operation_id"
+}
+
+func (m *AddOperationLogParentOperationID) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.OperationLog{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *AddOperationLogParentOperationID) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&model.OperationLog{}, "parent_operation_id"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// CreateContainerTemplatesTable 创建容器模板表
+type CreateContainerTemplatesTable struct {
+	BaseMigration
+}
+
+func (m *CreateContainerTemplatesTable) Name() string {
+	return "create_container_templates_table"
+}
+
+func (m *CreateContainerTemplatesTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.ContainerTemplate{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateContainerTemplatesTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("container_templates"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// CreateEnvironmentVariablesTable 创建环境变量表
+type CreateEnvironmentVariablesTable struct {
+	BaseMigration
+}
+
+func (m *CreateEnvironmentVariablesTable) Name() string {
+	return "create_environment_variables_table"
+}
+
+func (m *CreateEnvironmentVariablesTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.EnvironmentVariable{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateEnvironmentVariablesTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("environment_variables"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
+
+// CreateContainerRevisionsTable 创建容器镜像修订表
+type CreateContainerRevisionsTable struct {
+	BaseMigration
+}
+
+func (m *CreateContainerRevisionsTable) Name() string {
+	return "create_container_revisions_table"
+}
+
+func (m *CreateContainerRevisionsTable) Up(db *gorm.DB) error {
+	err := db.AutoMigrate(&model.ContainerRevision{})
+	if err != nil {
+		return err
+	}
+	return m.record(db)
+}
+
+func (m *CreateContainerRevisionsTable) Down(db *gorm.DB) error {
+	if err := db.Migrator().DropTable("container_revisions"); err != nil {
+		return err
+	}
+	return m.removeRecord(db)
+}
@@ -4,6 +4,7 @@ import (
 	"container-platform-backend/internal/model"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"path/filepath"
 	"time"
@@ -18,19 +19,71 @@ import (
 
 // Client Kubernetes客户端
 type Client struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 	config    *rest.Config
 	namespace string
 }
 
+// ClientsetFactory 根据REST配置创建clientset，测试时可注入 fake clientset
+type ClientsetFactory func(config *rest.Config) (kubernetes.Interface, error)
+
+// DefaultClientsetFactory 使用 client-go 创建真实的clientset
+func DefaultClientsetFactory(config *rest.Config) (kubernetes.Interface, error) {
+	return kubernetes.NewForConfig(config)
+}
+
 // NewClient 创建Kubernetes客户端
 func NewClient(configPath, namespace string) (*Client, error) {
+	return NewClientWithFactory(configPath, namespace, DefaultClientsetFactory)
+}
+
+// NewClientWithFactory 使用指定的 factory 创建Kubernetes客户端
+func NewClientWithFactory(configPath, namespace string, factory ClientsetFactory) (*Client, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建clientset
+	clientset, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建Kubernetes客户端失败: %w", err)
+	}
+
+	// 设置默认命名空间
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	log.Printf("Kubernetes客户端初始化成功，命名空间: %s", namespace)
+
+	return &Client{
+		clientset: clientset,
+		config:    config,
+		namespace: namespace,
+	}, nil
+}
+
+// NewClientForClientset 使用已创建的clientset构建绑定到指定命名空间的客户端
+func NewClientForClientset(clientset kubernetes.Interface, config *rest.Config, namespace string) *Client {
+	if namespace == "" {
+		namespace = "default"
+	}
+	return &Client{
+		clientset: clientset,
+		config:    config,
+		namespace: namespace,
+	}
+}
+
+// LoadConfig 按顺序尝试 指定的配置路径、集群内配置、~/.kube/config 加载配置
+func LoadConfig(configPath string) (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 
 	// 尝试使用提供的配置路径
 	if configPath != "" {
-		config, err = clientcmd.BuildConfigFromFlags(configPath, "")
+		config, err = clientcmd.BuildConfigFromFlags("", configPath)
 		if err != nil {
 			log.Printf("Warning: 无法从配置路径 %s 加载配置: %v", configPath, err)
 		}
@@ -54,24 +107,7 @@ func NewClient(configPath, namespace string) (*Client, error) {
 		}
 	}
 
-	// 创建clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("创建Kubernetes客户端失败: %w", err)
-	}
-
-	// 设置默认命名空间
-	if namespace == "" {
-		namespace = "default"
-	}
-
-	log.Printf("Kubernetes客户端初始化成功，命名空间: %s", namespace)
-
-	return &Client{
-		clientset: clientset,
-		config:    config,
-		namespace: namespace,
-	}, nil
+	return config, nil
 }
 
 // GetNamespace 获取当前命名空间
@@ -79,6 +115,11 @@ func (c *Client) GetNamespace() string {
 	return c.namespace
 }
 
+// GetConfig 获取客户端使用的REST配置
+func (c *Client) GetConfig() *rest.Config {
+	return c.config
+}
+
 // ListPods 列出Pod
 func (c *Client) ListPods(ctx context.Context, labelSelector string) ([]corev1.Pod, error) {
 	listOptions := metav1.ListOptions{}
@@ -152,17 +193,12 @@ func (c *Client) GetPodLogs(ctx context.Context, podName, containerName string,
 	defer logs.Close()
 
 	// 读取日志
-	buf := make([]byte, 4096)
-	var result string
-	for {
-		n, err := logs.Read(buf)
-		if err != nil {
-			break
-		}
-		result += string(buf[:n])
+	result, err := io.ReadAll(logs)
+	if err != nil {
+		return "", fmt.Errorf("读取Pod日志失败: %w", err)
 	}
 
-	return result, nil
+	return string(result), nil
 }
 
 // ListServices 列出Service
@@ -200,6 +236,16 @@ func (c *Client) CreateService(ctx context.Context, service *corev1.Service) (*c
 	return createdService, nil
 }
 
+// UpdateService 更新Service
+func (c *Client) UpdateService(ctx context.Context, service *corev1.Service) (*corev1.Service, error) {
+	updatedService, err := c.clientset.CoreV1().Services(c.namespace).Update(ctx, service, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("更新Service %s 失败: %w", service.Name, err)
+	}
+
+	return updatedService, nil
+}
+
 // DeleteService 删除Service
 func (c *Client) DeleteService(ctx context.Context, name string) error {
 	err := c.clientset.CoreV1().Services(c.namespace).Delete(ctx, name, metav1.DeleteOptions{})
@@ -308,7 +354,7 @@ func (c *Client) GetPersistentVolumeClaim(ctx context.Context, name string) (*co
 // HealthCheck 健康检查
 func (c *Client) HealthCheck(ctx context.Context) error {
 	// 检查API服务器连接
-	_, err := c.clientset.ServerVersion()
+	_, err := c.clientset.Discovery().ServerVersion()
 	if err != nil {
 		return fmt.Errorf("无法连接到Kubernetes API服务器: %w", err)
 	}
@@ -324,7 +370,7 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 
 // GetClusterInfo 获取集群信息
 func (c *Client) GetClusterInfo(ctx context.Context) (map[string]interface{}, error) {
-	serverVersion, err := c.clientset.ServerVersion()
+	serverVersion, err := c.clientset.Discovery().ServerVersion()
 	if err != nil {
 		return nil, fmt.Errorf("获取服务器版本失败: %w", err)
 	}
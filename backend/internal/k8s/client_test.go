@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://kubernetes.example.com
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+
+func newFakeClient(t *testing.T, namespace string) (*Client, *fake.Clientset) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	clientSet := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}})
+	client, err := NewClientWithFactory(path, namespace, func(*rest.Config) (kubernetes.Interface, error) {
+		return clientSet, nil
+	})
+	if err != nil {
+		t.Fatalf("NewClientWithFactory() error = %v", err)
+	}
+	return client, clientSet
+}
+
+func TestNewClientWithFactory(t *testing.T) {
+	client, _ := newFakeClient(t, "")
+
+	if client.GetNamespace() != "default" {
+		t.Errorf("GetNamespace() = %s, want default", client.GetNamespace())
+	}
+	if client.GetConfig().Host != "https://kubernetes.example.com" {
+		t.Errorf("GetConfig().Host = %s", client.GetConfig().Host)
+	}
+	if client.GetConfig().BearerToken != "test-token" {
+		t.Errorf("GetConfig().BearerToken = %s", client.GetConfig().BearerToken)
+	}
+}
+
+func TestClientPodLifecycle(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t, "apps")
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx"}}},
+	}
+	if _, err := client.CreatePod(ctx, pod); err != nil {
+		t.Fatalf("CreatePod() error = %v", err)
+	}
+
+	pods, err := client.ListPods(ctx, "app=web")
+	if err != nil {
+		t.Fatalf("ListPods() error = %v", err)
+	}
+	if len(pods) != 1 || pods[0].Namespace != "apps" {
+		t.Fatalf("ListPods() = %v, want one pod in apps", pods)
+	}
+
+	if err := client.DeletePod(ctx, "web"); err != nil {
+		t.Fatalf("DeletePod() error = %v", err)
+	}
+	if _, err := client.GetPod(ctx, "web"); err == nil {
+		t.Error("GetPod() after delete succeeded, want error")
+	}
+}
+
+func TestClientGetPodLogs(t *testing.T) {
+	client, _ := newFakeClient(t, "apps")
+
+	// 假客户端对任意 Pod 返回固定日志
+	logs, err := client.GetPodLogs(context.Background(), "web", "web", 100)
+	if err != nil {
+		t.Fatalf("GetPodLogs() error = %v", err)
+	}
+	if logs != "fake logs" {
+		t.Errorf("GetPodLogs() = %q, want fake logs", logs)
+	}
+}
+
+func TestClientCreateService(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeClient(t, "apps")
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports:    []corev1.ServicePort{{Port: 80}},
+		},
+	}
+	if _, err := client.CreateService(ctx, service); err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+
+	got, err := client.GetService(ctx, "web")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if got.Namespace != "apps" {
+		t.Errorf("service namespace = %s, want apps", got.Namespace)
+	}
+
+	got.Spec.Type = corev1.ServiceTypeNodePort
+	if _, err := client.UpdateService(ctx, got); err != nil {
+		t.Fatalf("UpdateService() error = %v", err)
+	}
+	if got, _ = client.GetService(ctx, "web"); got.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("service type = %s, want NodePort", got.Spec.Type)
+	}
+}
+
+func TestNewClientForClientset(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+
+	client := NewClientForClientset(clientSet, nil, "")
+	if client.GetNamespace() != "default" {
+		t.Errorf("GetNamespace() = %s, want default", client.GetNamespace())
+	}
+	if _, err := client.GetService(context.Background(), "web"); err != nil {
+		t.Errorf("GetService() error = %v", err)
+	}
+}
+
+func TestClientHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	client, _ := newFakeClient(t, "apps")
+	if err := client.HealthCheck(ctx); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+
+	client, _ = newFakeClient(t, "missing")
+	if err := client.HealthCheck(ctx); err == nil {
+		t.Error("HealthCheck() for missing namespace succeeded, want error")
+	}
+}
+
+func TestConvertToContainerModel(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.PodSpec{
+			NodeName:   "node-1",
+			Containers: []corev1.Container{{Name: "web", Image: "nginx"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+	}
+
+	container := ConvertToContainerModel(pod)
+	if container.Name != "web" || container.Status != "Running" || container.PodIP != "10.0.0.1" || container.NodeName != "node-1" {
+		t.Errorf("ConvertToContainerModel() = %+v", container)
+	}
+}
@@ -293,9 +293,9 @@ func (j *JWTAuth) RefreshTokenMiddleware() gin.HandlerFunc {
 		// 这里应该从数据库重新获取用户信息
 		// 为了简化，我们直接使用令牌中的信息
 		user := &model.User{
-			ID:       claims.UserID,
 			Username: claims.Username,
 		}
+		user.ID = claims.UserID
 
 		// 生成新的访问令牌
 		newToken, err := j.GenerateToken(user)
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -96,6 +99,16 @@ type ContainerImage struct {
 	Status       string    `gorm:"default:available;size:20" json:"status"`
 }
 
+// 容器状态，暂停与停止是不同的状态：暂停保留工作负载定义和副本数，可通过恢复继续运行
+const (
+	ContainerStatusPending          = "pending"
+	ContainerStatusRunning          = "running"
+	ContainerStatusPaused           = "paused"
+	ContainerStatusStopped          = "stopped"
+	ContainerStatusFailed           = "failed"
+	ContainerStatusCrashLoopBackOff = "crash_loop_back_off"
+)
+
 // Container 容器实例模型
 type Container struct {
 	BaseModel
@@ -103,11 +116,12 @@ type Container struct {
 	DisplayName     string          `gorm:"size:253" json:"displayName"`
 	NamespaceID     uint            `gorm:"not null" json:"namespaceId"`
 	ImageID         uint            `gorm:"not null" json:"imageId"`
+	ConnectionID    *uint           `gorm:"index" json:"connectionId"`
 	K8sName         string          `gorm:"size:253;not null" json:"k8sName"`
 	PodName         string          `gorm:"size:253" json:"podName"`
 	DeploymentName  string          `gorm:"size:253" json:"deploymentName"`
 	ReplicaSetName  string          `gorm:"size:253" json:"replicaSetName"`
-	Status          string          `gorm:"default:pending;size:20" json:"status"`
+	Status          string          `gorm:"default:pending;size:20" json:"status"` // pending, running, paused, stopped, failed, crash_loop_back_off
 	Phase           string          `gorm:"size:20" json:"phase"`
 	Reason          string          `gorm:"size:100" json:"reason"`
 	Message         string          `gorm:"type:text" json:"message"`
@@ -120,6 +134,7 @@ type Container struct {
 	HostIP          string          `gorm:"size:45" json:"hostIp"`
 	NodeName        string          `gorm:"size:63" json:"nodeName"`
 	StartedAt       *time.Time      `json:"startedAt"`
+	PausedAt        *time.Time      `json:"pausedAt"`
 	FinishedAt      *time.Time      `json:"finishedAt"`
 	CreatedBy       *uint           `json:"createdBy"`
 	UpdatedBy       *uint           `json:"updatedBy"`
@@ -141,6 +156,43 @@ type ContainerConfig struct {
 	Container   Container      `gorm:"foreignKey:ContainerID" json:"container,omitempty"`
 }
 
+// ContainerTemplate 容器模板，每次修改生成新版本，同一作用域下同名模板的各版本各占一条记录
+type ContainerTemplate struct {
+	BaseModel
+	Name        string `gorm:"size:63;not null;uniqueIndex:idx_container_templates_scope_version" json:"name"`
+	// 为空时为全局模板，否则只能在该命名空间中使用
+	Namespace   string `gorm:"size:63;not null;default:'';uniqueIndex:idx_container_templates_scope_version" json:"namespace"`
+	Version     int    `gorm:"not null;uniqueIndex:idx_container_templates_scope_version" json:"version"`
+	Description string `gorm:"type:text" json:"description"`
+	// 创建容器请求，字符串中可以用 ${变量名} 引用变量
+	Spec        JSONB  `gorm:"type:jsonb;not null" json:"spec"`
+	// 变量名到类型、默认值等定义的映射
+	Variables   JSONB  `gorm:"type:jsonb;default:'{}'" json:"variables"`
+	CreatedBy   *uint  `json:"createdBy"`
+}
+
+// ContainerRevision 容器镜像修订记录，每次修改或回滚镜像生成一个新修订
+type ContainerRevision struct {
+	BaseModel
+	ConnectionID  uint       `gorm:"not null;uniqueIndex:idx_container_revisions_workload_revision" json:"connectionId"`
+	Namespace     string     `gorm:"size:63;not null;uniqueIndex:idx_container_revisions_workload_revision" json:"namespace"`
+	WorkloadKind  string     `gorm:"size:20;not null;uniqueIndex:idx_container_revisions_workload_revision" json:"workloadKind"`
+	WorkloadName  string     `gorm:"size:253;not null;uniqueIndex:idx_container_revisions_workload_revision" json:"workloadName"`
+	Revision      int        `gorm:"not null;uniqueIndex:idx_container_revisions_workload_revision" json:"revision"`
+	ContainerName string     `gorm:"size:253;not null" json:"containerName"`
+	Image         string     `gorm:"size:500;not null" json:"image"`
+	PreviousImage string     `gorm:"size:500" json:"previousImage"`
+	Action        string     `gorm:"size:20;not null" json:"action"` // create, update, rollback
+	// 回滚时为回滚到的修订号
+	RollbackTo    *int       `json:"rollbackTo,omitempty"`
+	Status        string     `gorm:"size:20;not null;index" json:"status"` // progressing, available, failed, superseded
+	Message       string     `gorm:"type:text" json:"message"`
+	OperationID   string     `gorm:"size:36;index" json:"operationId"`
+	CreatedBy     *uint      `json:"createdBy"`
+	Username      string     `gorm:"size:50" json:"username"`
+	CompletedAt   *time.Time `json:"completedAt"`
+}
+
 // Volume 存储卷模型
 type Volume struct {
 	BaseModel
@@ -179,6 +231,8 @@ type PortMapping struct {
 	HostPort      *int   `json:"hostPort"`
 	Protocol      string `gorm:"size:10;default:TCP" json:"protocol"`
 	ServiceName   string `gorm:"size:253" json:"serviceName"`
+	ServiceType   string `gorm:"size:20" json:"serviceType"`
+	ClusterIP     string `gorm:"size:45" json:"clusterIp"`
 	ServicePort   *int   `json:"servicePort"`
 	NodePort      *int   `json:"nodePort"`
 	Container     Container `gorm:"foreignKey:ContainerID" json:"container,omitempty"`
@@ -242,6 +296,8 @@ type Service struct {
 type OperationLog struct {
 	BaseModel
 	OperationID   string    `gorm:"uniqueIndex;not null" json:"operationId"`
+	// 批量操作中单个容器的日志指向批量操作的操作ID
+	ParentOperationID string `gorm:"size:36;index" json:"parentOperationId,omitempty"`
 	UserID        *uint     `json:"userId"`
 	Username      string    `gorm:"size:50" json:"username"`
 	Action        string    `gorm:"size:50;not null" json:"action"`
@@ -296,16 +352,75 @@ type ContainerResourceUsage struct {
 // K8sConnection Kubernetes 连接配置模型
 type K8sConnection struct {
 	BaseModel
-	Name        string `gorm:"not null" json:"name"`
-	Endpoint    string `gorm:"not null" json:"endpoint"`
-	ConfigType  string `gorm:"not null;size:20" json:"configType"` // kubeconfig, token
-	Config      string `gorm:"type:text" json:"config,omitempty"`   // kubeconfig 内容
-	Token       string `gorm:"type:text" json:"token,omitempty"`    // service account token
-	Namespace   string `gorm:"size:63;default:default" json:"namespace"`
-	IsActive    bool   `gorm:"default:false" json:"isActive"`
-	CreatedBy   *uint  `json:"createdBy"`
-	UpdatedBy   *uint  `json:"updatedBy"`
+	Name         string `gorm:"not null" json:"name"`
+	Endpoint     string `gorm:"not null" json:"endpoint"`
+	ConfigType   string `gorm:"not null;size:20" json:"configType"` // kubeconfig, token, local
+	Config       string `gorm:"type:text" json:"config,omitempty"`   // kubeconfig 内容 (YAML/JSON)
+	ContextName  string `gorm:"size:253" json:"contextName"`        // kubeconfig 上下文，为空时使用 current-context
+	ClusterName  string `gorm:"size:253" json:"clusterName"`        // 覆盖上下文中的集群
+	AuthInfoName string `gorm:"size:253" json:"authInfoName"`       // 覆盖上下文中的用户
+	Token        string `gorm:"type:text" json:"token,omitempty"`    // service account token
+	// token 连接的 TLS 配置
+	CAData                string `gorm:"type:text" json:"caData,omitempty"`          // CA 证书 (PEM)
+	ClientCertData        string `gorm:"type:text" json:"clientCertData,omitempty"`  // 客户端证书 (PEM)
+	ClientKeyData         string `gorm:"type:text" json:"clientKeyData,omitempty"`   // 客户端私钥 (PEM)
+	TLSServerName         string `gorm:"size:253" json:"tlsServerName"`              // 校验服务端证书时使用的名称
+	InsecureSkipTLSVerify bool   `gorm:"default:false" json:"insecureSkipTLSVerify"` // 显式跳过证书校验（会被审计）
+	// 身份模拟：none 不模拟；fixed 模拟固定用户/用户组；platform_user 将平台用户映射为 Kubernetes 身份
+	ImpersonationMode string `gorm:"size:20;default:none" json:"impersonationMode"`
+	ImpersonateUser   string `gorm:"size:253" json:"impersonateUser"`    // fixed: 用户或 system:serviceaccount:<ns>:<name>；platform_user: 模板，如 platform:{username}
+	ImpersonateGroups string `gorm:"size:1000" json:"impersonateGroups"` // 逗号分隔，platform_user 模式下支持 {username}/{role}
+	// 健康状态，由后台健康检查维护：unknown, healthy, degraded, unreachable
+	Status        string     `gorm:"size:20;default:unknown" json:"status"`
+	StatusMessage string     `gorm:"type:text" json:"statusMessage"`
+	LastCheckedAt *time.Time `json:"lastCheckedAt"`
+	// 内置连接：服务端启动时根据集群内配置或 KUBECONFIG_PATH 自动注册，不可删除
+	BuiltIn      bool   `gorm:"default:false" json:"builtIn"`
+	Namespace    string `gorm:"size:63;default:default" json:"namespace"`
+	IsActive     bool   `gorm:"default:false" json:"isActive"`
+	CreatedBy    *uint  `json:"createdBy"`
+	UpdatedBy    *uint  `json:"updatedBy"`
+}
+
+// ClusterHealthCheck 集群健康检查记录
+type ClusterHealthCheck struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ConnectionID  uint      `gorm:"not null;index:idx_cluster_health_checks_connection_checked" json:"connectionId"`
+	Status        string    `gorm:"size:20;not null" json:"status"`
+	Reachable     bool      `gorm:"default:false" json:"reachable"`
+	AuthError     bool      `gorm:"default:false" json:"authError"`
+	ServerVersion string    `gorm:"size:50" json:"serverVersion"`
+	LatencyMs     int64     `json:"latencyMs"`
+	Error         string    `gorm:"type:text" json:"error,omitempty"`
+	CheckedAt     time.Time `gorm:"not null;index:idx_cluster_health_checks_connection_checked" json:"checkedAt"`
 }
 
 // JSONB 自定义类型
-type JSONB map[string]interface{}
\ No newline at end of file
+type JSONB map[string]interface{}
+
+// Value 实现 driver.Valuer 接口，以 JSON 形式写入 jsonb 列
+func (j JSONB) Value() (driver.Value, error) {
+	if j == nil {
+		return nil, nil
+	}
+	return json.Marshal(j)
+}
+
+// Scan 实现 sql.Scanner 接口，从 jsonb 列读取
+func (j *JSONB) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported JSONB value type %T", value)
+	}
+	return json.Unmarshal(data, j)
+}
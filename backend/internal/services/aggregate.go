@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"container-platform-backend/internal/model"
+)
+
+const (
+	// aggregateConcurrency 聚合查询时同时查询的 集群/命名空间 数量上限
+	aggregateConcurrency = 8
+	// aggregateClusterTimeout 单个集群/命名空间查询的超时时间
+	aggregateClusterTimeout = 15 * time.Second
+)
+
+// AllNamespaces 表示查询所有命名空间
+const AllNamespaces = "*"
+
+// ClusterTarget 聚合查询的目标集群及其命名空间
+// Namespaces 为空时使用连接配置的命名空间，包含 "*" 时查询所有命名空间。
+type ClusterTarget struct {
+	Connection *model.K8sConnection
+	Namespaces []string
+}
+
+// ClusterError 聚合查询中单个集群/命名空间的错误
+type ClusterError struct {
+	ConnectionID uint   `json:"connectionId"`
+	Cluster      string `json:"cluster"`
+	Namespace    string `json:"namespace,omitempty"`
+	Error        string `json:"error"`
+}
+
+// AggregatedContainers 跨集群聚合的容器列表，部分集群失败时仍返回其他集群的结果
+type AggregatedContainers struct {
+	Containers []ContainerInfo `json:"containers"`
+	Errors     []ClusterError  `json:"errors"`
+}
+
+// ListContainersAcrossClusters 并行查询多个集群和命名空间的容器
+func (s *K8sService) ListContainersAcrossClusters(ctx context.Context, targets []ClusterTarget) *AggregatedContainers {
+	type query struct {
+		connection *model.K8sConnection
+		namespace  string
+	}
+
+	var queries []query
+	for _, target := range targets {
+		for _, namespace := range targetNamespaces(target) {
+			queries = append(queries, query{connection: target.Connection, namespace: namespace})
+		}
+	}
+
+	result := &AggregatedContainers{
+		Containers: []ContainerInfo{},
+		Errors:     []ClusterError{},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, aggregateConcurrency)
+
+	for _, q := range queries {
+		wg.Add(1)
+		go func(q query) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			queryCtx, cancel := context.WithTimeout(ctx, aggregateClusterTimeout)
+			defer cancel()
+
+			containers, err := s.ListContainers(queryCtx, q.connection, q.namespace)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors = append(result.Errors, ClusterError{
+					ConnectionID: q.connection.ID,
+					Cluster:      q.connection.Name,
+					Namespace:    displayNamespace(q.namespace),
+					Error:        err.Error(),
+				})
+				return
+			}
+			result.Containers = append(result.Containers, containers...)
+		}(q)
+	}
+	wg.Wait()
+
+	sort.Slice(result.Containers, func(i, j int) bool {
+		a, b := result.Containers[i], result.Containers[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.PodName != b.PodName {
+			return a.PodName < b.PodName
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(result.Errors, func(i, j int) bool {
+		if result.Errors[i].Cluster != result.Errors[j].Cluster {
+			return result.Errors[i].Cluster < result.Errors[j].Cluster
+		}
+		return result.Errors[i].Namespace < result.Errors[j].Namespace
+	})
+
+	return result
+}
+
+// targetNamespaces 计算目标集群需要查询的命名空间
+func targetNamespaces(target ClusterTarget) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, namespace := range target.Namespaces {
+		if namespace == AllNamespaces {
+			// 查询所有命名空间时无需再逐个查询
+			return []string{metav1.NamespaceAll}
+		}
+		if namespace != "" && !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	if len(namespaces) == 0 {
+		namespace := target.Connection.Namespace
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
+}
+
+func displayNamespace(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return AllNamespaces
+	}
+	return namespace
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"container-platform-backend/internal/model"
+)
+
+// newMultiClusterService 按 API Server 地址为每个集群返回各自的 fake clientset
+func newMultiClusterService(t *testing.T, clusters map[string]*fake.Clientset) *K8sService {
+	t.Helper()
+
+	service := NewK8sServiceWithFactory(func(config *rest.Config) (kubernetes.Interface, error) {
+		clientSet, ok := clusters[config.Host]
+		if !ok {
+			return nil, errors.New("unknown cluster " + config.Host)
+		}
+		return clientSet, nil
+	})
+	t.Cleanup(service.pool.Close)
+	return service
+}
+
+func newClusterConnection(id uint, name string) *model.K8sConnection {
+	connection := newTestConnection()
+	connection.ID = id
+	connection.Name = name
+	connection.Endpoint = "https://" + name + ".example.com"
+	return connection
+}
+
+// containerKeys 返回 集群/命名空间/Pod 列表，用于比较聚合结果
+func containerKeys(containers []ContainerInfo) []string {
+	keys := []string{}
+	for _, container := range containers {
+		keys = append(keys, container.Cluster+"/"+container.Namespace+"/"+container.PodName)
+	}
+	return keys
+}
+
+func TestListContainersAcrossClusters(t *testing.T) {
+	dev := fake.NewSimpleClientset(newTestPod("api", "default"), newTestPod("worker", "jobs"))
+	prod := fake.NewSimpleClientset(newTestPod("web", "default"))
+	service := newMultiClusterService(t, map[string]*fake.Clientset{
+		"https://dev.example.com":  dev,
+		"https://prod.example.com": prod,
+	})
+
+	result := service.ListContainersAcrossClusters(context.Background(), []ClusterTarget{
+		{Connection: newClusterConnection(2, "prod")},
+		{Connection: newClusterConnection(1, "dev"), Namespaces: []string{AllNamespaces}},
+	})
+
+	if len(result.Errors) != 0 {
+		t.Fatalf("Errors = %+v", result.Errors)
+	}
+	want := []string{"dev/default/api", "dev/jobs/worker", "prod/default/web"}
+	if got := containerKeys(result.Containers); !reflect.DeepEqual(got, want) {
+		t.Errorf("containers = %q, want %q", got, want)
+	}
+	if result.Containers[2].ConnectionID != 2 {
+		t.Errorf("ConnectionID = %d, want 2", result.Containers[2].ConnectionID)
+	}
+}
+
+func TestListContainersAcrossClustersPartialFailure(t *testing.T) {
+	dev := fake.NewSimpleClientset(newTestPod("api", "default"))
+	prod := fake.NewSimpleClientset(newTestPod("web", "default"))
+	prod.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	service := newMultiClusterService(t, map[string]*fake.Clientset{
+		"https://dev.example.com":  dev,
+		"https://prod.example.com": prod,
+	})
+
+	result := service.ListContainersAcrossClusters(context.Background(), []ClusterTarget{
+		{Connection: newClusterConnection(1, "dev")},
+		{Connection: newClusterConnection(2, "prod")},
+		{Connection: newClusterConnection(3, "offline")},
+	})
+
+	// 失败的集群不影响其他集群的结果
+	if got := containerKeys(result.Containers); !reflect.DeepEqual(got, []string{"dev/default/api"}) {
+		t.Errorf("containers = %q", got)
+	}
+	if len(result.Errors) != 2 {
+		t.Fatalf("Errors = %+v, want offline and prod", result.Errors)
+	}
+	for i, want := range []ClusterError{
+		{ConnectionID: 3, Cluster: "offline", Namespace: "default"},
+		{ConnectionID: 2, Cluster: "prod", Namespace: "default"},
+	} {
+		got := result.Errors[i]
+		if got.ConnectionID != want.ConnectionID || got.Cluster != want.Cluster || got.Namespace != want.Namespace || got.Error == "" {
+			t.Errorf("Errors[%d] = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestListContainersAcrossClustersNamespaces(t *testing.T) {
+	clientSet := fake.NewSimpleClientset(
+		newTestPod("api", "default"),
+		newTestPod("web", "frontend"),
+		newTestPod("worker", "jobs"),
+	)
+	service := newMultiClusterService(t, map[string]*fake.Clientset{"https://dev.example.com": clientSet})
+	connection := newClusterConnection(1, "dev")
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       []string
+	}{
+		{name: "connection namespace", want: []string{"dev/default/api"}},
+		{name: "selected namespaces", namespaces: []string{"jobs", "frontend", "jobs", ""}, want: []string{"dev/frontend/web", "dev/jobs/worker"}},
+		{name: "all namespaces", namespaces: []string{"jobs", AllNamespaces}, want: []string{"dev/default/api", "dev/frontend/web", "dev/jobs/worker"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.ListContainersAcrossClusters(context.Background(), []ClusterTarget{{Connection: connection, Namespaces: tt.namespaces}})
+			if len(result.Errors) != 0 {
+				t.Fatalf("Errors = %+v", result.Errors)
+			}
+			if got := containerKeys(result.Containers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("containers = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetNamespaces(t *testing.T) {
+	connection := newTestConnection()
+	connection.Namespace = ""
+
+	tests := []struct {
+		namespaces []string
+		want       []string
+	}{
+		{nil, []string{"default"}},
+		{[]string{"", ""}, []string{"default"}},
+		{[]string{"b", "a", "b"}, []string{"b", "a"}},
+		{[]string{"a", AllNamespaces, "b"}, []string{""}},
+	}
+
+	for _, tt := range tests {
+		if got := targetNamespaces(ClusterTarget{Connection: connection, Namespaces: tt.namespaces}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("targetNamespaces(%q) = %q, want %q", tt.namespaces, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"container-platform-backend/internal/model"
+)
+
+const (
+	// defaultBatchConcurrency 批量操作默认同时处理的容器数
+	defaultBatchConcurrency = 5
+	// maxBatchConcurrency 批量操作允许的最大并发数
+	maxBatchConcurrency = 20
+	// maxBatchTargets 单次批量操作最多处理的容器数
+	maxBatchTargets = 500
+	// batchItemTimeout 单个容器操作的超时时间
+	batchItemTimeout = time.Minute
+)
+
+// 批量操作支持的动作
+const (
+	BatchActionStart   = "start"
+	BatchActionStop    = "stop"
+	BatchActionRestart = "restart"
+	BatchActionPause   = "pause"
+	BatchActionResume  = "resume"
+	BatchActionDelete  = "delete"
+	// BatchActionDestroy 前端使用的 delete 别名
+	BatchActionDestroy = "destroy"
+)
+
+// BatchTarget 批量操作的目标容器，name 可以是 Pod 或工作负载名称
+type BatchTarget struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// BatchRequest 批量操作请求，targets/ids 与 labelSelector 二选一
+type BatchRequest struct {
+	Action  string        `json:"action"`
+	Targets []BatchTarget `json:"targets,omitempty"`
+	// 前端使用的目标格式：namespace 下的 Pod 或工作负载名称
+	IDs []string `json:"ids,omitempty"`
+	// targets 未指定命名空间和使用 labelSelector 时的命名空间
+	Namespace     string `json:"namespace,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// 只校验目标是否存在以及是否支持该操作，不实际执行
+	DryRun bool `json:"dryRun,omitempty"`
+	// 并发数，默认 5，最大 20
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+// BatchItemResult 单个容器的操作结果
+type BatchItemResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// 解析出的工作负载类型和名称，目标不存在时为空
+	Kind         string            `json:"kind,omitempty"`
+	WorkloadName string            `json:"workloadName,omitempty"`
+	Success      bool              `json:"success"`
+	Error        string            `json:"error,omitempty"`
+	Deleted      *DeletedContainer `json:"deleted,omitempty"`
+	// 原始错误，用于审计和状态码判断
+	Err error `json:"-"`
+}
+
+// BatchResult 批量操作结果，所有容器的操作共用一个父操作ID
+type BatchResult struct {
+	OperationID string            `json:"operationId"`
+	Action      string            `json:"action"`
+	DryRun      bool              `json:"dryRun"`
+	Total       int               `json:"total"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Items       []BatchItemResult `json:"items"`
+}
+
+// validate 校验批量操作请求并归一化动作名称
+func (r *BatchRequest) validate() error {
+	var errs ValidationErrors
+
+	if r.Action == BatchActionDestroy {
+		r.Action = BatchActionDelete
+	}
+	switch r.Action {
+	case BatchActionStart, BatchActionStop, BatchActionRestart, BatchActionPause, BatchActionResume, BatchActionDelete:
+	default:
+		errs.add("action", "unsupported action %q, must be start, stop, restart, pause, resume or delete", r.Action)
+	}
+
+	hasTargets := len(r.Targets) > 0 || len(r.IDs) > 0
+	switch {
+	case hasTargets && r.LabelSelector != "":
+		errs.add("labelSelector", "cannot be combined with targets")
+	case !hasTargets && r.LabelSelector == "":
+		errs.add("targets", "targets or labelSelector is required")
+	case len(r.Targets)+len(r.IDs) > maxBatchTargets:
+		errs.add("targets", "at most %d targets are allowed", maxBatchTargets)
+	}
+	for i, target := range r.Targets {
+		if target.Name == "" {
+			errs.add(fmt.Sprintf("targets[%d].name", i), "is required")
+		}
+	}
+	if r.LabelSelector != "" {
+		if _, err := labels.Parse(r.LabelSelector); err != nil {
+			errs.add("labelSelector", "%s", err.Error())
+		}
+	}
+	if r.Concurrency < 0 || r.Concurrency > maxBatchConcurrency {
+		errs.add("concurrency", "must be between 1 and %d", maxBatchConcurrency)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// BatchContainers 对多个容器并发执行同一操作，单个容器失败不影响其他容器
+// dryRun 时只解析目标并检查是否支持该操作。
+func (s *K8sService) BatchContainers(ctx context.Context, connection *model.K8sConnection, req *BatchRequest) (*BatchResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := req.targets()
+	if req.LabelSelector != "" {
+		if targets, err = selectBatchTargets(ctx, clientSet, req.Namespace, req.LabelSelector); err != nil {
+			return nil, err
+		}
+		if len(targets) > maxBatchTargets {
+			return nil, ValidationErrors{{Field: "labelSelector", Message: fmt.Sprintf("matches %d containers, at most %d are allowed", len(targets), maxBatchTargets)}}
+		}
+	}
+
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	result := &BatchResult{
+		OperationID: NewOperationID(),
+		Action:      req.Action,
+		DryRun:      req.DryRun,
+		Total:       len(targets),
+		Items:       make([]BatchItemResult, len(targets)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target BatchTarget) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			itemCtx, cancel := context.WithTimeout(ctx, batchItemTimeout)
+			defer cancel()
+
+			item := BatchItemResult{Namespace: target.Namespace, Name: target.Name}
+			if req.DryRun {
+				item.Kind, item.Err = checkBatchAction(itemCtx, clientSet, target, req.Action)
+			} else {
+				var workload ContainerWorkload
+				workload, item.Deleted, item.Err = s.runBatchAction(itemCtx, clientSet, connection, target, req.Action)
+				item.Kind, item.WorkloadName = workload.Kind, workload.Name
+			}
+			item.Success = item.Err == nil
+			if item.Err != nil {
+				item.Error = item.Err.Error()
+			}
+			result.Items[i] = item
+		}(i, target)
+	}
+	wg.Wait()
+
+	for _, item := range result.Items {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// targets 合并 targets 和 ids，未指定命名空间的目标使用请求的命名空间，重复的目标只保留一个
+func (r *BatchRequest) targets() []BatchTarget {
+	all := append([]BatchTarget(nil), r.Targets...)
+	for _, id := range r.IDs {
+		all = append(all, BatchTarget{Name: id})
+	}
+
+	seen := make(map[BatchTarget]bool, len(all))
+	targets := make([]BatchTarget, 0, len(all))
+	for _, target := range all {
+		if target.Namespace == "" {
+			target.Namespace = r.Namespace
+		}
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// selectBatchTargets 按标签选择命名空间内的工作负载：标签匹配的 Deployment、Job、CronJob，
+// 以及标签匹配的 Pod 所属的工作负载（独立 Pod 为其自身），结果按名称排序
+func selectBatchTargets(ctx context.Context, clientSet kubernetes.Interface, namespace, labelSelector string) ([]BatchTarget, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContainerRequest, err)
+	}
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// 目标按名称解析，同名的工作负载只保留一个
+	found := make(map[string]bool)
+	workloads := listWorkloads(ctx, clientSet, namespace)
+	for _, deployment := range workloads.deployments {
+		if selector.Matches(labels.Set(deployment.Labels)) {
+			found[deployment.Name] = true
+		}
+	}
+	for _, cronJob := range workloads.cronJobs {
+		if selector.Matches(labels.Set(cronJob.Labels)) {
+			found[cronJob.Name] = true
+		}
+	}
+	for _, job := range workloads.jobs {
+		if !selector.Matches(labels.Set(job.Labels)) {
+			continue
+		}
+		// CronJob 创建的 Job 以 CronJob 作为操作对象
+		name := job.Name
+		if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+			if _, ok := workloads.cronJobsByName[job.Namespace+"/"+owner.Name]; ok {
+				name = owner.Name
+			}
+		}
+		found[name] = true
+	}
+	for i := range pods.Items {
+		found[workloads.ownerOf(&pods.Items[i]).name()] = true
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targets := make([]BatchTarget, 0, len(names))
+	for _, name := range names {
+		targets = append(targets, BatchTarget{Namespace: namespace, Name: name})
+	}
+	return targets, nil
+}
+
+// runBatchAction 执行单个容器的操作，返回解析出的工作负载
+func (s *K8sService) runBatchAction(ctx context.Context, clientSet kubernetes.Interface, connection *model.K8sConnection, target BatchTarget, action string) (ContainerWorkload, *DeletedContainer, error) {
+	resolved, err := resolveWorkload(ctx, clientSet, target.Namespace, target.Name)
+	if err != nil {
+		return ContainerWorkload{}, nil, err
+	}
+	workload := ContainerWorkload{Kind: resolved.kind(), Name: resolved.name()}
+
+	switch action {
+	case BatchActionStart:
+		err = s.StartContainer(ctx, connection, target.Namespace, target.Name)
+	case BatchActionStop:
+		err = s.StopContainer(ctx, connection, target.Namespace, target.Name)
+	case BatchActionRestart:
+		err = s.RestartContainer(ctx, connection, target.Namespace, target.Name)
+	case BatchActionPause:
+		_, err = s.PauseContainer(ctx, connection, target.Namespace, target.Name)
+	case BatchActionResume:
+		_, err = s.ResumeContainer(ctx, connection, target.Namespace, target.Name)
+	case BatchActionDelete:
+		deleted, err := s.DeleteContainer(ctx, connection, target.Namespace, target.Name)
+		return workload, deleted, err
+	}
+	return workload, nil, err
+}
+
+// checkBatchAction 解析目标并检查工作负载是否支持该操作，规则与单个容器的操作一致
+func checkBatchAction(ctx context.Context, clientSet kubernetes.Interface, target BatchTarget, action string) (string, error) {
+	workload, err := resolveWorkload(ctx, clientSet, target.Namespace, target.Name)
+	if err != nil {
+		return "", err
+	}
+	kind := workload.kind()
+
+	switch action {
+	case BatchActionStart, BatchActionStop, BatchActionRestart:
+		if workload.deployment == nil {
+			return kind, fmt.Errorf("%w: %s %s cannot be %s", ErrUnsupportedWorkload, kind, workload.name(), pastTense(action))
+		}
+	case BatchActionPause:
+		if kind == WorkloadPod {
+			return kind, fmt.Errorf("%w: pod %s is not managed by a deployment, job or cronjob", ErrUnsupportedWorkload, workload.name())
+		}
+	case BatchActionResume:
+		paused := false
+		switch kind {
+		case WorkloadDeployment:
+			paused = deploymentPaused(workload.deployment)
+		case WorkloadJob:
+			paused = isSuspended(workload.job.Spec.Suspend)
+		case WorkloadCronJob:
+			paused = isSuspended(workload.cronJob.Spec.Suspend)
+		default:
+			return kind, fmt.Errorf("%w: pod %s is not managed by a deployment, job or cronjob", ErrUnsupportedWorkload, workload.name())
+		}
+		if !paused {
+			return kind, fmt.Errorf("%w: %s %s", ErrContainerNotPaused, kind, workload.name())
+		}
+	}
+	return kind, nil
+}
+
+// pastTense 动作的过去分词，用于错误信息
+func pastTense(action string) string {
+	switch action {
+	case BatchActionStop:
+		return "stopped"
+	default:
+		return action + "ed"
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBatchContainers(t *testing.T) {
+	web, webRS, webPod := newTestDeployment("web", "default", 2)
+	api, apiRS, apiPod := newTestDeployment("api", "default", 1)
+	service, clientSet := newFakeService(t, web, webRS, webPod, api, apiRS, apiPod, newTestPod("standalone", "default"))
+
+	req := &BatchRequest{Action: BatchActionStop, Namespace: "default", IDs: []string{webPod.Name, "api", "standalone", "missing"}}
+	result, err := service.BatchContainers(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("BatchContainers() error = %v", err)
+	}
+
+	if result.OperationID == "" || result.Total != 4 || result.Succeeded != 2 || result.Failed != 2 {
+		t.Fatalf("BatchContainers() = %+v", result)
+	}
+	// 结果与请求中的目标顺序一致
+	wantKinds := []string{WorkloadDeployment, WorkloadDeployment, WorkloadPod, ""}
+	for i, item := range result.Items {
+		if item.Kind != wantKinds[i] || item.Success != (i < 2) {
+			t.Errorf("item %d = %+v", i, item)
+		}
+	}
+	// Pod 名称解析为所属的工作负载
+	if result.Items[0].WorkloadName != "web" {
+		t.Errorf("item 0 workload = %q, want web", result.Items[0].WorkloadName)
+	}
+	if !errors.Is(result.Items[2].Err, ErrUnsupportedWorkload) || result.Items[3].Error == "" {
+		t.Errorf("failed items = %+v, %+v", result.Items[2], result.Items[3])
+	}
+
+	for _, name := range []string{"web", "api"} {
+		if replicas := *getDeployment(t, clientSet, "default", name).Spec.Replicas; replicas != 0 {
+			t.Errorf("deployment %s replicas = %d, want 0", name, replicas)
+		}
+	}
+}
+
+func TestBatchContainersDryRun(t *testing.T) {
+	web, webRS, webPod := newTestDeployment("web", "default", 2)
+	service, clientSet := newFakeService(t, web, webRS, webPod, newTestPod("standalone", "default"))
+
+	req := &BatchRequest{Action: BatchActionResume, DryRun: true, Targets: []BatchTarget{{Namespace: "default", Name: "web"}, {Namespace: "default", Name: "standalone"}}}
+	result, err := service.BatchContainers(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("BatchContainers() error = %v", err)
+	}
+	if !result.DryRun || result.Failed != 2 {
+		t.Fatalf("BatchContainers() = %+v", result)
+	}
+	if !errors.Is(result.Items[0].Err, ErrContainerNotPaused) || !errors.Is(result.Items[1].Err, ErrUnsupportedWorkload) {
+		t.Errorf("dry run items = %+v", result.Items)
+	}
+
+	req = &BatchRequest{Action: BatchActionDestroy, DryRun: true, Targets: []BatchTarget{{Namespace: "default", Name: "web"}}}
+	result, err = service.BatchContainers(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("BatchContainers() error = %v", err)
+	}
+	if result.Action != BatchActionDelete || result.Succeeded != 1 || result.Items[0].Deleted != nil {
+		t.Errorf("BatchContainers() = %+v", result)
+	}
+	// 试运行不修改工作负载
+	getDeployment(t, clientSet, "default", "web")
+}
+
+func TestBatchContainersLabelSelector(t *testing.T) {
+	web, webRS, webPod := newTestDeployment("web", "default", 1)
+	api, apiRS, apiPod := newTestDeployment("api", "default", 1)
+	other, otherRS, otherPod := newTestDeployment("web", "other", 1)
+	standalone := newTestPod("standalone", "default")
+	standalone.Labels["tier"] = "frontend"
+	webPod.Labels = map[string]string{"app": "web", "managed": "container-platform", "tier": "frontend"}
+	service, _ := newFakeService(t, web, webRS, webPod, api, apiRS, apiPod, other, otherRS, otherPod, standalone)
+
+	req := &BatchRequest{Action: BatchActionRestart, Namespace: "default", LabelSelector: "tier=frontend", DryRun: true}
+	result, err := service.BatchContainers(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("BatchContainers() error = %v", err)
+	}
+
+	if len(result.Items) != 2 || result.Items[0].Name != "standalone" || result.Items[1].Name != "web" {
+		t.Fatalf("selected items = %+v, want standalone and web", result.Items)
+	}
+	if result.Items[0].Success || !result.Items[1].Success || result.Items[1].Kind != WorkloadDeployment {
+		t.Errorf("selected items = %+v", result.Items)
+	}
+}
+
+func TestBatchRequestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   BatchRequest
+		field string
+	}{
+		{"unknown action", BatchRequest{Action: "kill", IDs: []string{"web"}}, "action"},
+		{"no targets", BatchRequest{Action: BatchActionStart}, "targets"},
+		{"targets and selector", BatchRequest{Action: BatchActionStart, IDs: []string{"web"}, LabelSelector: "app=web"}, "labelSelector"},
+		{"invalid selector", BatchRequest{Action: BatchActionStart, LabelSelector: "app in (web"}, "labelSelector"},
+		{"missing name", BatchRequest{Action: BatchActionStart, Targets: []BatchTarget{{Namespace: "default"}}}, "targets[0].name"},
+		{"concurrency", BatchRequest{Action: BatchActionStart, IDs: []string{"web"}, Concurrency: 100}, "concurrency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.validate()
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.field {
+				t.Errorf("validate() error = %v, want field error on %s", err, tt.field)
+			}
+		})
+	}
+}
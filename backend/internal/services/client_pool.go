@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/k8s"
+	"container-platform-backend/internal/model"
+)
+
+const (
+	// defaultClientIdleTimeout 客户端空闲多久后被回收
+	defaultClientIdleTimeout = 30 * time.Minute
+	// clientEvictionInterval 空闲回收的检查间隔
+	clientEvictionInterval = time.Minute
+	// defaultClusterRequestTimeout 单个集群请求的默认超时时间
+	defaultClusterRequestTimeout = 30 * time.Second
+)
+
+// clusterClient 连接池中缓存的集群客户端
+type clusterClient struct {
+	clientSet   kubernetes.Interface
+	config      *rest.Config
+	fingerprint string
+	lastUsed    time.Time
+	// 按模拟身份缓存的派生客户端（platform_user 模式）
+	impersonated map[string]kubernetes.Interface
+}
+
+// ClientPool 按连接ID缓存已就绪的 Kubernetes 客户端
+//
+// 客户端在首次使用时创建并完成一次握手，之后的请求直接复用；
+// 连接配置变更（UpdatedAt 变化）或被显式失效时重新创建，空闲超时的客户端会被回收。
+// 模拟平台用户的连接在基础客户端之上按用户派生客户端，无需再次握手。
+type ClientPool struct {
+	mu          sync.Mutex
+	clients     map[uint]*clusterClient
+	idleTimeout time.Duration
+	factory     k8s.ClientsetFactory
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+}
+
+// NewClientPool 创建客户端连接池并启动空闲回收
+// factory 为空时使用 k8s.DefaultClientsetFactory。
+func NewClientPool(idleTimeout time.Duration, factory k8s.ClientsetFactory) *ClientPool {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultClientIdleTimeout
+	}
+	if factory == nil {
+		factory = k8s.DefaultClientsetFactory
+	}
+
+	pool := &ClientPool{
+		clients:     make(map[uint]*clusterClient),
+		idleTimeout: idleTimeout,
+		factory:     factory,
+		stopCh:      make(chan struct{}),
+	}
+	go pool.evictLoop()
+	return pool
+}
+
+// Get 获取连接对应的客户端，不存在或已过期时创建
+// user 仅在连接使用 platform_user 模拟模式时需要。
+func (p *ClientPool) Get(ctx context.Context, connection *model.K8sConnection, user *PlatformUser) (kubernetes.Interface, *rest.Config, error) {
+	if connection.ImpersonationMode == ImpersonationPlatformUser && user == nil {
+		return nil, nil, ErrPlatformUserRequired
+	}
+
+	cached, err := p.base(ctx, connection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if connection.ImpersonationMode != ImpersonationPlatformUser {
+		return cached.clientSet, cached.config, nil
+	}
+	return p.impersonate(connection, cached, user)
+}
+
+// base 获取连接的基础客户端，新建客户端的握手在 ctx 取消时中止
+func (p *ClientPool) base(ctx context.Context, connection *model.K8sConnection) (*clusterClient, error) {
+	fingerprint := connectionFingerprint(connection)
+
+	p.mu.Lock()
+	if cached, ok := p.clients[connection.ID]; ok && cached.fingerprint == fingerprint {
+		cached.lastUsed = time.Now()
+		p.mu.Unlock()
+		return cached, nil
+	}
+	p.mu.Unlock()
+
+	// 在锁外创建客户端，避免一个慢集群阻塞其他连接
+	config, err := buildRestConfig(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	clientSet, err := p.factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	if _, err := serverVersion(ctx, clientSet); err != nil {
+		return nil, fmt.Errorf("failed to connect to cluster: %w", classifyCertificateError(err))
+	}
+
+	created := &clusterClient{
+		clientSet:    clientSet,
+		config:       config,
+		fingerprint:  fingerprint,
+		lastUsed:     time.Now(),
+		impersonated: make(map[string]kubernetes.Interface),
+	}
+
+	// 未持久化的连接（如连接测试）不缓存
+	if connection.ID == 0 {
+		return created, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 并发创建时以先写入者为准
+	if cached, ok := p.clients[connection.ID]; ok && cached.fingerprint == fingerprint {
+		cached.lastUsed = time.Now()
+		return cached, nil
+	}
+
+	p.clients[connection.ID] = created
+	log.Printf("Successfully connected to Kubernetes cluster: %s", connection.Name)
+
+	return created, nil
+}
+
+// impersonate 基于基础客户端派生模拟平台用户的客户端
+func (p *ClientPool) impersonate(connection *model.K8sConnection, cached *clusterClient, user *PlatformUser) (kubernetes.Interface, *rest.Config, error) {
+	impersonation := platformUserImpersonation(connection, user)
+	key := impersonation.UserName + "|" + strings.Join(impersonation.Groups, ",")
+
+	config := rest.CopyConfig(cached.config)
+	config.Impersonate = impersonation
+
+	p.mu.Lock()
+	if clientSet, ok := cached.impersonated[key]; ok {
+		p.mu.Unlock()
+		return clientSet, config, nil
+	}
+	p.mu.Unlock()
+
+	clientSet, err := p.factory(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create impersonated kubernetes client: %w", err)
+	}
+
+	p.mu.Lock()
+	cached.impersonated[key] = clientSet
+	p.mu.Unlock()
+
+	return clientSet, config, nil
+}
+
+// Invalidate 使指定连接的缓存客户端失效
+func (p *ClientPool) Invalidate(connectionID uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.clients, connectionID)
+}
+
+// Close 停止空闲回收并清空连接池
+func (p *ClientPool) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clients = make(map[uint]*clusterClient)
+}
+
+// evictLoop 定期回收空闲的客户端
+func (p *ClientPool) evictLoop() {
+	ticker := time.NewTicker(clientEvictionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case now := <-ticker.C:
+			p.evictIdle(now)
+		}
+	}
+}
+
+// evictIdle 回收在 now 之前已空闲超时的客户端
+func (p *ClientPool) evictIdle(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, cached := range p.clients {
+		if now.Sub(cached.lastUsed) > p.idleTimeout {
+			delete(p.clients, id)
+			log.Printf("Evicted idle Kubernetes client for connection %d", id)
+		}
+	}
+}
+
+// connectionFingerprint 连接配置的版本标识，配置被修改后 UpdatedAt 随之变化
+func connectionFingerprint(connection *model.K8sConnection) string {
+	return fmt.Sprintf("%d-%d", connection.ID, connection.UpdatedAt.UnixNano())
+}
+
+// serverVersion 获取集群版本，请求在 ctx 取消时中止
+// Discovery().ServerVersion() 不接受 ctx，集群不可达时会一直等待到请求超时
+func serverVersion(ctx context.Context, clientSet kubernetes.Interface) (*version.Info, error) {
+	restClient := clientSet.Discovery().RESTClient()
+	if restClient == nil {
+		// fake clientset 没有 RESTClient
+		return clientSet.Discovery().ServerVersion()
+	}
+
+	body, err := restClient.Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var info version.Info
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("unable to parse the server version: %w", err)
+	}
+	return &info, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/model"
+)
+
+// 证书校验失败的原因
+const (
+	CertificateUnknownAuthority = "unknown_authority"
+	CertificateHostnameMismatch = "hostname_mismatch"
+	CertificateExpired          = "expired"
+	CertificateNotYetValid      = "not_yet_valid"
+	CertificateInvalid          = "invalid"
+)
+
+// CertificateError 连接集群时的 TLS 证书校验错误
+type CertificateError struct {
+	Reason  string
+	Message string
+	Err     error
+}
+
+func (e *CertificateError) Error() string {
+	return fmt.Sprintf("TLS certificate verification failed (%s): %s", e.Reason, e.Message)
+}
+
+func (e *CertificateError) Unwrap() error {
+	return e.Err
+}
+
+// tokenTLSConfig 构建 token 连接的 TLS 配置
+func tokenTLSConfig(connection *model.K8sConnection) rest.TLSClientConfig {
+	return rest.TLSClientConfig{
+		Insecure:   connection.InsecureSkipTLSVerify,
+		ServerName: connection.TLSServerName,
+		CAData:     []byte(connection.CAData),
+		CertData:   []byte(connection.ClientCertData),
+		KeyData:    []byte(connection.ClientKeyData),
+	}
+}
+
+// validateTLSConfig 校验连接的 CA 证书和客户端证书
+func validateTLSConfig(connection *model.K8sConnection) error {
+	if connection.InsecureSkipTLSVerify && connection.CAData != "" {
+		return fmt.Errorf("%w: caData cannot be combined with insecureSkipTLSVerify", ErrInvalidConnection)
+	}
+
+	if connection.CAData != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(connection.CAData)) {
+			return fmt.Errorf("%w: caData does not contain a valid PEM certificate", ErrInvalidConnection)
+		}
+	}
+
+	if (connection.ClientCertData == "") != (connection.ClientKeyData == "") {
+		return fmt.Errorf("%w: clientCertData and clientKeyData must be provided together", ErrInvalidConnection)
+	}
+	if connection.ClientCertData != "" {
+		if _, err := tls.X509KeyPair([]byte(connection.ClientCertData), []byte(connection.ClientKeyData)); err != nil {
+			return fmt.Errorf("%w: invalid client certificate/key pair: %v", ErrInvalidConnection, err)
+		}
+	}
+
+	return nil
+}
+
+// classifyCertificateError 将 TLS 握手中的证书错误转换为 CertificateError，其他错误原样返回
+func classifyCertificateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthority) {
+		return &CertificateError{
+			Reason:  CertificateUnknownAuthority,
+			Message: "server certificate is signed by an unknown authority; provide the cluster CA in caData",
+			Err:     err,
+		}
+	}
+
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return &CertificateError{
+			Reason:  CertificateHostnameMismatch,
+			Message: fmt.Sprintf("server certificate is not valid for %q; check the endpoint or set tlsServerName", hostnameErr.Host),
+			Err:     err,
+		}
+	}
+
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		switch invalidErr.Reason {
+		case x509.Expired:
+			// x509.Expired 同时表示已过期和尚未生效
+			if invalidErr.Cert != nil && time.Now().Before(invalidErr.Cert.NotBefore) {
+				return &CertificateError{Reason: CertificateNotYetValid, Message: "server certificate is not yet valid: " + invalidErr.Detail, Err: err}
+			}
+			return &CertificateError{Reason: CertificateExpired, Message: "server certificate has expired: " + invalidErr.Detail, Err: err}
+		default:
+			return &CertificateError{Reason: CertificateInvalid, Message: invalidErr.Error(), Err: err}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newTestCertificate 生成自签名证书，返回证书、PEM 编码的证书和私钥
+func newTestCertificate(t *testing.T, notBefore, notAfter time.Time) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		DNSNames:              []string{"kubernetes.example.com"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return cert, string(certPEM), string(keyPEM)
+}
+
+func TestValidateTLSConfig(t *testing.T) {
+	now := time.Now()
+	_, certPEM, keyPEM := newTestCertificate(t, now.Add(-time.Hour), now.Add(time.Hour))
+	_, otherCertPEM, _ := newTestCertificate(t, now.Add(-time.Hour), now.Add(time.Hour))
+
+	tests := []struct {
+		name     string
+		insecure bool
+		caData   string
+		certData string
+		keyData  string
+		wantErr  bool
+	}{
+		{name: "no TLS settings"},
+		{name: "insecure", insecure: true},
+		{name: "CA", caData: certPEM},
+		{name: "client certificate", caData: certPEM, certData: certPEM, keyData: keyPEM},
+		{name: "CA with insecure", insecure: true, caData: certPEM, wantErr: true},
+		{name: "invalid CA", caData: "not a certificate", wantErr: true},
+		{name: "certificate without key", certData: certPEM, wantErr: true},
+		{name: "key without certificate", keyData: keyPEM, wantErr: true},
+		{name: "mismatched key", certData: otherCertPEM, keyData: keyPEM, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connection := newTestConnection()
+			connection.InsecureSkipTLSVerify = tt.insecure
+			connection.CAData = tt.caData
+			connection.ClientCertData = tt.certData
+			connection.ClientKeyData = tt.keyData
+
+			err := validateTLSConfig(connection)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidConnection) {
+					t.Errorf("validateTLSConfig() error = %v, want ErrInvalidConnection", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("validateTLSConfig() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClassifyCertificateError(t *testing.T) {
+	now := time.Now()
+	expired, _, _ := newTestCertificate(t, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	future, _, _ := newTestCertificate(t, now.Add(time.Hour), now.Add(2*time.Hour))
+
+	// 证书错误通常被包装在 url.Error 中返回
+	wrap := func(err error) error {
+		return fmt.Errorf("Get \"https://kubernetes.example.com/version\": %w", &url.Error{Op: "Get", URL: "https://kubernetes.example.com/version", Err: err})
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+	}{
+		{name: "unknown authority", err: wrap(x509.UnknownAuthorityError{}), wantReason: CertificateUnknownAuthority},
+		{name: "hostname mismatch", err: wrap(x509.HostnameError{Certificate: expired, Host: "10.0.0.1"}), wantReason: CertificateHostnameMismatch},
+		{name: "expired", err: wrap(x509.CertificateInvalidError{Cert: expired, Reason: x509.Expired}), wantReason: CertificateExpired},
+		{name: "not yet valid", err: wrap(x509.CertificateInvalidError{Cert: future, Reason: x509.Expired}), wantReason: CertificateNotYetValid},
+		{name: "other invalid", err: wrap(x509.CertificateInvalidError{Cert: expired, Reason: x509.NotAuthorizedToSign}), wantReason: CertificateInvalid},
+		{name: "not a certificate error", err: wrap(errors.New("connection refused"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyCertificateError(tt.err)
+
+			var certErr *CertificateError
+			if tt.wantReason == "" {
+				if errors.As(err, &certErr) || err != tt.err {
+					t.Errorf("classifyCertificateError() = %v, want the original error", err)
+				}
+				return
+			}
+			if !errors.As(err, &certErr) || certErr.Reason != tt.wantReason {
+				t.Fatalf("classifyCertificateError() = %v, want reason %s", err, tt.wantReason)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("CertificateError should wrap the original error")
+			}
+		})
+	}
+
+	if err := classifyCertificateError(nil); err != nil {
+		t.Errorf("classifyCertificateError(nil) = %v", err)
+	}
+}
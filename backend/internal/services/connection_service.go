@@ -0,0 +1,330 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"container-platform-backend/internal/model"
+)
+
+var (
+	// ErrConnectionNotFound 连接不存在
+	ErrConnectionNotFound = errors.New("kubernetes connection not found")
+	// ErrNoActiveConnection 没有激活的连接
+	ErrNoActiveConnection = errors.New("no active kubernetes connection configured")
+	// ErrInvalidConnection 连接配置无效
+	ErrInvalidConnection = errors.New("invalid kubernetes connection")
+)
+
+// 连接的配置类型
+const (
+	// ConfigTypeKubeconfig 使用 kubeconfig 内容连接
+	ConfigTypeKubeconfig = "kubeconfig"
+	// ConfigTypeToken 使用 API Server 地址和 token/客户端证书连接
+	ConfigTypeToken = "token"
+	// ConfigTypeLocal 内置连接，使用服务端所在环境的集群内配置或 KUBECONFIG_PATH
+	ConfigTypeLocal = "local"
+)
+
+// ConnectionService Kubernetes 连接注册服务
+type ConnectionService struct {
+	db *gorm.DB
+}
+
+// ConnectionRequest 创建/更新连接请求
+type ConnectionRequest struct {
+	Name       string `json:"name"`
+	Endpoint   string `json:"endpoint"`
+	ConfigType string `json:"configType"`
+	Config     string `json:"config"`
+	Token      string `json:"token"`
+	Namespace  string `json:"namespace"`
+	// 未提供时保持原值，切换激活连接使用 ActivateConnection
+	IsActive *bool `json:"isActive"`
+
+	// kubeconfig 连接可选的上下文/集群/用户
+	ContextName  string `json:"contextName"`
+	ClusterName  string `json:"clusterName"`
+	AuthInfoName string `json:"authInfoName"`
+
+	// token 连接的 TLS 配置，clientKeyData 为空时保留原值
+	CAData                string `json:"caData"`
+	ClientCertData        string `json:"clientCertData"`
+	ClientKeyData         string `json:"clientKeyData"`
+	TLSServerName         string `json:"tlsServerName"`
+	InsecureSkipTLSVerify bool   `json:"insecureSkipTLSVerify"`
+
+	// 身份模拟设置：none / fixed / platform_user
+	ImpersonationMode string `json:"impersonationMode"`
+	ImpersonateUser   string `json:"impersonateUser"`
+	ImpersonateGroups string `json:"impersonateGroups"`
+}
+
+// NewConnectionService 创建连接服务
+func NewConnectionService(db *gorm.DB) *ConnectionService {
+	return &ConnectionService{db: db}
+}
+
+// ListConnections 获取所有连接
+func (s *ConnectionService) ListConnections() ([]model.K8sConnection, error) {
+	var connections []model.K8sConnection
+	if err := s.db.Order("id").Find(&connections).Error; err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	return connections, nil
+}
+
+// GetConnection 根据ID获取连接
+func (s *ConnectionService) GetConnection(id uint) (*model.K8sConnection, error) {
+	var connection model.K8sConnection
+	if err := s.db.First(&connection, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrConnectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	return &connection, nil
+}
+
+// GetConnections 根据ID列表获取连接，任一不存在时返回 ErrConnectionNotFound
+func (s *ConnectionService) GetConnections(ids []uint) ([]model.K8sConnection, error) {
+	var connections []model.K8sConnection
+	if err := s.db.Where("id IN ?", ids).Order("id").Find(&connections).Error; err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	found := make(map[uint]bool, len(connections))
+	for _, connection := range connections {
+		found[connection.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: id %d", ErrConnectionNotFound, id)
+		}
+	}
+
+	return connections, nil
+}
+
+// GetActiveConnection 获取当前激活的连接
+func (s *ConnectionService) GetActiveConnection() (*model.K8sConnection, error) {
+	var connection model.K8sConnection
+	if err := s.db.Where("is_active = ?", true).Order("id").First(&connection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoActiveConnection
+		}
+		return nil, fmt.Errorf("failed to get active connection: %w", err)
+	}
+	return &connection, nil
+}
+
+// ResolveConnection 根据连接ID解析连接，未指定时回退到激活的连接
+func (s *ConnectionService) ResolveConnection(connectionID string) (*model.K8sConnection, error) {
+	if connectionID == "" {
+		return s.GetActiveConnection()
+	}
+
+	id, err := strconv.ParseUint(connectionID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid connection id %q", ErrInvalidConnection, connectionID)
+	}
+	return s.GetConnection(uint(id))
+}
+
+// CreateConnection 创建连接
+func (s *ConnectionService) CreateConnection(req *ConnectionRequest) (*model.K8sConnection, error) {
+	connection := &model.K8sConnection{}
+	applyConnectionRequest(connection, req)
+	if err := validateConnection(connection); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if connection.IsActive {
+			if err := deactivateConnections(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Create(connection).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection: %w", err)
+	}
+
+	return connection, nil
+}
+
+// UpdateConnection 更新连接
+// 凭据字段 (config/token) 为空、isActive 未提供时保留原值，避免列表接口脱敏后回写覆盖。
+func (s *ConnectionService) UpdateConnection(id uint, req *ConnectionRequest) (*model.K8sConnection, error) {
+	connection, err := s.GetConnection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	original := *connection
+	applyConnectionRequest(connection, req)
+	if connection.BuiltIn {
+		// 内置连接的凭据来源由服务端配置决定
+		restoreBuiltInCredentials(connection, &original)
+	}
+	if err := validateConnection(connection); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if connection.IsActive && !original.IsActive {
+			if err := deactivateConnections(tx); err != nil {
+				return err
+			}
+		}
+		return tx.Save(connection).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update connection: %w", err)
+	}
+
+	return connection, nil
+}
+
+// DeleteConnection 删除连接
+func (s *ConnectionService) DeleteConnection(id uint) error {
+	connection, err := s.GetConnection(id)
+	if err != nil {
+		return err
+	}
+	if connection.BuiltIn {
+		return ErrBuiltInConnection
+	}
+
+	if err := s.db.Delete(connection).Error; err != nil {
+		return fmt.Errorf("failed to delete connection: %w", err)
+	}
+	return nil
+}
+
+// ActivateConnection 将指定连接设为激活连接
+func (s *ConnectionService) ActivateConnection(id uint) (*model.K8sConnection, error) {
+	connection, err := s.GetConnection(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deactivateConnections(tx); err != nil {
+			return err
+		}
+		return tx.Model(connection).Update("is_active", true).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate connection: %w", err)
+	}
+
+	connection.IsActive = true
+	return connection, nil
+}
+
+// SanitizeConnection 返回去除凭据的连接副本，用于 API 响应
+func SanitizeConnection(connection model.K8sConnection) model.K8sConnection {
+	connection.Config = ""
+	connection.Token = ""
+	connection.ClientKeyData = ""
+	return connection
+}
+
+func applyConnectionRequest(connection *model.K8sConnection, req *ConnectionRequest) {
+	connection.Name = req.Name
+	connection.Endpoint = req.Endpoint
+	connection.ConfigType = req.ConfigType
+	connection.Namespace = req.Namespace
+	if req.IsActive != nil {
+		connection.IsActive = *req.IsActive
+	}
+	connection.ContextName = req.ContextName
+	connection.ClusterName = req.ClusterName
+	connection.AuthInfoName = req.AuthInfoName
+	connection.CAData = req.CAData
+	connection.ClientCertData = req.ClientCertData
+	connection.TLSServerName = req.TLSServerName
+	connection.InsecureSkipTLSVerify = req.InsecureSkipTLSVerify
+	connection.ImpersonationMode = req.ImpersonationMode
+	connection.ImpersonateUser = req.ImpersonateUser
+	connection.ImpersonateGroups = req.ImpersonateGroups
+	if req.ClientKeyData != "" {
+		connection.ClientKeyData = req.ClientKeyData
+	}
+	if connection.ClientCertData == "" {
+		connection.ClientKeyData = ""
+	}
+	if req.Config != "" {
+		connection.Config = req.Config
+	}
+	if req.Token != "" {
+		connection.Token = req.Token
+	}
+	if connection.Namespace == "" {
+		connection.Namespace = "default"
+	}
+}
+
+func validateConnection(connection *model.K8sConnection) error {
+	if connection.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidConnection)
+	}
+
+	switch connection.ConfigType {
+	case ConfigTypeKubeconfig:
+		if connection.Config == "" {
+			return fmt.Errorf("%w: config is required for kubeconfig connections", ErrInvalidConnection)
+		}
+		// 校验 kubeconfig 及所选上下文，并在未填写时使用其中的 API Server 地址
+		restConfig, err := kubeconfigRestConfig(connection)
+		if err != nil {
+			return err
+		}
+		if connection.Endpoint == "" {
+			connection.Endpoint = restConfig.Host
+		}
+	case ConfigTypeToken:
+		if connection.Endpoint == "" {
+			return fmt.Errorf("%w: endpoint is required for token connections", ErrInvalidConnection)
+		}
+		if connection.Token == "" && connection.ClientCertData == "" {
+			return fmt.Errorf("%w: token or client certificate is required for token connections", ErrInvalidConnection)
+		}
+		if err := validateTLSConfig(connection); err != nil {
+			return err
+		}
+	case ConfigTypeLocal:
+		if !connection.BuiltIn {
+			return fmt.Errorf("%w: config type %q is reserved for the built-in connection", ErrInvalidConnection, ConfigTypeLocal)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported config type %q", ErrInvalidConnection, connection.ConfigType)
+	}
+
+	return validateImpersonation(connection)
+}
+
+// restoreBuiltInCredentials 还原内置连接的配置类型和凭据来源，忽略请求中的凭据字段
+func restoreBuiltInCredentials(connection, original *model.K8sConnection) {
+	connection.Endpoint = original.Endpoint
+	connection.ConfigType = original.ConfigType
+	connection.Config = original.Config
+	connection.ContextName = original.ContextName
+	connection.ClusterName = original.ClusterName
+	connection.AuthInfoName = original.AuthInfoName
+	connection.Token = original.Token
+	connection.CAData = original.CAData
+	connection.ClientCertData = original.ClientCertData
+	connection.ClientKeyData = original.ClientKeyData
+	connection.TLSServerName = original.TLSServerName
+	connection.InsecureSkipTLSVerify = original.InsecureSkipTLSVerify
+}
+
+func deactivateConnections(tx *gorm.DB) error {
+	return tx.Model(&model.K8sConnection{}).Where("is_active = ?", true).Update("is_active", false).Error
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"container-platform-backend/internal/model"
+)
+
+// connectionRows 将连接转换为查询结果
+func connectionRows(connections ...model.K8sConnection) *recordingRows {
+	rows := &recordingRows{columns: []string{
+		"id", "name", "endpoint", "config_type", "config", "token", "namespace",
+		"impersonation_mode", "built_in", "is_active",
+	}}
+	for _, c := range connections {
+		rows.values = append(rows.values, []driver.Value{
+			int64(c.ID), c.Name, c.Endpoint, c.ConfigType, c.Config, c.Token, c.Namespace,
+			c.ImpersonationMode, c.BuiltIn, c.IsActive,
+		})
+	}
+	return rows
+}
+
+// newConnectionDB 返回查询连接时得到 connections 的数据库
+func newConnectionDB(t *testing.T, connections ...model.K8sConnection) (*ConnectionService, *recordingDriver) {
+	t.Helper()
+
+	db, recorder := newRecordingDB(t, 0)
+	recorder.results = func(query string) *recordingRows {
+		if strings.Contains(query, `FROM "k8s_connections"`) && !strings.Contains(query, "count(") {
+			return connectionRows(connections...)
+		}
+		return nil
+	}
+	return NewConnectionService(db), recorder
+}
+
+func TestUpdateConnectionKeepsActive(t *testing.T) {
+	active := model.K8sConnection{
+		Name: "prod", Endpoint: "https://prod.example.com:6443", ConfigType: ConfigTypeToken,
+		Token: "prod-token", Namespace: "default", ImpersonationMode: ImpersonationNone, IsActive: true,
+	}
+	active.ID = 1
+	service, recorder := newConnectionDB(t, active)
+
+	// 只修改名称，未提供 isActive
+	connection, err := service.UpdateConnection(1, &ConnectionRequest{
+		Name: "production", Endpoint: active.Endpoint, ConfigType: ConfigTypeToken, Namespace: "default",
+	})
+	if err != nil {
+		t.Fatalf("UpdateConnection() error = %v", err)
+	}
+	if !connection.IsActive || connection.Name != "production" || connection.Token != "prod-token" {
+		t.Errorf("connection = %+v, want it to stay active with its token", connection)
+	}
+
+	updates := recorder.matching(`UPDATE "k8s_connections"`)
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want only the save: %+v", len(updates), updates)
+	}
+	if _, ok := namedArg(updates[0].args, func(v driver.Value) bool { return v == true }); !ok {
+		t.Errorf("saved connection should stay active: %+v", updates[0].args)
+	}
+}
+
+func TestUpdateConnectionActivation(t *testing.T) {
+	inactive := model.K8sConnection{
+		Name: "staging", Endpoint: "https://staging.example.com:6443", ConfigType: ConfigTypeToken,
+		Token: "staging-token", Namespace: "default", ImpersonationMode: ImpersonationNone,
+	}
+	inactive.ID = 2
+	service, recorder := newConnectionDB(t, inactive)
+
+	isActive := true
+	connection, err := service.UpdateConnection(2, &ConnectionRequest{
+		Name: "staging", Endpoint: inactive.Endpoint, ConfigType: ConfigTypeToken, IsActive: &isActive,
+	})
+	if err != nil {
+		t.Fatalf("UpdateConnection() error = %v", err)
+	}
+	if !connection.IsActive {
+		t.Error("connection should be activated")
+	}
+
+	// 激活时先取消其他连接的激活状态
+	updates := recorder.matching(`UPDATE "k8s_connections"`)
+	if len(updates) != 2 || !strings.Contains(updates[0].query, `SET "is_active"=`) {
+		t.Errorf("updates = %+v, want deactivation before the save", updates)
+	}
+}
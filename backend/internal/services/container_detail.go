@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	"container-platform-backend/internal/model"
+)
+
+// maxDetailEvents 详情中返回的最近事件数
+const maxDetailEvents = 20
+
+// ContainerDetail 容器所在 Pod 的详细状态
+type ContainerDetail struct {
+	PodName   string `json:"podName"`
+	Namespace string `json:"namespace"`
+	// Pod 阶段：Pending、Running、Succeeded、Failed 或 Unknown
+	Phase   string `json:"phase"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// QoS 等级：Guaranteed、Burstable 或 BestEffort
+	QOSClass    string            `json:"qosClass,omitempty"`
+	PodIP       string            `json:"podIp,omitempty"`
+	PodIPs      []string          `json:"podIps,omitempty"`
+	HostIP      string            `json:"hostIp,omitempty"`
+	Node        string            `json:"node,omitempty"`
+	StartTime   *time.Time        `json:"startTime,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	Age         string            `json:"age"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// 所属工作负载：deployment、job、cronjob 或 pod
+	Workload     string `json:"workload"`
+	WorkloadName string `json:"workloadName,omitempty"`
+	ConnectionID uint   `json:"connectionId,omitempty"`
+	Cluster      string `json:"cluster,omitempty"`
+
+	Conditions      []PodConditionInfo     `json:"conditions"`
+	InitContainers  []ContainerStateDetail `json:"initContainers,omitempty"`
+	Containers      []ContainerStateDetail `json:"containers"`
+	OwnerReferences []OwnerReferenceInfo   `json:"ownerReferences,omitempty"`
+	// 最近的事件，按时间倒序
+	Events []EventInfo `json:"events"`
+}
+
+// PodConditionInfo Pod 状况
+type PodConditionInfo struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// ContainerStateDetail 单个容器的当前和上一次状态
+type ContainerStateDetail struct {
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	ImageID     string `json:"imageId,omitempty"`
+	ContainerID string `json:"containerId,omitempty"`
+	// 与容器列表相同的展示状态
+	Status       string `json:"status"`
+	Ready        bool   `json:"ready"`
+	Started      *bool  `json:"started,omitempty"`
+	RestartCount int32  `json:"restartCount"`
+	// 尚未上报状态时为空
+	State     *ContainerStateInfo `json:"state,omitempty"`
+	LastState *ContainerStateInfo `json:"lastState,omitempty"`
+}
+
+// ContainerStateInfo 容器状态：running、waiting 或 terminated
+type ContainerStateInfo struct {
+	State      string     `json:"state"`
+	Reason     string     `json:"reason,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	ExitCode   *int32     `json:"exitCode,omitempty"`
+	Signal     int32      `json:"signal,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// OwnerReferenceInfo Pod 的属主
+type OwnerReferenceInfo struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller bool   `json:"controller"`
+}
+
+// EventInfo Kubernetes 事件
+type EventInfo struct {
+	Type      string     `json:"type"`
+	Reason    string     `json:"reason"`
+	Message   string     `json:"message"`
+	Source    string     `json:"source,omitempty"`
+	Count     int32      `json:"count"`
+	FirstSeen *time.Time `json:"firstSeen,omitempty"`
+	LastSeen  *time.Time `json:"lastSeen,omitempty"`
+}
+
+// GetContainerDetail 获取容器所在 Pod 的详细状态和最近事件
+// podName 也可以是 Deployment 或 Job 名称，此时返回其最新创建的 Pod
+func (s *K8sService) GetContainerDetail(ctx context.Context, connection *model.K8sConnection, namespace, podName string) (*ContainerDetail, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	if workload.pod == nil {
+		pod, err := newestWorkloadPod(ctx, clientSet, namespace, workload)
+		if err != nil {
+			return nil, err
+		}
+		workload.pod = pod
+	}
+	pod := workload.pod
+
+	detail := &ContainerDetail{
+		PodName:      pod.Name,
+		Namespace:    pod.Namespace,
+		Phase:        string(pod.Status.Phase),
+		Reason:       pod.Status.Reason,
+		Message:      pod.Status.Message,
+		QOSClass:     string(pod.Status.QOSClass),
+		PodIP:        pod.Status.PodIP,
+		HostIP:       pod.Status.HostIP,
+		Node:         pod.Spec.NodeName,
+		StartTime:    timePtr(pod.Status.StartTime),
+		CreatedAt:    pod.CreationTimestamp.Time,
+		Age:          calculateAge(pod.CreationTimestamp.Time),
+		Labels:       pod.Labels,
+		Annotations:  pod.Annotations,
+		Workload:     workload.kind(),
+		ConnectionID: connection.ID,
+		Cluster:      connection.Name,
+		Conditions:   []PodConditionInfo{},
+		Containers:   containerStateDetails(pod.Spec.Containers, pod.Status.ContainerStatuses),
+		Events:       []EventInfo{},
+	}
+	if workload.kind() != WorkloadPod {
+		detail.WorkloadName = workload.name()
+	}
+	for _, ip := range pod.Status.PodIPs {
+		detail.PodIPs = append(detail.PodIPs, ip.IP)
+	}
+	for _, condition := range pod.Status.Conditions {
+		detail.Conditions = append(detail.Conditions, PodConditionInfo{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: timePtr(&condition.LastTransitionTime),
+		})
+	}
+	if len(pod.Spec.InitContainers) > 0 {
+		detail.InitContainers = containerStateDetails(pod.Spec.InitContainers, pod.Status.InitContainerStatuses)
+	}
+	for _, owner := range pod.OwnerReferences {
+		detail.OwnerReferences = append(detail.OwnerReferences, OwnerReferenceInfo{
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+			Controller: owner.Controller != nil && *owner.Controller,
+		})
+	}
+
+	events, err := podEvents(ctx, clientSet, pod)
+	if err != nil {
+		return nil, err
+	}
+	detail.Events = events
+
+	return detail, nil
+}
+
+// newestWorkloadPod 按工作负载的选择器查找最新创建的 Pod，已停止或暂停时没有 Pod
+func newestWorkloadPod(ctx context.Context, clientSet kubernetes.Interface, namespace string, workload *workloadRef) (*corev1.Pod, error) {
+	var selector *metav1.LabelSelector
+	switch {
+	case workload.deployment != nil:
+		selector = workload.deployment.Spec.Selector
+	case workload.job != nil:
+		selector = workload.job.Spec.Selector
+	}
+	notFound := fmt.Errorf("%s %s has no pods: %w", workload.kind(), workload.name(),
+		apierrors.NewNotFound(corev1.Resource("pods"), workload.name()))
+	if selector == nil {
+		return nil, notFound
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of %s %s: %w", workload.kind(), workload.name(), err)
+	}
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, notFound
+	}
+
+	newest := &pods.Items[0]
+	for i := range pods.Items[1:] {
+		pod := &pods.Items[i+1]
+		if newest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			newest = pod
+		}
+	}
+	return newest, nil
+}
+
+// containerStateDetails 按容器定义的顺序合并容器状态
+func containerStateDetails(containers []corev1.Container, statuses []corev1.ContainerStatus) []ContainerStateDetail {
+	details := make([]ContainerStateDetail, 0, len(containers))
+	for _, container := range containers {
+		status, restartCount, containerID := deriveContainerStatus(statuses, container.Name)
+		detail := ContainerStateDetail{
+			Name:         container.Name,
+			Image:        container.Image,
+			ContainerID:  containerID,
+			Status:       status,
+			RestartCount: restartCount,
+		}
+		for _, containerStatus := range statuses {
+			if containerStatus.Name != container.Name {
+				continue
+			}
+			detail.ImageID = containerStatus.ImageID
+			detail.Ready = containerStatus.Ready
+			detail.Started = containerStatus.Started
+			detail.State = containerStateInfo(containerStatus.State)
+			detail.LastState = containerStateInfo(containerStatus.LastTerminationState)
+			break
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// containerStateInfo 转换容器状态，状态为空时返回 nil
+func containerStateInfo(state corev1.ContainerState) *ContainerStateInfo {
+	switch {
+	case state.Running != nil:
+		return &ContainerStateInfo{State: "running", StartedAt: timePtr(&state.Running.StartedAt)}
+	case state.Waiting != nil:
+		return &ContainerStateInfo{State: "waiting", Reason: state.Waiting.Reason, Message: state.Waiting.Message}
+	case state.Terminated != nil:
+		exitCode := state.Terminated.ExitCode
+		return &ContainerStateInfo{
+			State:      "terminated",
+			Reason:     state.Terminated.Reason,
+			Message:    state.Terminated.Message,
+			ExitCode:   &exitCode,
+			Signal:     state.Terminated.Signal,
+			StartedAt:  timePtr(&state.Terminated.StartedAt),
+			FinishedAt: timePtr(&state.Terminated.FinishedAt),
+		}
+	default:
+		return nil
+	}
+}
+
+// podEvents 获取 Pod 最近的事件，按最后发生时间倒序
+func podEvents(ctx context.Context, clientSet kubernetes.Interface, pod *corev1.Pod) ([]EventInfo, error) {
+	selector := fields.Set{
+		"involvedObject.kind": "Pod",
+		"involvedObject.name": pod.Name,
+	}.AsSelector().String()
+	events, err := clientSet.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	items := make([]corev1.Event, 0, len(events.Items))
+	for _, event := range events.Items {
+		// 同名的旧 Pod 的事件不属于当前 Pod
+		if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != pod.Name ||
+			(event.InvolvedObject.UID != "" && event.InvolvedObject.UID != pod.UID) {
+			continue
+		}
+		items = append(items, event)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(&items[j]).Before(eventTime(&items[i]))
+	})
+	if len(items) > maxDetailEvents {
+		items = items[:maxDetailEvents]
+	}
+
+	result := make([]EventInfo, 0, len(items))
+	for _, event := range items {
+		info := EventInfo{
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Source:    event.Source.Component,
+			Count:     event.Count,
+			FirstSeen: timePtr(&event.FirstTimestamp),
+			LastSeen:  timePtr(&event.LastTimestamp),
+		}
+		if info.Source == "" {
+			info.Source = event.ReportingController
+		}
+		if info.Count == 0 {
+			info.Count = 1
+		}
+		if info.LastSeen == nil {
+			lastSeen := eventTime(&event)
+			if !lastSeen.IsZero() {
+				info.LastSeen = &lastSeen
+			}
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// eventTime 事件最后发生的时间，新版事件只设置 eventTime
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// timePtr 转换 Kubernetes 时间，零值返回 nil
+func timePtr(t *metav1.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
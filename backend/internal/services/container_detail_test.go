@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestEvent(name, podName, reason string, uid types.UID, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: podName, UID: uid},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " message",
+		Source:         corev1.EventSource{Component: "kubelet"},
+		Count:          2,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func TestGetContainerDetail(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	started := true
+	controller := true
+
+	pod := newTestPod("web", "default", corev1.ContainerStatus{
+		Name:         "app",
+		ImageID:      "docker.io/library/nginx@sha256:abc",
+		ContainerID:  "containerd://abc",
+		RestartCount: 4,
+		Started:      &started,
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+			Reason:  "CrashLoopBackOff",
+			Message: "back-off 40s restarting failed container",
+		}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode:   137,
+			Reason:     "OOMKilled",
+			StartedAt:  metav1.NewTime(now.Add(-time.Minute)),
+			FinishedAt: metav1.NewTime(now),
+		}},
+	})
+	pod.UID = "pod-uid"
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", Controller: &controller}}
+	pod.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.QOSClass = corev1.PodQOSBurstable
+	pod.Status.PodIP = "10.0.0.5"
+	pod.Status.PodIPs = []corev1.PodIP{{IP: "10.0.0.5"}, {IP: "fd00::5"}}
+	pod.Status.HostIP = "192.168.1.10"
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady", LastTransitionTime: metav1.NewTime(now)},
+	}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "init",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+	}}
+
+	service, _ := newFakeService(t, pod,
+		newTestEvent("older", "web", "BackOff", "pod-uid", now.Add(-time.Hour)),
+		newTestEvent("newer", "web", "OOMKilling", "", now),
+		newTestEvent("previous-pod", "web", "Failed", "old-uid", now),
+		newTestEvent("other-pod", "api", "Failed", "", now),
+	)
+
+	detail, err := service.GetContainerDetail(context.Background(), newTestConnection(), "default", "web")
+	if err != nil {
+		t.Fatalf("GetContainerDetail() error = %v", err)
+	}
+
+	if detail.Phase != "Running" || detail.QOSClass != "Burstable" || detail.Node != "node-1" || detail.HostIP != "192.168.1.10" {
+		t.Errorf("GetContainerDetail() = %+v", detail)
+	}
+	if !reflect.DeepEqual(detail.PodIPs, []string{"10.0.0.5", "fd00::5"}) {
+		t.Errorf("pod IPs = %v", detail.PodIPs)
+	}
+	if len(detail.Conditions) != 1 || detail.Conditions[0].Reason != "ContainersNotReady" || !detail.Conditions[0].LastTransitionTime.Equal(now) {
+		t.Errorf("conditions = %+v", detail.Conditions)
+	}
+	if !reflect.DeepEqual(detail.OwnerReferences, []OwnerReferenceInfo{{Kind: "ReplicaSet", Name: "web-abc", Controller: true}}) {
+		t.Errorf("owner references = %+v", detail.OwnerReferences)
+	}
+
+	app := detail.Containers[0]
+	if app.Status != "Pending" || app.RestartCount != 4 || app.ImageID == "" || app.Started == nil || !*app.Started {
+		t.Errorf("container = %+v", app)
+	}
+	wantState := &ContainerStateInfo{State: "waiting", Reason: "CrashLoopBackOff", Message: "back-off 40s restarting failed container"}
+	if !reflect.DeepEqual(app.State, wantState) {
+		t.Errorf("state = %+v, want %+v", app.State, wantState)
+	}
+	if last := app.LastState; last == nil || last.State != "terminated" || last.Reason != "OOMKilled" || last.ExitCode == nil || *last.ExitCode != 137 {
+		t.Errorf("last state = %+v", app.LastState)
+	}
+
+	if len(detail.InitContainers) != 1 || detail.InitContainers[0].Status != "Succeeded" || detail.InitContainers[0].State.Reason != "Completed" {
+		t.Errorf("init containers = %+v", detail.InitContainers)
+	}
+
+	var reasons []string
+	for _, event := range detail.Events {
+		reasons = append(reasons, event.Reason)
+	}
+	if !reflect.DeepEqual(reasons, []string{"OOMKilling", "BackOff"}) {
+		t.Errorf("event reasons = %v, want newest first and only this pod's events", reasons)
+	}
+	if detail.Events[0].Source != "kubelet" || detail.Events[0].Count != 2 {
+		t.Errorf("event = %+v", detail.Events[0])
+	}
+}
+
+func TestGetContainerDetailByDeployment(t *testing.T) {
+	ctx := context.Background()
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx"}
+	if err := service.CreateContainer(ctx, newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	_, err := service.GetContainerDetail(ctx, newTestConnection(), "default", "web")
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("GetContainerDetail() without pods error = %v, want not found", err)
+	}
+
+	for i, name := range []string{"web-old", "web-new"} {
+		pod := newTestPod(name, "default")
+		pod.Labels = map[string]string{appLabel: "web", managedLabel: managedLabelValue}
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(i-2) * time.Hour))
+		if _, err := clientSet.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create pod: %v", err)
+		}
+	}
+
+	detail, err := service.GetContainerDetail(ctx, newTestConnection(), "default", "web")
+	if err != nil {
+		t.Fatalf("GetContainerDetail() error = %v", err)
+	}
+	if detail.PodName != "web-new" || detail.Workload != WorkloadDeployment || detail.WorkloadName != "web" {
+		t.Errorf("GetContainerDetail() = pod %s, workload %s/%s", detail.PodName, detail.Workload, detail.WorkloadName)
+	}
+	if detail.Conditions == nil || detail.Events == nil {
+		t.Errorf("conditions and events should be empty lists, got %v and %v", detail.Conditions, detail.Events)
+	}
+}
+
+func TestGetContainerDetailNotFound(t *testing.T) {
+	service, _ := newFakeService(t)
+
+	_, err := service.GetContainerDetail(context.Background(), newTestConnection(), "default", "missing")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("GetContainerDetail() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"container-platform-backend/internal/model"
+)
+
+// defaultExecSessionTTL 创建的 exec 会话在多久内未建立 WebSocket 连接即失效
+const defaultExecSessionTTL = time.Minute
+
+// DefaultShellCommand 交互式终端未指定命令时启动的 shell，容器中有 bash 时优先使用
+var DefaultShellCommand = []string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash || exec sh"}
+
+// ExecutorFactory 创建 exec 子资源的流式执行器
+type ExecutorFactory func(config *rest.Config, method string, url *url.URL) (remotecommand.Executor, error)
+
+// ExecOptions 在容器中执行命令的参数
+type ExecOptions struct {
+	// 容器名称，为空时使用第一个容器
+	Container string   `json:"container,omitempty"`
+	Command   []string `json:"command"`
+	// 是否转发标准输入
+	Stdin bool `json:"stdin,omitempty"`
+	// 是否分配终端，分配终端时标准错误合并到标准输出
+	TTY bool `json:"tty,omitempty"`
+}
+
+// validate 校验执行参数
+func (o *ExecOptions) validate() error {
+	var errs ValidationErrors
+	if len(o.Command) == 0 || o.Command[0] == "" {
+		errs.add("command", "is required")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ExecStreams exec 的输入输出，未使用的流为空
+type ExecStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// 终端尺寸变化，仅在分配终端时使用
+	Resize remotecommand.TerminalSizeQueue
+}
+
+// ExecResult exec 的执行结果
+type ExecResult struct {
+	PodName   string `json:"podName"`
+	Container string `json:"container"`
+	// 命令的退出码
+	ExitCode int `json:"exitCode"`
+}
+
+// ExecContainer 通过 pods/exec 子资源在容器中执行命令，命令结束或 ctx 取消时返回
+// 命令以非零退出码结束不视为错误，退出码记录在结果中。
+// podName 也可以是 Deployment 或 Job 名称，此时在其最新创建的 Pod 中执行
+func (s *K8sService) ExecContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string, opts *ExecOptions, streams ExecStreams) (*ExecResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	clientSet, config, err := s.clientAndConfigFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	pod := workload.pod
+	if pod == nil {
+		if pod, err = newestWorkloadPod(ctx, clientSet, namespace, workload); err != nil {
+			return nil, err
+		}
+	}
+
+	index := containerIndex(pod.Spec.Containers, opts.Container)
+	if index < 0 {
+		return nil, ValidationErrors{{Field: "container", Message: fmt.Sprintf("container %q not found in pod %s", opts.Container, pod.Name)}}
+	}
+	result := &ExecResult{PodName: pod.Name, Container: pod.Spec.Containers[index].Name}
+
+	execURL, err := podSubresourceURL(config, pod, "exec", &corev1.PodExecOptions{
+		Container: result.Container,
+		Command:   opts.Command,
+		Stdin:     opts.Stdin && streams.Stdin != nil,
+		Stdout:    streams.Stdout != nil,
+		Stderr:    streams.Stderr != nil && !opts.TTY,
+		TTY:       opts.TTY,
+	})
+	if err != nil {
+		return nil, err
+	}
+	executor, err := s.executor(config, "POST", execURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	streamOptions := remotecommand.StreamOptions{
+		Stdout: streams.Stdout,
+		Tty:    opts.TTY,
+	}
+	if opts.Stdin {
+		streamOptions.Stdin = streams.Stdin
+	}
+	if opts.TTY {
+		streamOptions.TerminalSizeQueue = streams.Resize
+	} else {
+		streamOptions.Stderr = streams.Stderr
+	}
+
+	err = executor.StreamWithContext(ctx, streamOptions)
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		result.ExitCode = exitErr.ExitStatus()
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec in %s/%s: %w", pod.Name, result.Container, err)
+	}
+	return result, nil
+}
+
+// podSubresourceURL 构建 Pod 子资源（exec、portforward）的请求地址
+func podSubresourceURL(config *rest.Config, pod *corev1.Pod, subresource string, options runtime.Object) (*url.URL, error) {
+	base, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster endpoint: %w", err)
+	}
+
+	target := *base
+	target.Path = path.Join("/", base.Path, "api", "v1", "namespaces", pod.Namespace, "pods", pod.Name, subresource)
+	if options != nil {
+		params, err := scheme.ParameterCodec.EncodeParameters(options, corev1.SchemeGroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s options: %w", subresource, err)
+		}
+		target.RawQuery = params.Encode()
+	}
+	return &target, nil
+}
+
+// ExecSession 已授权、等待客户端通过 WebSocket 连接的 exec 会话
+type ExecSession struct {
+	ID         string
+	Connection *model.K8sConnection
+	Namespace  string
+	PodName    string
+	Options    ExecOptions
+	User       *PlatformUser
+	// 创建会话时生成的审计日志，会话结束后补全结果并写入
+	Audit     *model.OperationLog
+	ExpiresAt time.Time
+}
+
+// ExecSessionStore 保存待连接的 exec 会话，会话只能使用一次，过期后失效
+type ExecSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*ExecSession
+	ttl      time.Duration
+}
+
+// NewExecSessionStore 创建 exec 会话存储，ttl 为 0 时使用默认值
+func NewExecSessionStore(ttl time.Duration) *ExecSessionStore {
+	if ttl <= 0 {
+		ttl = defaultExecSessionTTL
+	}
+	return &ExecSessionStore{sessions: make(map[string]*ExecSession), ttl: ttl}
+}
+
+// Create 保存会话并分配会话ID
+func (s *ExecSessionStore) Create(session *ExecSession) *ExecSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+
+	session.ID = uuid.NewString()
+	session.ExpiresAt = now.Add(s.ttl)
+	s.sessions[session.ID] = session
+	return session
+}
+
+// Take 取出并删除会话，会话不存在或已过期时返回 false
+func (s *ExecSessionStore) Take(id string) (*ExecSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+
+	session, ok := s.sessions[id]
+	if ok {
+		delete(s.sessions, id)
+	}
+	return session, ok
+}
+
+// evictExpired 删除已过期的会话，调用方需持有锁
+func (s *ExecSessionStore) evictExpired(now time.Time) {
+	for id, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// fakeExecutor 记录请求地址，将标准输入回显到标准输出并返回预设的错误
+type fakeExecutor struct {
+	url  *url.URL
+	opts remotecommand.StreamOptions
+	err  error
+}
+
+func (e *fakeExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return e.StreamWithContext(context.Background(), opts)
+}
+
+func (e *fakeExecutor) StreamWithContext(_ context.Context, opts remotecommand.StreamOptions) error {
+	e.opts = opts
+	if opts.Stdin != nil && opts.Stdout != nil {
+		io.Copy(opts.Stdout, opts.Stdin)
+	}
+	return e.err
+}
+
+func newFakeExecService(t *testing.T, executor *fakeExecutor) *K8sService {
+	t.Helper()
+
+	service, _ := newFakeService(t, newTestPod("web", "default"))
+	service.executor = func(_ *rest.Config, method string, target *url.URL) (remotecommand.Executor, error) {
+		if method != "POST" {
+			t.Errorf("executor method = %s, want POST", method)
+		}
+		executor.url = target
+		return executor, nil
+	}
+	return service
+}
+
+func TestExecContainer(t *testing.T) {
+	executor := &fakeExecutor{}
+	service := newFakeExecService(t, executor)
+
+	var stdout bytes.Buffer
+	opts := &ExecOptions{Command: []string{"cat"}, Stdin: true, TTY: true}
+	streams := ExecStreams{Stdin: strings.NewReader("hello"), Stdout: &stdout, Stderr: io.Discard}
+	result, err := service.ExecContainer(context.Background(), newTestConnection(), "default", "web", opts, streams)
+	if err != nil {
+		t.Fatalf("ExecContainer() error = %v", err)
+	}
+
+	if *result != (ExecResult{PodName: "web", Container: "app", ExitCode: 0}) {
+		t.Errorf("ExecContainer() = %+v", result)
+	}
+	if stdout.String() != "hello" {
+		t.Errorf("stdout = %q, want stdin echoed", stdout.String())
+	}
+	if executor.url.Path != "/api/v1/namespaces/default/pods/web/exec" {
+		t.Errorf("exec path = %s", executor.url.Path)
+	}
+	query := executor.url.Query()
+	if query.Get("container") != "app" || query.Get("command") != "cat" || query.Get("stdin") != "true" || query.Get("tty") != "true" {
+		t.Errorf("exec query = %v", query)
+	}
+	// 分配终端时标准错误合并到标准输出
+	if query.Get("stderr") != "" || executor.opts.Stderr != nil || !executor.opts.Tty {
+		t.Errorf("tty exec streams stderr separately: query %v, options %+v", query, executor.opts)
+	}
+}
+
+func TestExecContainerExitCode(t *testing.T) {
+	executor := &fakeExecutor{err: utilexec.CodeExitError{Err: errors.New("command terminated with exit code 2"), Code: 2}}
+	service := newFakeExecService(t, executor)
+
+	opts := &ExecOptions{Command: []string{"ls", "/missing"}}
+	result, err := service.ExecContainer(context.Background(), newTestConnection(), "default", "web", opts, ExecStreams{Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil {
+		t.Fatalf("ExecContainer() error = %v", err)
+	}
+	if result.ExitCode != 2 {
+		t.Errorf("exit code = %d, want 2", result.ExitCode)
+	}
+	if executor.opts.Stdin != nil || executor.opts.Stderr == nil {
+		t.Errorf("stream options = %+v, want stderr without stdin", executor.opts)
+	}
+}
+
+func TestExecContainerErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  ExecOptions
+		field string
+	}{
+		{"missing command", ExecOptions{}, "command"},
+		{"unknown container", ExecOptions{Container: "sidecar", Command: []string{"sh"}}, "container"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newFakeExecService(t, &fakeExecutor{})
+
+			_, err := service.ExecContainer(context.Background(), newTestConnection(), "default", "web", &tt.opts, ExecStreams{})
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.field {
+				t.Errorf("ExecContainer() error = %v, want field error on %s", err, tt.field)
+			}
+		})
+	}
+
+	t.Run("stream failure", func(t *testing.T) {
+		service := newFakeExecService(t, &fakeExecutor{err: errors.New("upgrade failed")})
+
+		_, err := service.ExecContainer(context.Background(), newTestConnection(), "default", "web", &ExecOptions{Command: []string{"sh"}}, ExecStreams{Stdout: io.Discard})
+		if err == nil || !strings.Contains(err.Error(), "upgrade failed") {
+			t.Errorf("ExecContainer() error = %v, want stream error", err)
+		}
+	})
+}
+
+func TestExecSessionStore(t *testing.T) {
+	store := NewExecSessionStore(time.Minute)
+
+	session := store.Create(&ExecSession{Namespace: "default", PodName: "web", Options: ExecOptions{Command: DefaultShellCommand}})
+	if session.ID == "" || session.ExpiresAt.IsZero() {
+		t.Fatalf("Create() = %+v, want ID and expiry", session)
+	}
+
+	taken, ok := store.Take(session.ID)
+	if !ok || !reflect.DeepEqual(taken, session) {
+		t.Fatalf("Take() = %+v, %v", taken, ok)
+	}
+	if _, ok := store.Take(session.ID); ok {
+		t.Error("Take() returned a session twice")
+	}
+
+	expired := store.Create(&ExecSession{PodName: "web"})
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	if _, ok := store.Take(expired.ID); ok {
+		t.Error("Take() returned an expired session")
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"container-platform-backend/internal/model"
+)
+
+// maxFileCommandStderr 文件传输命令最多保留的错误输出字节数
+const maxFileCommandStderr = 4096
+
+// statScript 输出路径的类型和大小：目录为 "d <条目数> <KB> <路径>"（du 估算，没有 find 时条目数为 0），文件为 "f <字节数>"
+const statScript = `[ -e "$1" ] || { echo "$1: No such file or directory" >&2; exit 2; }
+if [ -d "$1" ]; then printf 'd %s ' "$(find "$1" 2>/dev/null | wc -l)"; du -sk "$1"; else printf 'f '; wc -c < "$1"; fi`
+
+const (
+	// tarBlockSize tar 头部和内容补齐的块大小
+	tarBlockSize = 512
+	// tarRecordSize tar 归档补齐的记录大小（默认 20 个块）
+	tarRecordSize = 20 * tarBlockSize
+)
+
+// extractScript 创建目标目录后从标准输入解压 tar
+const extractScript = `mkdir -p "$1" && tar xf - -C "$1"`
+
+var (
+	// ErrFileNotFound 容器中的文件或目录不存在
+	ErrFileNotFound = errors.New("file not found in container")
+	// ErrFileTooLarge 传输的内容超过大小限制
+	ErrFileTooLarge = errors.New("file exceeds the size limit")
+)
+
+// ContainerFile 容器中的文件或目录
+type ContainerFile struct {
+	PodName   string `json:"podName"`
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Dir       bool   `json:"dir"`
+	// 文件的字节数，目录为 du 估算的占用空间
+	Size int64 `json:"size"`
+	// 目录中的条目数（包括目录本身），用于估算 tar 归档的大小
+	Entries int64 `json:"entries,omitempty"`
+}
+
+// FileUpload 上传到容器的内容
+type FileUpload struct {
+	// 容器名称，为空时使用第一个容器
+	Container string
+	// Archive 为 true 时为解压到的目录，否则为写入的文件路径
+	Path    string
+	Archive bool
+	// Content 为 tar 归档或单个文件的内容，上传单个文件时 Size 为其字节数
+	Content io.Reader
+	Size    int64
+	// 最多读取的字节数
+	MaxBytes int64
+}
+
+// FileTransfer 文件传输的结果
+type FileTransfer struct {
+	PodName   string `json:"podName"`
+	Container string `json:"container"`
+	Path      string `json:"path"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// StatContainerPath 获取容器中文件或目录的类型和大小，需要容器中有 sh、du 和 wc
+// podName 也可以是 Deployment 或 Job 名称，此时使用其最新创建的 Pod
+func (s *K8sService) StatContainerPath(ctx context.Context, connection *model.K8sConnection, namespace, podName, containerName, filePath string) (*ContainerFile, error) {
+	filePath, err := containerFilePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout bytes.Buffer
+	stderr := &tailBuffer{limit: maxFileCommandStderr}
+	opts := &ExecOptions{Container: containerName, Command: []string{"sh", "-c", statScript, "sh", filePath}}
+	result, err := s.ExecContainer(ctx, connection, namespace, podName, opts, ExecStreams{Stdout: &stdout, Stderr: stderr})
+	if err != nil {
+		return nil, err
+	}
+	if err := fileCommandError(result, filePath, stderr); err != nil {
+		return nil, err
+	}
+
+	file := &ContainerFile{PodName: result.PodName, Container: result.Container, Path: filePath}
+	fields := strings.Fields(stdout.String())
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("unexpected output when inspecting %s: %q", filePath, stdout.String())
+	}
+	file.Dir = fields[0] == "d"
+	if file.Dir {
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("unexpected output when inspecting %s: %q", filePath, stdout.String())
+		}
+		if file.Entries, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("unexpected output when inspecting %s: %q", filePath, stdout.String())
+		}
+		fields = fields[1:]
+	}
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected output when inspecting %s: %q", filePath, stdout.String())
+	}
+	file.Size = size
+	if file.Dir {
+		file.Size = size * 1024
+	}
+	return file, nil
+}
+
+// DownloadFromContainer 将容器中的文件或目录写入 w：archive 为 true 时写入 tar 归档（与 kubectl cp 相同），
+// 否则写入文件内容（仅支持文件）。超过 maxBytes 时停止传输并返回 ErrFileTooLarge
+func (s *K8sService) DownloadFromContainer(ctx context.Context, connection *model.K8sConnection, namespace string, file *ContainerFile, archive bool, maxBytes int64, w io.Writer) (*FileTransfer, error) {
+	size := file.Size
+	if archive {
+		size = tarArchiveSize(file)
+	}
+	if size > maxBytes {
+		return nil, fmt.Errorf("%w: %s is %d bytes, limit is %d bytes", ErrFileTooLarge, file.Path, size, maxBytes)
+	}
+
+	command := []string{"tar", "cf", "-", "-C", path.Dir(file.Path), path.Base(file.Path)}
+	if !archive {
+		if file.Dir {
+			return nil, ValidationErrors{{Field: "format", Message: fmt.Sprintf("%s is a directory and can only be downloaded as tar", file.Path)}}
+		}
+		command = []string{"cat", file.Path}
+	}
+
+	stdout := &limitWriter{w: w, remaining: maxBytes}
+	stderr := &tailBuffer{limit: maxFileCommandStderr}
+	opts := &ExecOptions{Container: file.Container, Command: command}
+	result, err := s.ExecContainer(ctx, connection, namespace, file.PodName, opts, ExecStreams{Stdout: stdout, Stderr: stderr})
+
+	transfer := &FileTransfer{PodName: file.PodName, Container: file.Container, Path: file.Path, Bytes: stdout.written}
+	if stdout.exceeded {
+		return transfer, fmt.Errorf("%w: %s exceeded %d bytes during transfer", ErrFileTooLarge, file.Path, maxBytes)
+	}
+	if err != nil {
+		return transfer, err
+	}
+	return transfer, fileCommandError(result, file.Path, stderr)
+}
+
+// tarArchiveSize 估算文件或目录打包为 tar 后的字节数：每个条目按两个头部块（长路径需要额外的扩展头部）计算，
+// 内容补齐到块大小，末尾两个空块，整体补齐到记录大小
+func tarArchiveSize(file *ContainerFile) int64 {
+	entries := int64(1)
+	if file.Dir && file.Entries > entries {
+		entries = file.Entries
+	}
+	size := entries*2*tarBlockSize + roundUp(file.Size, tarBlockSize) + 2*tarBlockSize
+	return roundUp(size, tarRecordSize)
+}
+
+// roundUp 将 n 向上补齐到 unit 的整数倍
+func roundUp(n, unit int64) int64 {
+	return (n + unit - 1) / unit * unit
+}
+
+// UploadToContainer 将 tar 归档解压到容器的目录中，或将单个文件写入容器，需要容器中有 sh 和 tar
+// podName 也可以是 Deployment 或 Job 名称，此时使用其最新创建的 Pod
+func (s *K8sService) UploadToContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string, upload *FileUpload) (*FileTransfer, error) {
+	target, err := containerFilePath(upload.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.Archive && upload.Size > upload.MaxBytes {
+		return nil, fmt.Errorf("%w: upload is %d bytes, limit is %d bytes", ErrFileTooLarge, upload.Size, upload.MaxBytes)
+	}
+
+	content := &limitReader{r: upload.Content, remaining: upload.MaxBytes}
+	var stdin io.Reader = content
+	dir := target
+	if !upload.Archive {
+		// 单个文件打包为只包含该文件的 tar，边读边写
+		dir = path.Dir(target)
+		archive := singleFileArchive(path.Base(target), upload.Size, content)
+		// 命令提前结束时结束打包协程
+		defer archive.Close()
+		stdin = archive
+	}
+
+	stderr := &tailBuffer{limit: maxFileCommandStderr}
+	opts := &ExecOptions{Container: upload.Container, Command: []string{"sh", "-c", extractScript, "sh", dir}, Stdin: true}
+	result, err := s.ExecContainer(ctx, connection, namespace, podName, opts, ExecStreams{Stdin: stdin, Stdout: io.Discard, Stderr: stderr})
+
+	transfer := &FileTransfer{Path: target, Bytes: content.read.Load()}
+	if result != nil {
+		transfer.PodName, transfer.Container = result.PodName, result.Container
+	}
+	if content.exceeded.Load() {
+		return transfer, fmt.Errorf("%w: upload exceeded %d bytes", ErrFileTooLarge, upload.MaxBytes)
+	}
+	if err != nil {
+		return transfer, err
+	}
+	return transfer, fileCommandError(result, dir, stderr)
+}
+
+// singleFileArchive 返回只包含一个文件的 tar 流，content 必须恰好有 size 字节
+func singleFileArchive(name string, size int64, content io.Reader) *io.PipeReader {
+	reader, writer := io.Pipe()
+	go func() {
+		archive := tar.NewWriter(writer)
+		err := archive.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     size,
+			Mode:     0644,
+			ModTime:  time.Now(),
+		})
+		if err == nil {
+			_, err = io.CopyN(archive, content, size)
+		}
+		if err == nil {
+			err = archive.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader
+}
+
+// containerFilePath 校验并规范化容器中的绝对路径
+func containerFilePath(filePath string) (string, error) {
+	if !path.IsAbs(filePath) {
+		return "", ValidationErrors{{Field: "path", Message: "must be an absolute path"}}
+	}
+	filePath = path.Clean(filePath)
+	if filePath == "/" {
+		return "", ValidationErrors{{Field: "path", Message: "cannot be the root directory"}}
+	}
+	return filePath, nil
+}
+
+// fileCommandError 将文件命令的非零退出码转换为错误
+func fileCommandError(result *ExecResult, filePath string, stderr *tailBuffer) error {
+	if result.ExitCode == 0 {
+		return nil
+	}
+	message := strings.TrimSpace(stderr.String())
+	switch {
+	case strings.Contains(message, "No such file or directory"):
+		return fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+	case result.ExitCode == 126 || result.ExitCode == 127:
+		return fmt.Errorf("%w: container %s does not provide the required tools: %s", ErrUnsupportedWorkload, result.Container, message)
+	default:
+		return fmt.Errorf("command exited with code %d: %s", result.ExitCode, message)
+	}
+}
+
+// limitWriter 最多写入 remaining 字节，超出时返回 ErrFileTooLarge
+type limitWriter struct {
+	w         io.Writer
+	remaining int64
+	written   int64
+	exceeded  bool
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		l.exceeded = true
+		return 0, ErrFileTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= int64(n)
+	l.written += int64(n)
+	return n, err
+}
+
+// limitReader 最多读取 remaining 字节，还有更多内容时返回 ErrFileTooLarge
+// exec 的标准输入在单独的协程中读取，统计结果使用原子变量
+type limitReader struct {
+	r         io.Reader
+	remaining int64
+	read      atomic.Int64
+	exceeded  atomic.Bool
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// 恰好读完限制时确认是否还有剩余内容
+		var probe [1]byte
+		if n, _ := l.r.Read(probe[:]); n > 0 {
+			l.exceeded.Store(true)
+			return 0, ErrFileTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	l.read.Add(int64(n))
+	return n, err
+}
+
+// tailBuffer 只保留最后 limit 字节的错误输出
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.buf)
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// scriptExecutor 按命令返回预设的输出和退出码
+type scriptExecutor struct {
+	command []string
+	stdin   []byte
+	stdout  string
+	stderr  string
+	exit    int
+}
+
+func (e *scriptExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return e.StreamWithContext(context.Background(), opts)
+}
+
+func (e *scriptExecutor) StreamWithContext(_ context.Context, opts remotecommand.StreamOptions) error {
+	if opts.Stdin != nil {
+		var err error
+		if e.stdin, err = io.ReadAll(opts.Stdin); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(opts.Stdout, e.stdout); err != nil {
+		return err
+	}
+	io.WriteString(opts.Stderr, e.stderr)
+	if e.exit != 0 {
+		return utilexec.CodeExitError{Err: errors.New("command terminated with non-zero exit code"), Code: e.exit}
+	}
+	return nil
+}
+
+func newScriptExecService(t *testing.T, executor *scriptExecutor) *K8sService {
+	t.Helper()
+
+	service, _ := newFakeService(t, newTestPod("web", "default"))
+	service.executor = func(_ *rest.Config, _ string, target *url.URL) (remotecommand.Executor, error) {
+		executor.command = target.Query()["command"]
+		return executor, nil
+	}
+	return service
+}
+
+func TestStatContainerPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor *scriptExecutor
+		want     ContainerFile
+		wantErr  error
+	}{
+		{
+			name:     "file",
+			executor: &scriptExecutor{stdout: "f 1536\n"},
+			want:     ContainerFile{PodName: "web", Container: "app", Path: "/etc/nginx/nginx.conf", Size: 1536},
+		},
+		{
+			name:     "directory",
+			executor: &scriptExecutor{stdout: "d 3 8\t/etc/nginx/nginx.conf\n"},
+			want:     ContainerFile{PodName: "web", Container: "app", Path: "/etc/nginx/nginx.conf", Dir: true, Size: 8192, Entries: 3},
+		},
+		{
+			name:     "missing",
+			executor: &scriptExecutor{stderr: "/etc/nginx/nginx.conf: No such file or directory\n", exit: 2},
+			wantErr:  ErrFileNotFound,
+		},
+		{
+			name:     "no shell",
+			executor: &scriptExecutor{stderr: "exec: \"sh\": executable file not found\n", exit: 127},
+			wantErr:  ErrUnsupportedWorkload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newScriptExecService(t, tt.executor)
+			file, err := service.StatContainerPath(context.Background(), newTestConnection(), "default", "web", "", "/etc/nginx/./nginx.conf")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("StatContainerPath() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StatContainerPath() error = %v", err)
+			}
+			if *file != tt.want {
+				t.Errorf("file = %+v, want %+v", *file, tt.want)
+			}
+			if got := tt.executor.command; len(got) != 5 || got[0] != "sh" || got[4] != "/etc/nginx/nginx.conf" {
+				t.Errorf("command = %q", got)
+			}
+		})
+	}
+}
+
+func TestDownloadFromContainer(t *testing.T) {
+	file := &ContainerFile{PodName: "web", Container: "app", Path: "/var/log/app", Dir: true, Size: 4096}
+
+	executor := &scriptExecutor{stdout: "archive-content"}
+	service := newScriptExecService(t, executor)
+	var out bytes.Buffer
+	transfer, err := service.DownloadFromContainer(context.Background(), newTestConnection(), "default", file, true, 1<<20, &out)
+	if err != nil {
+		t.Fatalf("DownloadFromContainer() error = %v", err)
+	}
+	if got := strings.Join(executor.command, " "); got != "tar cf - -C /var/log app" {
+		t.Errorf("command = %q", got)
+	}
+	if out.String() != "archive-content" || transfer.Bytes != int64(out.Len()) {
+		t.Errorf("output = %q, transfer = %+v", out.String(), transfer)
+	}
+
+	// 目录只能以 tar 下载
+	_, err = service.DownloadFromContainer(context.Background(), newTestConnection(), "default", file, false, 1<<20, io.Discard)
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || validationErrs[0].Field != "format" {
+		t.Errorf("raw download of directory error = %v, want format validation error", err)
+	}
+
+	// 估算大小超过限制时不开始传输
+	if _, err := service.DownloadFromContainer(context.Background(), newTestConnection(), "default", file, true, 1024, io.Discard); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("DownloadFromContainer() error = %v, want ErrFileTooLarge", err)
+	}
+
+	// tar 的头部和补齐计入大小，刚好达到限制的文件只能以 raw 下载
+	single := &ContainerFile{PodName: "web", Container: "app", Path: "/var/log/app.log", Size: 1 << 20}
+	if _, err := service.DownloadFromContainer(context.Background(), newTestConnection(), "default", single, true, 1<<20, io.Discard); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("tar download at the limit error = %v, want ErrFileTooLarge", err)
+	}
+	if _, err := service.DownloadFromContainer(context.Background(), newTestConnection(), "default", single, false, 1<<20, io.Discard); err != nil {
+		t.Errorf("raw download at the limit error = %v", err)
+	}
+
+	// 文件在获取大小后变大，传输过程中超过限制时停止
+	executor.stdout = strings.Repeat("x", 2048)
+	single.Size = 0
+	transfer, err = service.DownloadFromContainer(context.Background(), newTestConnection(), "default", single, false, 1024, io.Discard)
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("DownloadFromContainer() error = %v, want ErrFileTooLarge", err)
+	}
+	if transfer.Bytes > 1024 {
+		t.Errorf("transferred %d bytes beyond the limit", transfer.Bytes)
+	}
+}
+
+func TestTarArchiveSize(t *testing.T) {
+	tests := []struct {
+		name string
+		file ContainerFile
+		want int64
+	}{
+		{name: "empty file", file: ContainerFile{}, want: tarRecordSize},
+		{name: "one record", file: ContainerFile{Size: tarRecordSize - 4*tarBlockSize}, want: tarRecordSize},
+		{name: "padding spills into next record", file: ContainerFile{Size: tarRecordSize - 4*tarBlockSize + 1}, want: 2 * tarRecordSize},
+		{name: "directory entries", file: ContainerFile{Dir: true, Entries: 10, Size: 4096}, want: 2 * tarRecordSize},
+		{name: "directory without entry count", file: ContainerFile{Dir: true, Size: 4096}, want: tarRecordSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tarArchiveSize(&tt.file); got != tt.want {
+				t.Errorf("tarArchiveSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadToContainer(t *testing.T) {
+	executor := &scriptExecutor{}
+	service := newScriptExecService(t, executor)
+
+	content := "server { listen 80; }\n"
+	transfer, err := service.UploadToContainer(context.Background(), newTestConnection(), "default", "web", &FileUpload{
+		Path:     "/etc/nginx/conf.d/default.conf",
+		Content:  strings.NewReader(content),
+		Size:     int64(len(content)),
+		MaxBytes: 1 << 20,
+	})
+	if err != nil {
+		t.Fatalf("UploadToContainer() error = %v", err)
+	}
+	want := FileTransfer{PodName: "web", Container: "app", Path: "/etc/nginx/conf.d/default.conf", Bytes: int64(len(content))}
+	if *transfer != want {
+		t.Errorf("transfer = %+v, want %+v", *transfer, want)
+	}
+	if got := executor.command; len(got) != 5 || got[2] != extractScript || got[4] != "/etc/nginx/conf.d" {
+		t.Errorf("command = %q", got)
+	}
+
+	// 单个文件打包为 tar 写入标准输入
+	archive := tar.NewReader(bytes.NewReader(executor.stdin))
+	header, err := archive.Next()
+	if err != nil {
+		t.Fatalf("reading uploaded archive: %v", err)
+	}
+	body, _ := io.ReadAll(archive)
+	if header.Name != "default.conf" || string(body) != content {
+		t.Errorf("archive entry = %s %q", header.Name, body)
+	}
+	if _, err := archive.Next(); err != io.EOF {
+		t.Errorf("archive should contain a single entry, got %v", err)
+	}
+
+	// tar 归档超过限制
+	_, err = service.UploadToContainer(context.Background(), newTestConnection(), "default", "web", &FileUpload{
+		Path:     "/srv",
+		Archive:  true,
+		Content:  strings.NewReader(strings.Repeat("x", 2048)),
+		MaxBytes: 1024,
+	})
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("UploadToContainer() error = %v, want ErrFileTooLarge", err)
+	}
+}
+
+func TestContainerFilePath(t *testing.T) {
+	for input, want := range map[string]string{
+		"/tmp/a.txt":      "/tmp/a.txt",
+		"/tmp/../etc/x/":  "/etc/x",
+		"//var//log/app/": "/var/log/app",
+	} {
+		got, err := containerFilePath(input)
+		if err != nil || got != want {
+			t.Errorf("containerFilePath(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "tmp/a.txt", "/", "/tmp/.."} {
+		if _, err := containerFilePath(input); err == nil {
+			t.Errorf("containerFilePath(%q) should fail", input)
+		}
+	}
+}
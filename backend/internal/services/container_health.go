@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"container-platform-backend/internal/model"
+)
+
+// 探针和生命周期钩子的检查方式
+const (
+	ProbeTypeHTTP = "http"
+	ProbeTypeTCP  = "tcp"
+	ProbeTypeExec = "exec"
+	ProbeTypeGRPC = "grpc"
+)
+
+// HealthSpec 容器的健康检查探针和生命周期配置
+type HealthSpec struct {
+	LivenessProbe  *ProbeSpec `json:"livenessProbe,omitempty"`
+	ReadinessProbe *ProbeSpec `json:"readinessProbe,omitempty"`
+	StartupProbe   *ProbeSpec `json:"startupProbe,omitempty"`
+	// postStart 和 preStop 钩子
+	Lifecycle *LifecycleSpec `json:"lifecycle,omitempty"`
+	// 停止容器时等待优雅退出的秒数，为空时使用集群默认值（30 秒）
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// ProbeSpec 健康检查探针
+type ProbeSpec struct {
+	HandlerSpec
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds,omitempty"`
+	SuccessThreshold    int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    int32 `json:"failureThreshold,omitempty"`
+}
+
+// HandlerSpec 探针或生命周期钩子的检查方式
+type HandlerSpec struct {
+	// 类型：http、tcp、exec 或 grpc（生命周期钩子仅支持 http 和 exec）
+	Type string `json:"type"`
+	// http、tcp 和 grpc 检查的端口
+	Port int32 `json:"port,omitempty"`
+	// http 检查的路径和协议（HTTP 或 HTTPS）
+	Path        string       `json:"path,omitempty"`
+	Scheme      string       `json:"scheme,omitempty"`
+	HTTPHeaders []HTTPHeader `json:"httpHeaders,omitempty"`
+	// exec 检查执行的命令
+	Command []string `json:"command,omitempty"`
+	// grpc 健康检查的服务名
+	Service string `json:"service,omitempty"`
+}
+
+// HTTPHeader http 检查附带的请求头
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LifecycleSpec 容器生命周期钩子
+type LifecycleSpec struct {
+	PostStart *HandlerSpec `json:"postStart,omitempty"`
+	PreStop   *HandlerSpec `json:"preStop,omitempty"`
+}
+
+// validate 校验探针和生命周期配置，字段名以 prefix 开头
+func (h *HealthSpec) validate(errs *ValidationErrors, prefix string) {
+	probes := []struct {
+		field string
+		probe *ProbeSpec
+	}{
+		{"livenessProbe", h.LivenessProbe},
+		{"readinessProbe", h.ReadinessProbe},
+		{"startupProbe", h.StartupProbe},
+	}
+	for _, p := range probes {
+		if p.probe == nil {
+			continue
+		}
+		field := prefix + p.field
+		p.probe.HandlerSpec.validate(errs, field, true)
+
+		for _, setting := range []struct {
+			name  string
+			value int32
+		}{
+			{"initialDelaySeconds", p.probe.InitialDelaySeconds},
+			{"periodSeconds", p.probe.PeriodSeconds},
+			{"timeoutSeconds", p.probe.TimeoutSeconds},
+			{"successThreshold", p.probe.SuccessThreshold},
+			{"failureThreshold", p.probe.FailureThreshold},
+		} {
+			if setting.value < 0 {
+				errs.add(field+"."+setting.name, "must not be negative")
+			}
+		}
+		// 存活和启动探针的成功阈值只能为 1
+		if p.field != "readinessProbe" && p.probe.SuccessThreshold > 1 {
+			errs.add(field+".successThreshold", "must be 1 for %s", p.field)
+		}
+	}
+
+	if h.Lifecycle != nil {
+		if h.Lifecycle.PostStart != nil {
+			h.Lifecycle.PostStart.validate(errs, prefix+"lifecycle.postStart", false)
+		}
+		if h.Lifecycle.PreStop != nil {
+			h.Lifecycle.PreStop.validate(errs, prefix+"lifecycle.preStop", false)
+		}
+	}
+
+	if h.TerminationGracePeriodSeconds != nil && *h.TerminationGracePeriodSeconds < 0 {
+		errs.add(prefix+"terminationGracePeriodSeconds", "must not be negative")
+	}
+}
+
+// validate 校验检查方式，probe 为 false 时为生命周期钩子，只允许 http 和 exec
+func (h *HandlerSpec) validate(errs *ValidationErrors, field string, probe bool) {
+	validatePort := func() {
+		for _, msg := range validation.IsValidPortNum(int(h.Port)) {
+			errs.add(field+".port", "%s", msg)
+		}
+	}
+
+	switch h.Type {
+	case ProbeTypeHTTP:
+		validatePort()
+		if h.Path != "" && !strings.HasPrefix(h.Path, "/") {
+			errs.add(field+".path", "must start with /")
+		}
+		switch strings.ToUpper(h.Scheme) {
+		case "", string(corev1.URISchemeHTTP), string(corev1.URISchemeHTTPS):
+		default:
+			errs.add(field+".scheme", "unsupported scheme %q, must be HTTP or HTTPS", h.Scheme)
+		}
+		for i, header := range h.HTTPHeaders {
+			for _, msg := range validation.IsHTTPHeaderName(header.Name) {
+				errs.add(fmt.Sprintf("%s.httpHeaders[%d].name", field, i), "%s", msg)
+			}
+		}
+	case ProbeTypeExec:
+		if len(h.Command) == 0 {
+			errs.add(field+".command", "is required for exec handlers")
+		}
+	case ProbeTypeTCP, ProbeTypeGRPC:
+		if !probe {
+			errs.add(field+".type", "unsupported type %q for lifecycle hooks, must be http or exec", h.Type)
+			return
+		}
+		validatePort()
+	default:
+		if probe {
+			errs.add(field+".type", "unsupported type %q, must be http, tcp, exec or grpc", h.Type)
+		} else {
+			errs.add(field+".type", "unsupported type %q, must be http or exec", h.Type)
+		}
+	}
+}
+
+// apply 将探针和生命周期配置写入容器和 Pod 定义，未设置的项会被清除
+func (h *HealthSpec) apply(container *corev1.Container, podSpec *corev1.PodSpec) {
+	container.LivenessProbe = h.LivenessProbe.probe()
+	container.ReadinessProbe = h.ReadinessProbe.probe()
+	container.StartupProbe = h.StartupProbe.probe()
+
+	container.Lifecycle = nil
+	if h.Lifecycle != nil && (h.Lifecycle.PostStart != nil || h.Lifecycle.PreStop != nil) {
+		container.Lifecycle = &corev1.Lifecycle{
+			PostStart: h.Lifecycle.PostStart.lifecycleHandler(),
+			PreStop:   h.Lifecycle.PreStop.lifecycleHandler(),
+		}
+	}
+
+	podSpec.TerminationGracePeriodSeconds = h.TerminationGracePeriodSeconds
+}
+
+func (p *ProbeSpec) probe() *corev1.Probe {
+	if p == nil {
+		return nil
+	}
+
+	probe := &corev1.Probe{
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       p.PeriodSeconds,
+		TimeoutSeconds:      p.TimeoutSeconds,
+		SuccessThreshold:    p.SuccessThreshold,
+		FailureThreshold:    p.FailureThreshold,
+	}
+	switch p.Type {
+	case ProbeTypeHTTP:
+		probe.HTTPGet = p.httpGet()
+	case ProbeTypeTCP:
+		probe.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt32(p.Port)}
+	case ProbeTypeExec:
+		probe.Exec = &corev1.ExecAction{Command: p.Command}
+	case ProbeTypeGRPC:
+		probe.GRPC = &corev1.GRPCAction{Port: p.Port}
+		if p.Service != "" {
+			service := p.Service
+			probe.GRPC.Service = &service
+		}
+	}
+	return probe
+}
+
+func (h *HandlerSpec) lifecycleHandler() *corev1.LifecycleHandler {
+	if h == nil {
+		return nil
+	}
+
+	switch h.Type {
+	case ProbeTypeHTTP:
+		return &corev1.LifecycleHandler{HTTPGet: h.httpGet()}
+	case ProbeTypeExec:
+		return &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: h.Command}}
+	default:
+		return nil
+	}
+}
+
+func (h *HandlerSpec) httpGet() *corev1.HTTPGetAction {
+	action := &corev1.HTTPGetAction{
+		Path:   h.Path,
+		Port:   intstr.FromInt32(h.Port),
+		Scheme: corev1.URIScheme(strings.ToUpper(h.Scheme)),
+	}
+	for _, header := range h.HTTPHeaders {
+		action.HTTPHeaders = append(action.HTTPHeaders, corev1.HTTPHeader{Name: header.Name, Value: header.Value})
+	}
+	return action
+}
+
+// UpdateContainerHealth 更新 Deployment 中容器的探针和生命周期配置，会触发滚动更新
+// containerName 为空时更新第一个容器
+func (s *K8sService) UpdateContainerHealth(ctx context.Context, connection *model.K8sConnection, namespace, podName, containerName string, spec *HealthSpec) error {
+	var errs ValidationErrors
+	spec.validate(&errs, "")
+	if len(errs) > 0 {
+		return errs
+	}
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return err
+	}
+	if workload.deployment == nil {
+		return fmt.Errorf("%w: probes can only be updated on deployments, not %s %s", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+	index := containerIndex(workload.deployment.Spec.Template.Spec.Containers, containerName)
+	if index < 0 {
+		return ValidationErrors{{Field: "container", Message: fmt.Sprintf("container %q not found in deployment %s", containerName, workload.name())}}
+	}
+
+	err = updateDeployment(ctx, clientSet, namespace, workload.name(), func(deployment *appsv1.Deployment) {
+		podSpec := &deployment.Spec.Template.Spec
+		if index < len(podSpec.Containers) {
+			spec.apply(&podSpec.Containers[index], podSpec)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update deployment: %w", err)
+	}
+
+	log.Printf("Updated probes of deployment %s container %s", workload.name(), workload.deployment.Spec.Template.Spec.Containers[index].Name)
+	return nil
+}
+
+// containerIndex 按名称查找容器，名称为空时返回第一个容器
+func containerIndex(containers []corev1.Container, name string) int {
+	if name == "" && len(containers) > 0 {
+		return 0
+	}
+	for i, container := range containers {
+		if container.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestCreateContainerWithProbes(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	gracePeriod := int64(45)
+	req := &CreateContainerRequest{
+		Name:      "web",
+		Namespace: "default",
+		Image:     "nginx",
+		HealthSpec: HealthSpec{
+			LivenessProbe: &ProbeSpec{
+				HandlerSpec:         HandlerSpec{Type: ProbeTypeHTTP, Port: 8080, Path: "/healthz", Scheme: "https", HTTPHeaders: []HTTPHeader{{Name: "X-Probe", Value: "1"}}},
+				InitialDelaySeconds: 10,
+				FailureThreshold:    3,
+			},
+			ReadinessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeTCP, Port: 8080}, PeriodSeconds: 5},
+			StartupProbe:   &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeGRPC, Port: 9000, Service: "health"}},
+			Lifecycle: &LifecycleSpec{
+				PreStop: &HandlerSpec{Type: ProbeTypeExec, Command: []string{"nginx", "-s", "quit"}},
+			},
+			TerminationGracePeriodSeconds: &gracePeriod,
+		},
+	}
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	spec := getDeployment(t, clientSet, "default", "web").Spec.Template.Spec
+	container := spec.Containers[0]
+
+	liveness := container.LivenessProbe
+	if liveness == nil || liveness.HTTPGet == nil {
+		t.Fatalf("liveness probe = %+v, want http", liveness)
+	}
+	wantHTTP := &corev1.HTTPGetAction{
+		Path:        "/healthz",
+		Port:        intstr.FromInt32(8080),
+		Scheme:      corev1.URISchemeHTTPS,
+		HTTPHeaders: []corev1.HTTPHeader{{Name: "X-Probe", Value: "1"}},
+	}
+	if !reflect.DeepEqual(liveness.HTTPGet, wantHTTP) || liveness.InitialDelaySeconds != 10 || liveness.FailureThreshold != 3 {
+		t.Errorf("liveness probe = %+v, http = %+v", liveness, liveness.HTTPGet)
+	}
+
+	if readiness := container.ReadinessProbe; readiness == nil || readiness.TCPSocket == nil || readiness.TCPSocket.Port != intstr.FromInt32(8080) || readiness.PeriodSeconds != 5 {
+		t.Errorf("readiness probe = %+v", readiness)
+	}
+	if startup := container.StartupProbe; startup == nil || startup.GRPC == nil || startup.GRPC.Port != 9000 || startup.GRPC.Service == nil || *startup.GRPC.Service != "health" {
+		t.Errorf("startup probe = %+v", startup)
+	}
+
+	if container.Lifecycle == nil || container.Lifecycle.PostStart != nil || container.Lifecycle.PreStop == nil || container.Lifecycle.PreStop.Exec == nil {
+		t.Fatalf("lifecycle = %+v", container.Lifecycle)
+	}
+	if !reflect.DeepEqual(container.Lifecycle.PreStop.Exec.Command, []string{"nginx", "-s", "quit"}) {
+		t.Errorf("preStop command = %v", container.Lifecycle.PreStop.Exec.Command)
+	}
+	if spec.TerminationGracePeriodSeconds == nil || *spec.TerminationGracePeriodSeconds != 45 {
+		t.Errorf("terminationGracePeriodSeconds = %v, want 45", spec.TerminationGracePeriodSeconds)
+	}
+}
+
+func TestValidateHealthSpec(t *testing.T) {
+	negative := int64(-1)
+
+	tests := []struct {
+		name   string
+		spec   HealthSpec
+		fields []string
+	}{
+		{"unknown probe type", HealthSpec{LivenessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: "udp"}}}, []string{"livenessProbe.type"}},
+		{"missing port", HealthSpec{ReadinessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeTCP}}}, []string{"readinessProbe.port"}},
+		{
+			name:   "invalid http probe",
+			spec:   HealthSpec{LivenessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeHTTP, Port: 80, Path: "healthz", Scheme: "ftp", HTTPHeaders: []HTTPHeader{{Name: "bad header"}}}}},
+			fields: []string{"livenessProbe.path", "livenessProbe.scheme", "livenessProbe.httpHeaders[0].name"},
+		},
+		{"exec without command", HealthSpec{StartupProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeExec}}}, []string{"startupProbe.command"}},
+		{
+			name:   "invalid timings",
+			spec:   HealthSpec{LivenessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeTCP, Port: 80}, PeriodSeconds: -1, SuccessThreshold: 2}},
+			fields: []string{"livenessProbe.periodSeconds", "livenessProbe.successThreshold"},
+		},
+		{"tcp lifecycle hook", HealthSpec{Lifecycle: &LifecycleSpec{PreStop: &HandlerSpec{Type: ProbeTypeTCP, Port: 80}}}, []string{"lifecycle.preStop.type"}},
+		{"negative grace period", HealthSpec{TerminationGracePeriodSeconds: &negative}, []string{"terminationGracePeriodSeconds"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", HealthSpec: tt.spec}
+
+			var validationErrs ValidationErrors
+			if !errors.As(req.Validate(), &validationErrs) {
+				t.Fatalf("Validate() succeeded, want field errors %v", tt.fields)
+			}
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestUpdateContainerHealth(t *testing.T) {
+	ctx := context.Background()
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{
+		Name:      "web",
+		Namespace: "default",
+		Image:     "nginx",
+		HealthSpec: HealthSpec{
+			LivenessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeTCP, Port: 80}},
+		},
+	}
+	if err := service.CreateContainer(ctx, newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	spec := &HealthSpec{ReadinessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeHTTP, Port: 80, Path: "/ready"}}}
+	if err := service.UpdateContainerHealth(ctx, newTestConnection(), "default", "web", "", spec); err != nil {
+		t.Fatalf("UpdateContainerHealth() error = %v", err)
+	}
+
+	container := getDeployment(t, clientSet, "default", "web").Spec.Template.Spec.Containers[0]
+	if container.LivenessProbe != nil {
+		t.Errorf("liveness probe = %+v, want removed", container.LivenessProbe)
+	}
+	if container.ReadinessProbe == nil || container.ReadinessProbe.HTTPGet == nil || container.ReadinessProbe.HTTPGet.Path != "/ready" {
+		t.Errorf("readiness probe = %+v", container.ReadinessProbe)
+	}
+
+	err := service.UpdateContainerHealth(ctx, newTestConnection(), "default", "web", "sidecar", spec)
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || validationErrs[0].Field != "container" {
+		t.Errorf("UpdateContainerHealth() with unknown container error = %v, want field error on container", err)
+	}
+}
+
+func TestUpdateContainerHealthRejectsPod(t *testing.T) {
+	service, _ := newFakeService(t, newTestPod("web", "default"))
+
+	spec := &HealthSpec{LivenessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeTCP, Port: 80}}}
+	err := service.UpdateContainerHealth(context.Background(), newTestConnection(), "default", "web", "", spec)
+	if !errors.Is(err, ErrUnsupportedWorkload) {
+		t.Errorf("UpdateContainerHealth() error = %v, want ErrUnsupportedWorkload", err)
+	}
+}
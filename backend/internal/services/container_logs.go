@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"container-platform-backend/internal/model"
+)
+
+// LogOptions 容器日志查询参数
+type LogOptions struct {
+	// 容器名称，为空时使用第一个容器，也可以是初始化容器
+	Container string `form:"container" json:"container,omitempty"`
+	// 持续输出新日志
+	Follow bool `form:"follow" json:"follow,omitempty"`
+	// 只返回最后若干行
+	TailLines *int64 `form:"tailLines" json:"tailLines,omitempty"`
+	// 只返回最近若干秒的日志
+	SinceSeconds *int64 `form:"sinceSeconds" json:"sinceSeconds,omitempty"`
+	// 每行前加上时间戳
+	Timestamps bool `form:"timestamps" json:"timestamps,omitempty"`
+	// 返回上一次运行（重启前）的日志
+	Previous bool `form:"previous" json:"previous,omitempty"`
+	// 最多返回的字节数
+	LimitBytes *int64 `form:"limitBytes" json:"limitBytes,omitempty"`
+}
+
+// validate 校验日志查询参数
+func (o *LogOptions) validate() error {
+	var errs ValidationErrors
+	if o.TailLines != nil && *o.TailLines < 0 {
+		errs.add("tailLines", "must not be negative")
+	}
+	if o.SinceSeconds != nil && *o.SinceSeconds <= 0 {
+		errs.add("sinceSeconds", "must be positive")
+	}
+	if o.LimitBytes != nil && *o.LimitBytes <= 0 {
+		errs.add("limitBytes", "must be positive")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// LogStream 容器日志流，使用后需要关闭
+type LogStream struct {
+	PodName   string
+	Container string
+	io.ReadCloser
+}
+
+// StreamContainerLogs 打开容器日志流，follow 时流在 ctx 取消或容器退出前不会结束
+// podName 也可以是 Deployment 或 Job 名称，此时读取其最新创建的 Pod
+func (s *K8sService) StreamContainerLogs(ctx context.Context, connection *model.K8sConnection, namespace, podName string, opts *LogOptions) (*LogStream, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	pod := workload.pod
+	if pod == nil {
+		if pod, err = newestWorkloadPod(ctx, clientSet, namespace, workload); err != nil {
+			return nil, err
+		}
+	}
+
+	container, err := podContainerName(pod, opts.Container)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Follow {
+		// 跟随的日志流会超过默认的请求超时
+		if clientSet, err = s.streamingClientFor(ctx, connection); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := clientSet.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container:    container,
+		Follow:       opts.Follow,
+		TailLines:    opts.TailLines,
+		SinceSeconds: opts.SinceSeconds,
+		Timestamps:   opts.Timestamps,
+		Previous:     opts.Previous,
+		LimitBytes:   opts.LimitBytes,
+	}).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs of %s/%s: %w", pod.Name, container, err)
+	}
+
+	return &LogStream{PodName: pod.Name, Container: container, ReadCloser: stream}, nil
+}
+
+// podContainerName 校验容器属于该 Pod（含初始化容器），名称为空时返回第一个容器
+func podContainerName(pod *corev1.Pod, name string) (string, error) {
+	if index := containerIndex(pod.Spec.Containers, name); index >= 0 {
+		return pod.Spec.Containers[index].Name, nil
+	}
+	if name != "" && containerIndex(pod.Spec.InitContainers, name) >= 0 {
+		return name, nil
+	}
+	return "", ValidationErrors{{Field: "container", Message: fmt.Sprintf("container %q not found in pod %s", name, pod.Name)}}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestStreamContainerLogs(t *testing.T) {
+	pod := newTestPod("web", "default")
+	pod.Spec.InitContainers = []corev1.Container{{Name: "migrate", Image: "busybox"}}
+	service, _ := newFakeService(t, pod)
+
+	tests := []struct {
+		name      string
+		container string
+		want      string
+	}{
+		{"default container", "", "app"},
+		{"named container", "app", "app"},
+		{"init container", "migrate", "migrate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tailLines := int64(100)
+			stream, err := service.StreamContainerLogs(context.Background(), newTestConnection(), "default", "web", &LogOptions{Container: tt.container, TailLines: &tailLines})
+			if err != nil {
+				t.Fatalf("StreamContainerLogs() error = %v", err)
+			}
+			defer stream.Close()
+
+			if stream.PodName != "web" || stream.Container != tt.want {
+				t.Errorf("StreamContainerLogs() = %s/%s, want web/%s", stream.PodName, stream.Container, tt.want)
+			}
+			// 假客户端对任意 Pod 返回固定日志
+			logs, err := io.ReadAll(stream)
+			if err != nil || string(logs) != "fake logs" {
+				t.Errorf("logs = %q, %v", logs, err)
+			}
+		})
+	}
+}
+
+func TestStreamContainerLogsErrors(t *testing.T) {
+	negative := int64(-1)
+	zero := int64(0)
+
+	tests := []struct {
+		name   string
+		pod    string
+		opts   LogOptions
+		fields []string
+	}{
+		{"invalid options", "web", LogOptions{TailLines: &negative, SinceSeconds: &zero, LimitBytes: &zero}, []string{"tailLines", "sinceSeconds", "limitBytes"}},
+		{"unknown container", "web", LogOptions{Container: "sidecar"}, []string{"container"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newFakeService(t, newTestPod("web", "default"))
+
+			_, err := service.StreamContainerLogs(context.Background(), newTestConnection(), "default", tt.pod, &tt.opts)
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("StreamContainerLogs() error = %v, want field errors %v", err, tt.fields)
+			}
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("StreamContainerLogs() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+
+	t.Run("stopped deployment", func(t *testing.T) {
+		service, _ := newFakeService(t)
+		req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx"}
+		if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+			t.Fatalf("CreateContainer() error = %v", err)
+		}
+
+		_, err := service.StreamContainerLogs(context.Background(), newTestConnection(), "default", "web", &LogOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("StreamContainerLogs() error = %v, want not found", err)
+		}
+	})
+}
@@ -0,0 +1,918 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"container-platform-backend/internal/model"
+)
+
+// 导入时对 ConfigMap 和 PVC 执行的操作
+const (
+	ImportActionCreated   = "created"
+	ImportActionUpdated   = "updated"
+	ImportActionUnchanged = "unchanged"
+)
+
+// ErrConfigMapConflict 清单中的 ConfigMap 与已有的 ConfigMap 同名，且已有的不由平台管理
+var ErrConfigMapConflict = errors.New("configmap already exists and is not managed by the platform")
+
+// serviceAccountTokenPrefix API Server 为 Pod 自动注入的 ServiceAccount 令牌卷的名称前缀
+const serviceAccountTokenPrefix = "kube-api-access-"
+
+// serverAnnotations 由集群或 kubectl 维护的注解，导出时移除
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+}
+
+// ContainerExport 导出的容器清单
+type ContainerExport struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	// 清单包含的资源，如 Deployment/web、Service/web
+	Resources []string `json:"resources"`
+	// 多文档 YAML，依次为 ConfigMap、PVC、工作负载和 Service
+	Manifest string `json:"manifest"`
+}
+
+// ManifestSource YAML 清单文本，请求中也可以直接提供资源的 JSON 对象
+type ManifestSource string
+
+func (m *ManifestSource) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*m = ManifestSource(value)
+		return nil
+	}
+	*m = ManifestSource(data)
+	return nil
+}
+
+// ImportContainerRequest 导入容器请求
+type ImportContainerRequest struct {
+	// 多文档 YAML 清单：一个 Deployment 或 Pod，可选一个同名 Service 以及 ConfigMap 和 PVC
+	Config ManifestSource `json:"config" swaggertype:"string"`
+	// 目标命名空间，覆盖清单中的命名空间
+	Namespace string `json:"namespace,omitempty"`
+	// 只校验，不创建任何资源
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ContainerImport 从清单映射得到的容器及其依赖的资源
+type ContainerImport struct {
+	// 映射得到的创建容器请求，Service 映射为 expose
+	Request *CreateContainerRequest `json:"request"`
+	// 已应用（dryRun 时为将要应用）的 ConfigMap 和 PVC
+	Resources []ImportedResource `json:"resources,omitempty"`
+	// 平台不支持、导入时忽略的设置
+	Warnings []string `json:"warnings,omitempty"`
+
+	configMaps []*corev1.ConfigMap
+	claims     []*corev1.PersistentVolumeClaim
+}
+
+// ImportedResource 导入时应用的 ConfigMap 或 PVC
+type ImportedResource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// 执行的操作：created、updated 或 unchanged
+	Action string `json:"action"`
+}
+
+// ExportContainer 将容器导出为可直接用于 kubectl apply 的多文档 YAML
+// 包含 Deployment 或独立 Pod、选择该工作负载的同名 Service，以及引用的 ConfigMap 和 PVC；
+// 状态、命名空间、UID、资源版本、ClusterIP、节点端口等由集群生成的字段会被移除，Secret 只保留引用。
+func (s *K8sService) ExportContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) (*ContainerExport, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	var object runtime.Object
+	var podSpec *corev1.PodSpec
+	var podLabels map[string]string
+	switch workload.kind() {
+	case WorkloadDeployment:
+		deployment := workload.deployment.DeepCopy()
+		deployment.TypeMeta = metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"}
+		cleanObjectMeta(&deployment.ObjectMeta)
+		deployment.Status = appsv1.DeploymentStatus{}
+		object, podSpec, podLabels = deployment, &deployment.Spec.Template.Spec, deployment.Spec.Template.Labels
+	case WorkloadPod:
+		pod := workload.pod.DeepCopy()
+		pod.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Pod"}
+		cleanObjectMeta(&pod.ObjectMeta)
+		pod.Status = corev1.PodStatus{}
+		pod.Spec.NodeName = ""
+		pod.Spec.DeprecatedServiceAccount = ""
+		removeServiceAccountToken(&pod.Spec)
+		object, podSpec, podLabels = pod, &pod.Spec, pod.Labels
+	default:
+		return nil, fmt.Errorf("%w: cannot export %s %s", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+
+	export := &ContainerExport{Name: workload.name(), Namespace: namespace, Kind: object.GetObjectKind().GroupVersionKind().Kind}
+	var objects []runtime.Object
+	add := func(obj runtime.Object, name string) {
+		objects = append(objects, obj)
+		export.Resources = append(export.Resources, obj.GetObjectKind().GroupVersionKind().Kind+"/"+name)
+	}
+
+	configMapNames, claimNames := podSpecReferences(podSpec)
+	for _, name := range configMapNames {
+		configMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get configmap %s: %w", name, err)
+		}
+		configMap = configMap.DeepCopy()
+		configMap.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ConfigMap"}
+		cleanObjectMeta(&configMap.ObjectMeta)
+		add(configMap, name)
+	}
+	for _, name := range claimNames {
+		claim, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get persistent volume claim %s: %w", name, err)
+		}
+		claim = claim.DeepCopy()
+		claim.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "PersistentVolumeClaim"}
+		cleanObjectMeta(&claim.ObjectMeta)
+		claim.Spec.VolumeName = ""
+		claim.Status = corev1.PersistentVolumeClaimStatus{}
+		add(claim, name)
+	}
+
+	add(object, workload.name())
+
+	serviceName := exposedServiceName(workload)
+	service, err := clientSet.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		if len(service.Spec.Selector) == 0 || !labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(podLabels)) {
+			break
+		}
+		service = service.DeepCopy()
+		service.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Service"}
+		cleanObjectMeta(&service.ObjectMeta)
+		cleanServiceSpec(&service.Spec)
+		service.Status = corev1.ServiceStatus{}
+		add(service, serviceName)
+	case !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err):
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	export.Manifest, err = renderManifest(objects...)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// cleanObjectMeta 只保留名称、标签和用户设置的注解
+func cleanObjectMeta(meta *metav1.ObjectMeta) {
+	*meta = metav1.ObjectMeta{Name: meta.Name, Labels: meta.Labels, Annotations: meta.Annotations}
+	for _, annotation := range serverAnnotations {
+		delete(meta.Annotations, annotation)
+	}
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+// cleanServiceSpec 移除集群分配的 IP 和节点端口，无头 Service 保留 ClusterIP: None
+func cleanServiceSpec(spec *corev1.ServiceSpec) {
+	if spec.ClusterIP != corev1.ClusterIPNone {
+		spec.ClusterIP = ""
+	}
+	spec.ClusterIPs = nil
+	spec.IPFamilies = nil
+	spec.IPFamilyPolicy = nil
+	spec.HealthCheckNodePort = 0
+	for i := range spec.Ports {
+		spec.Ports[i].NodePort = 0
+	}
+}
+
+// removeServiceAccountToken 移除 API Server 注入的 ServiceAccount 令牌卷及其挂载点
+func removeServiceAccountToken(podSpec *corev1.PodSpec) {
+	volumes := podSpec.Volumes[:0]
+	for _, volume := range podSpec.Volumes {
+		if !isServiceAccountToken(volume) {
+			volumes = append(volumes, volume)
+		}
+	}
+	podSpec.Volumes = volumes
+	if len(volumes) == 0 {
+		podSpec.Volumes = nil
+	}
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		mounts := container.VolumeMounts[:0]
+		for _, mount := range container.VolumeMounts {
+			if !strings.HasPrefix(mount.Name, serviceAccountTokenPrefix) {
+				mounts = append(mounts, mount)
+			}
+		}
+		container.VolumeMounts = mounts
+		if len(mounts) == 0 {
+			container.VolumeMounts = nil
+		}
+	}
+}
+
+func isServiceAccountToken(volume corev1.Volume) bool {
+	return volume.Projected != nil && strings.HasPrefix(volume.Name, serviceAccountTokenPrefix)
+}
+
+// podSpecReferences Pod 引用的 ConfigMap 和 PVC 名称，按名称排序
+func podSpecReferences(podSpec *corev1.PodSpec) ([]string, []string) {
+	configMaps := make(map[string]bool)
+	claims := make(map[string]bool)
+
+	for _, volume := range podSpec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			configMaps[volume.ConfigMap.Name] = true
+		case volume.PersistentVolumeClaim != nil:
+			claims[volume.PersistentVolumeClaim.ClaimName] = true
+		case volume.Projected != nil && !isServiceAccountToken(volume):
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					configMaps[source.ConfigMap.Name] = true
+				}
+			}
+		}
+	}
+	for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+				configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+		}
+		for _, source := range container.EnvFrom {
+			if source.ConfigMapRef != nil {
+				configMaps[source.ConfigMapRef.Name] = true
+			}
+		}
+	}
+
+	return sortedKeys(configMaps), sortedKeys(claims)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParseContainerManifest 解析导入的 YAML 清单并映射为创建容器请求
+// 清单必须包含一个单容器的 Deployment 或 Pod，可以包含一个与容器同名的 Service 以及任意 ConfigMap 和 PVC；
+// namespace 不为空时覆盖清单中的命名空间。无法映射的字段返回 documents[i] 开头的字段错误，
+// 平台不支持但不影响运行的设置（如 nodeSelector、tolerations）被忽略并记入 Warnings。
+func ParseContainerManifest(manifest, namespace string) (*ContainerImport, error) {
+	documents, err := manifestDocuments(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := &ContainerImport{}
+	var errs ValidationErrors
+	var req *CreateContainerRequest
+	var podSpec *corev1.PodSpec
+	var workloadField, serviceField string
+	var service *corev1.Service
+	manifestNamespace := ""
+
+	for i, document := range documents {
+		field := fmt.Sprintf("documents[%d]", i)
+		if namespace == "" && document.GetNamespace() != "" {
+			if manifestNamespace == "" {
+				manifestNamespace = document.GetNamespace()
+			} else if document.GetNamespace() != manifestNamespace {
+				errs.add(field+".metadata.namespace", "all resources must be in the same namespace, found %q and %q", manifestNamespace, document.GetNamespace())
+			}
+		}
+
+		switch gvk := document.GroupVersionKind(); gvk {
+		case appsv1.SchemeGroupVersion.WithKind("Deployment"), corev1.SchemeGroupVersion.WithKind("Pod"):
+			if req != nil {
+				errs.add(field+".kind", "only one Deployment or Pod is allowed, already found %s", workloadField)
+				continue
+			}
+			if gvk.Kind == "Deployment" {
+				var deployment appsv1.Deployment
+				if !decodeManifestObject(document, &deployment, field, &errs) {
+					continue
+				}
+				req = &CreateContainerRequest{Name: deployment.Name, Kind: WorkloadDeployment, Replicas: deployment.Spec.Replicas}
+				podSpec = &deployment.Spec.Template.Spec
+				imported.checkLabels(deployment.Labels, field)
+				field += ".spec.template.spec"
+			} else {
+				var pod corev1.Pod
+				if !decodeManifestObject(document, &pod, field, &errs) {
+					continue
+				}
+				req = &CreateContainerRequest{Name: strings.TrimSuffix(pod.Name, "-pod"), Kind: WorkloadPod}
+				if req.WorkloadName() != pod.Name {
+					imported.warn(field+".metadata.name", "the pod will be created as %s", req.WorkloadName())
+				}
+				podSpec = &pod.Spec
+				imported.checkLabels(pod.Labels, field)
+				field += ".spec"
+			}
+			workloadField = fmt.Sprintf("documents[%d]", i)
+			imported.mapPodSpec(req, podSpec, field, &errs)
+		case corev1.SchemeGroupVersion.WithKind("Service"):
+			if service != nil {
+				errs.add(field+".kind", "only one Service is allowed, already found %s", serviceField)
+				continue
+			}
+			service = &corev1.Service{}
+			if !decodeManifestObject(document, service, field, &errs) {
+				continue
+			}
+			serviceField = field
+		case corev1.SchemeGroupVersion.WithKind("ConfigMap"):
+			var configMap corev1.ConfigMap
+			if decodeManifestObject(document, &configMap, field, &errs) {
+				cleanObjectMeta(&configMap.ObjectMeta)
+				imported.configMaps = append(imported.configMaps, &configMap)
+			}
+		case corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"):
+			var claim corev1.PersistentVolumeClaim
+			if decodeManifestObject(document, &claim, field, &errs) {
+				cleanObjectMeta(&claim.ObjectMeta)
+				claim.Spec.VolumeName = ""
+				claim.Status = corev1.PersistentVolumeClaimStatus{}
+				imported.claims = append(imported.claims, &claim)
+			}
+		default:
+			errs.add(field+".kind", "unsupported resource %s %s, must be Deployment, Pod, Service, ConfigMap or PersistentVolumeClaim",
+				document.GetAPIVersion(), document.GetKind())
+		}
+	}
+
+	if req == nil && len(errs) == 0 {
+		errs.add("documents", "must contain a Deployment or Pod")
+	}
+	if service != nil && req != nil {
+		imported.mapService(req, service, podSpec, serviceField, &errs)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	req.Version = ContainerRequestV2
+	req.Namespace = namespace
+	if req.Namespace == "" {
+		req.Namespace = manifestNamespace
+	}
+	imported.Request = req
+	return imported, nil
+}
+
+// manifestDocuments 拆分多文档 YAML，跳过空文档并展开 kind: List
+func manifestDocuments(manifest string) ([]*unstructured.Unstructured, error) {
+	var documents []*unstructured.Unstructured
+	var errs ValidationErrors
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+	for {
+		data, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ValidationErrors{{Field: "config", Message: fmt.Sprintf("invalid YAML: %v", err)}}
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			errs.add(fmt.Sprintf("documents[%d]", len(documents)), "invalid YAML: %v", err)
+			continue
+		}
+		var items []interface{}
+		switch value := value.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			items = value
+		case map[string]interface{}:
+			if list, ok := value["items"].([]interface{}); ok && value["kind"] == "List" {
+				items = list
+			} else {
+				items = []interface{}{value}
+			}
+		default:
+			errs.add(fmt.Sprintf("documents[%d]", len(documents)), "must be a Kubernetes resource")
+			continue
+		}
+
+		for _, item := range items {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				errs.add(fmt.Sprintf("documents[%d]", len(documents)), "must be a Kubernetes resource")
+				continue
+			}
+			documents = append(documents, &unstructured.Unstructured{Object: object})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return documents, nil
+}
+
+// decodeManifestObject 将文档转换为具体类型，拒绝未知字段
+func decodeManifestObject(document *unstructured.Unstructured, obj interface{}, field string, errs *ValidationErrors) bool {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(document.Object, obj, true); err != nil {
+		errs.add(field, "%v", err)
+		return false
+	}
+	return true
+}
+
+func (imp *ContainerImport) warn(field, format string, args ...interface{}) {
+	imp.Warnings = append(imp.Warnings, field+": "+fmt.Sprintf(format, args...))
+}
+
+// checkLabels 工作负载使用平台的 app 和 managed 标签，其他标签不会保留
+func (imp *ContainerImport) checkLabels(workloadLabels map[string]string, field string) {
+	var ignored []string
+	for key := range workloadLabels {
+		if key != appLabel && key != managedLabel {
+			ignored = append(ignored, key)
+		}
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		imp.warn(field+".metadata.labels", "labels %s are ignored", strings.Join(ignored, ", "))
+	}
+}
+
+// mapPodSpec 将 Pod 定义映射到创建容器请求
+func (imp *ContainerImport) mapPodSpec(req *CreateContainerRequest, podSpec *corev1.PodSpec, field string, errs *ValidationErrors) {
+	if len(podSpec.Containers) != 1 {
+		errs.add(field+".containers", "must contain exactly one container, found %d", len(podSpec.Containers))
+		return
+	}
+	if len(podSpec.InitContainers) > 0 {
+		errs.add(field+".initContainers", "init containers are not supported")
+	}
+
+	container := podSpec.Containers[0]
+	containerField := field + ".containers[0]"
+	req.Image = container.Image
+	req.Command = container.Command
+	req.Args = container.Args
+
+	for i, env := range container.Env {
+		spec := EnvVarSpec{Name: env.Name, Value: env.Value}
+		switch source := env.ValueFrom; {
+		case source == nil:
+		case source.SecretKeyRef != nil:
+			spec.ValueFrom = &EnvVarSource{Type: EnvSourceSecret, Name: source.SecretKeyRef.Name, Key: source.SecretKeyRef.Key}
+			spec.ValueFrom.Optional = source.SecretKeyRef.Optional != nil && *source.SecretKeyRef.Optional
+		case source.ConfigMapKeyRef != nil:
+			spec.ValueFrom = &EnvVarSource{Type: EnvSourceConfigMap, Name: source.ConfigMapKeyRef.Name, Key: source.ConfigMapKeyRef.Key}
+			spec.ValueFrom.Optional = source.ConfigMapKeyRef.Optional != nil && *source.ConfigMapKeyRef.Optional
+		default:
+			errs.add(fmt.Sprintf("%s.env[%d].valueFrom", containerField, i), "only secretKeyRef and configMapKeyRef are supported")
+		}
+		req.Env = append(req.Env, spec)
+	}
+	if len(container.EnvFrom) > 0 {
+		errs.add(containerField+".envFrom", "is not supported, list the variables in env instead")
+	}
+
+	for i, port := range container.Ports {
+		req.Ports = append(req.Ports, PortSpec{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: string(port.Protocol)})
+		if port.HostPort != 0 {
+			imp.warn(fmt.Sprintf("%s.ports[%d].hostPort", containerField, i), "is ignored")
+		}
+	}
+
+	req.Resources = imp.mapResources(container.Resources, containerField+".resources")
+	imp.mapVolumes(req, podSpec, &container, field, errs)
+
+	req.LivenessProbe = mapProbe(container.LivenessProbe, container.Ports, containerField+".livenessProbe", errs)
+	req.ReadinessProbe = mapProbe(container.ReadinessProbe, container.Ports, containerField+".readinessProbe", errs)
+	req.StartupProbe = mapProbe(container.StartupProbe, container.Ports, containerField+".startupProbe", errs)
+	if lifecycle := container.Lifecycle; lifecycle != nil && (lifecycle.PostStart != nil || lifecycle.PreStop != nil) {
+		req.Lifecycle = &LifecycleSpec{
+			PostStart: mapLifecycleHandler(lifecycle.PostStart, container.Ports, containerField+".lifecycle.postStart", errs),
+			PreStop:   mapLifecycleHandler(lifecycle.PreStop, container.Ports, containerField+".lifecycle.preStop", errs),
+		}
+	}
+	req.TerminationGracePeriodSeconds = podSpec.TerminationGracePeriodSeconds
+
+	for _, setting := range []struct {
+		field string
+		set   bool
+	}{
+		{field + ".nodeSelector", len(podSpec.NodeSelector) > 0},
+		{field + ".affinity", podSpec.Affinity != nil},
+		{field + ".tolerations", hasCustomTolerations(podSpec.Tolerations)},
+		{field + ".imagePullSecrets", len(podSpec.ImagePullSecrets) > 0},
+		{field + ".serviceAccountName", podSpec.ServiceAccountName != "" && podSpec.ServiceAccountName != "default"},
+		{field + ".hostNetwork", podSpec.HostNetwork},
+		{field + ".securityContext", podSpec.SecurityContext != nil && !reflect.DeepEqual(*podSpec.SecurityContext, corev1.PodSecurityContext{})},
+		{containerField + ".securityContext", container.SecurityContext != nil},
+		{containerField + ".workingDir", container.WorkingDir != ""},
+	} {
+		if setting.set {
+			imp.warn(setting.field, "is not supported and is ignored")
+		}
+	}
+}
+
+// hasCustomTolerations 是否包含 API Server 为 Pod 默认添加之外的容忍
+func hasCustomTolerations(tolerations []corev1.Toleration) bool {
+	for _, toleration := range tolerations {
+		if toleration.Key != corev1.TaintNodeNotReady && toleration.Key != corev1.TaintNodeUnreachable {
+			return true
+		}
+	}
+	return false
+}
+
+// mapResources 映射 CPU 和内存的请求与限制，其他资源被忽略
+func (imp *ContainerImport) mapResources(requirements corev1.ResourceRequirements, field string) *ResourceSpec {
+	quantity := func(list corev1.ResourceList, name corev1.ResourceName) string {
+		if value, ok := list[name]; ok {
+			return value.String()
+		}
+		return ""
+	}
+
+	spec := &ResourceSpec{
+		CPURequest:    quantity(requirements.Requests, corev1.ResourceCPU),
+		CPULimit:      quantity(requirements.Limits, corev1.ResourceCPU),
+		MemoryRequest: quantity(requirements.Requests, corev1.ResourceMemory),
+		MemoryLimit:   quantity(requirements.Limits, corev1.ResourceMemory),
+	}
+	for _, list := range []corev1.ResourceList{requirements.Requests, requirements.Limits} {
+		for name := range list {
+			if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+				imp.warn(field, "resource %s is ignored", name)
+			}
+		}
+	}
+
+	if *spec == (ResourceSpec{}) {
+		return nil
+	}
+	return spec
+}
+
+// mapVolumes 将容器的挂载点及其存储卷映射为挂载定义，未挂载的存储卷被忽略
+func (imp *ContainerImport) mapVolumes(req *CreateContainerRequest, podSpec *corev1.PodSpec, container *corev1.Container, field string, errs *ValidationErrors) {
+	indexes := make(map[string]int)
+	for i, volume := range podSpec.Volumes {
+		indexes[volume.Name] = i
+	}
+	mounted := make(map[string]bool)
+
+	for i, mount := range container.VolumeMounts {
+		index, ok := indexes[mount.Name]
+		if !ok {
+			errs.add(fmt.Sprintf("%s.containers[0].volumeMounts[%d].name", field, i), "volume %q is not defined", mount.Name)
+			continue
+		}
+		volume := podSpec.Volumes[index]
+		if isServiceAccountToken(volume) {
+			continue
+		}
+		mounted[volume.Name] = true
+
+		spec := VolumeMountSpec{Name: mount.Name, MountPath: mount.MountPath, SubPath: mount.SubPath, ReadOnly: mount.ReadOnly}
+		volumeField := fmt.Sprintf("%s.volumes[%d]", field, index)
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			spec.Type, spec.Source = VolumeTypePVC, volume.PersistentVolumeClaim.ClaimName
+		case volume.ConfigMap != nil:
+			spec.Type, spec.Source = VolumeTypeConfigMap, volume.ConfigMap.Name
+			if len(volume.ConfigMap.Items) > 0 {
+				imp.warn(volumeField+".configMap.items", "is ignored, all keys are mounted")
+			}
+		case volume.Secret != nil:
+			spec.Type, spec.Source = VolumeTypeSecret, volume.Secret.SecretName
+			if len(volume.Secret.Items) > 0 {
+				imp.warn(volumeField+".secret.items", "is ignored, all keys are mounted")
+			}
+		case volume.EmptyDir != nil:
+			spec.Type, spec.Medium = VolumeTypeEmptyDir, string(volume.EmptyDir.Medium)
+			if volume.EmptyDir.SizeLimit != nil {
+				spec.SizeLimit = volume.EmptyDir.SizeLimit.String()
+			}
+		case volume.HostPath != nil:
+			spec.Type, spec.HostPath = VolumeTypeHostPath, volume.HostPath.Path
+		default:
+			errs.add(volumeField, "unsupported volume source, must be persistentVolumeClaim, configMap, secret, emptyDir or hostPath")
+			continue
+		}
+		req.Volumes = append(req.Volumes, spec)
+	}
+
+	for i, volume := range podSpec.Volumes {
+		if !mounted[volume.Name] && !isServiceAccountToken(volume) {
+			imp.warn(fmt.Sprintf("%s.volumes[%d]", field, i), "volume %q is not mounted by the container and is ignored", volume.Name)
+		}
+	}
+}
+
+// mapService 将 Service 映射为暴露配置，Service 名称必须与容器名称一致
+func (imp *ContainerImport) mapService(req *CreateContainerRequest, service *corev1.Service, podSpec *corev1.PodSpec, field string, errs *ValidationErrors) {
+	if service.Name != req.Name {
+		errs.add(field+".metadata.name", "must match the container name %q", req.Name)
+	}
+
+	expose := &ExposeSpec{Type: string(service.Spec.Type)}
+	switch service.Spec.Type {
+	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		errs.add(field+".spec.type", "unsupported type %q, must be ClusterIP, NodePort or LoadBalancer", service.Spec.Type)
+	}
+	if service.Spec.ClusterIP == corev1.ClusterIPNone {
+		imp.warn(field+".spec.clusterIP", "headless services are not supported, a cluster IP will be allocated")
+	}
+
+	var containerPorts []corev1.ContainerPort
+	if podSpec != nil && len(podSpec.Containers) > 0 {
+		containerPorts = podSpec.Containers[0].Ports
+	}
+	for i, port := range service.Spec.Ports {
+		target := port.TargetPort.IntVal
+		switch {
+		case port.TargetPort.Type == intstr.String:
+			target = namedPort(containerPorts, port.TargetPort.StrVal)
+			if target == 0 {
+				errs.add(fmt.Sprintf("%s.spec.ports[%d].targetPort", field, i), "port %q is not declared by the container", port.TargetPort.StrVal)
+				continue
+			}
+		case target == 0:
+			target = port.Port
+		}
+		expose.Ports = append(expose.Ports, ServicePortSpec{
+			ContainerPort: target,
+			Protocol:      string(port.Protocol),
+			ServicePort:   port.Port,
+			NodePort:      port.NodePort,
+		})
+	}
+	req.Expose = expose
+}
+
+// namedPort 按名称查找容器端口，找不到时返回 0
+func namedPort(ports []corev1.ContainerPort, name string) int32 {
+	for _, port := range ports {
+		if port.Name == name {
+			return port.ContainerPort
+		}
+	}
+	return 0
+}
+
+func mapProbe(probe *corev1.Probe, ports []corev1.ContainerPort, field string, errs *ValidationErrors) *ProbeSpec {
+	if probe == nil {
+		return nil
+	}
+
+	handler := mapHandler(probe.Exec, probe.HTTPGet, probe.TCPSocket, probe.GRPC, ports, field, errs)
+	if handler == nil {
+		return nil
+	}
+	return &ProbeSpec{
+		HandlerSpec:         *handler,
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+}
+
+func mapLifecycleHandler(handler *corev1.LifecycleHandler, ports []corev1.ContainerPort, field string, errs *ValidationErrors) *HandlerSpec {
+	if handler == nil {
+		return nil
+	}
+	if handler.TCPSocket != nil {
+		errs.add(field, "only httpGet and exec hooks are supported")
+		return nil
+	}
+	return mapHandler(handler.Exec, handler.HTTPGet, nil, nil, ports, field, errs)
+}
+
+// mapHandler 映射探针或生命周期钩子的检查方式，命名端口解析为容器端口号
+func mapHandler(exec *corev1.ExecAction, httpGet *corev1.HTTPGetAction, tcpSocket *corev1.TCPSocketAction, grpc *corev1.GRPCAction,
+	ports []corev1.ContainerPort, field string, errs *ValidationErrors) *HandlerSpec {
+	port := func(value intstr.IntOrString, portField string) int32 {
+		if value.Type == intstr.Int {
+			return value.IntVal
+		}
+		number := namedPort(ports, value.StrVal)
+		if number == 0 {
+			errs.add(field+portField, "port %q is not declared by the container", value.StrVal)
+		}
+		return number
+	}
+
+	switch {
+	case exec != nil:
+		return &HandlerSpec{Type: ProbeTypeExec, Command: exec.Command}
+	case httpGet != nil:
+		handler := &HandlerSpec{Type: ProbeTypeHTTP, Port: port(httpGet.Port, ".httpGet.port"), Path: httpGet.Path, Scheme: string(httpGet.Scheme)}
+		for _, header := range httpGet.HTTPHeaders {
+			handler.HTTPHeaders = append(handler.HTTPHeaders, HTTPHeader{Name: header.Name, Value: header.Value})
+		}
+		return handler
+	case tcpSocket != nil:
+		return &HandlerSpec{Type: ProbeTypeTCP, Port: port(tcpSocket.Port, ".tcpSocket.port")}
+	case grpc != nil:
+		handler := &HandlerSpec{Type: ProbeTypeGRPC, Port: grpc.Port}
+		if grpc.Service != nil {
+			handler.Service = *grpc.Service
+		}
+		return handler
+	default:
+		errs.add(field, "must specify exec, httpGet, tcpSocket or grpc")
+		return nil
+	}
+}
+
+// ApplyImportResources 在容器的命名空间中创建或更新清单中的 ConfigMap，创建不存在的 PVC（已有的 PVC 保持不变）
+// 同名 ConfigMap 不由平台管理且数据不同时返回 ErrConfigMapConflict
+// dryRun 时以 DryRun=All 提交，不会修改集群
+func (s *K8sService) ApplyImportResources(ctx context.Context, connection *model.K8sConnection, imported *ContainerImport, dryRun bool) error {
+	if err := imported.Request.Validate(); err != nil {
+		return err
+	}
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return err
+	}
+
+	var dryRunOptions []string
+	if dryRun {
+		dryRunOptions = []string{metav1.DryRunAll}
+	}
+	namespace := imported.Request.Namespace
+
+	imported.Resources = nil
+	for _, configMap := range imported.configMaps {
+		desired := configMap.DeepCopy()
+		desired.Namespace = namespace
+		action, err := applyConfigMap(ctx, clientSet, desired, dryRunOptions)
+		if err != nil {
+			return err
+		}
+		imported.Resources = append(imported.Resources, ImportedResource{Kind: "ConfigMap", Name: desired.Name, Action: action})
+	}
+
+	for _, claim := range imported.claims {
+		desired := claim.DeepCopy()
+		desired.Namespace = namespace
+		client := clientSet.CoreV1().PersistentVolumeClaims(namespace)
+
+		action := ImportActionUnchanged
+		_, err := client.Get(ctx, desired.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			if _, err := client.Create(ctx, desired, metav1.CreateOptions{DryRun: dryRunOptions}); err != nil {
+				return fmt.Errorf("failed to create persistent volume claim %s: %w", desired.Name, err)
+			}
+			action = ImportActionCreated
+		case err != nil:
+			return fmt.Errorf("failed to get persistent volume claim %s: %w", desired.Name, err)
+		}
+		imported.Resources = append(imported.Resources, ImportedResource{Kind: "PersistentVolumeClaim", Name: desired.Name, Action: action})
+	}
+	return nil
+}
+
+// applyConfigMap 创建带平台标签的 ConfigMap，已存在时只更新由平台管理的 ConfigMap 的数据
+func applyConfigMap(ctx context.Context, clientSet kubernetes.Interface, desired *corev1.ConfigMap, dryRun []string) (string, error) {
+	client := clientSet.CoreV1().ConfigMaps(desired.Namespace)
+
+	existing, err := client.Get(ctx, desired.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if desired.Labels == nil {
+			desired.Labels = map[string]string{}
+		}
+		desired.Labels[managedLabel] = managedLabelValue
+		if _, err := client.Create(ctx, desired, metav1.CreateOptions{DryRun: dryRun}); err != nil {
+			return "", fmt.Errorf("failed to create configmap %s: %w", desired.Name, err)
+		}
+		return ImportActionCreated, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get configmap %s: %w", desired.Name, err)
+	}
+
+	sameData := (len(existing.Data) == 0 && len(desired.Data) == 0) || reflect.DeepEqual(existing.Data, desired.Data)
+	sameBinaryData := (len(existing.BinaryData) == 0 && len(desired.BinaryData) == 0) || reflect.DeepEqual(existing.BinaryData, desired.BinaryData)
+	if sameData && sameBinaryData {
+		return ImportActionUnchanged, nil
+	}
+	// 不覆盖其他工作负载或用户自行创建的配置
+	if existing.Labels[managedLabel] != managedLabelValue {
+		return "", fmt.Errorf("%w: %s/%s", ErrConfigMapConflict, desired.Namespace, desired.Name)
+	}
+
+	updated := existing.DeepCopy()
+	updated.Data = desired.Data
+	updated.BinaryData = desired.BinaryData
+	if _, err := client.Update(ctx, updated, metav1.UpdateOptions{DryRun: dryRun}); err != nil {
+		return "", fmt.Errorf("failed to update configmap %s: %w", desired.Name, err)
+	}
+	return ImportActionUpdated, nil
+}
+
+// ValidateImport 以 DryRun 应用清单中的 ConfigMap 和 PVC 并校验容器，清单中包含的 PVC 不要求已存在
+func (s *K8sService) ValidateImport(ctx context.Context, connection *model.K8sConnection, imported *ContainerImport) (*ContainerValidation, error) {
+	result := &ContainerValidation{}
+	if err := result.merge(s.ApplyImportResources(ctx, connection, imported, true)); err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	result, err := s.ValidateContainer(ctx, connection, imported.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]bool)
+	for _, claim := range imported.claims {
+		pending[fmt.Sprintf("%s/%s", VolumeTypePVC, claim.Name)] = true
+	}
+	errs := result.Errors[:0]
+	for _, fieldErr := range result.Errors {
+		if !imported.Request.pendingClaim(fieldErr.Field, pending) {
+			errs = append(errs, fieldErr)
+		}
+	}
+	result.Errors = errs
+	if len(errs) == 0 {
+		result.Errors = nil
+	}
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// pendingClaim 字段错误是否针对导入时才会创建的 PVC
+func (r *CreateContainerRequest) pendingClaim(field string, pending map[string]bool) bool {
+	for i, volume := range r.Volumes {
+		if field == fmt.Sprintf("volumes[%d].source", i) && pending[volume.Type+"/"+volume.Source] {
+			return true
+		}
+	}
+	return false
+}
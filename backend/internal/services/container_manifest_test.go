@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newExportFixtures 平台创建的 Deployment 及其 Service、ConfigMap 和 PVC，带有集群生成的字段
+func newExportFixtures(t *testing.T) (*CreateContainerRequest, []runtime.Object) {
+	t.Helper()
+
+	replicas := int32(2)
+	req := &CreateContainerRequest{
+		Version:   ContainerRequestV2,
+		Name:      "web",
+		Namespace: "default",
+		Image:     "nginx:1.25",
+		Replicas:  &replicas,
+		Kind:      WorkloadDeployment,
+		Ports:     PortList{{Name: "http", ContainerPort: 80, Protocol: "TCP"}},
+		Env: EnvVarList{
+			{Name: "MODE", Value: "production"},
+			{Name: "LEVEL", ValueFrom: &EnvVarSource{Type: EnvSourceConfigMap, Name: "web-config", Key: "level"}},
+			{Name: "TOKEN", ValueFrom: &EnvVarSource{Type: EnvSourceSecret, Name: "web-secret", Key: "token"}},
+		},
+		Resources: &ResourceSpec{CPURequest: "100m", MemoryLimit: "256Mi"},
+		Volumes: []VolumeMountSpec{
+			{Name: "data", Type: VolumeTypePVC, Source: "web-data", MountPath: "/data"},
+			{Name: "config", Type: VolumeTypeConfigMap, Source: "web-config", MountPath: "/etc/web", ReadOnly: true},
+		},
+		HealthSpec: HealthSpec{ReadinessProbe: &ProbeSpec{HandlerSpec: HandlerSpec{Type: ProbeTypeHTTP, Port: 80, Path: "/healthz", Scheme: "HTTP"}, PeriodSeconds: 5}},
+	}
+	workload, err := req.workload()
+	if err != nil {
+		t.Fatalf("workload() error = %v", err)
+	}
+	deployment := workload.(*appsv1.Deployment)
+	deployment.UID = "web-uid"
+	deployment.ResourceVersion = "42"
+	deployment.Generation = 3
+	deployment.Annotations["deployment.kubernetes.io/revision"] = "3"
+	deployment.Status = appsv1.DeploymentStatus{ReadyReplicas: 2}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "default", UID: "config-uid", ResourceVersion: "7"},
+		Data:       map[string]string{"level": "info"},
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web-data", Namespace: "default", Finalizers: []string{"kubernetes.io/pvc-protection"},
+			Annotations: map[string]string{"pv.kubernetes.io/bind-completed": "yes"},
+		},
+		Spec:   corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{managedLabel: managedLabelValue}},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeNodePort,
+			Selector:   map[string]string{appLabel: "web", managedLabel: managedLabelValue},
+			ClusterIP:  "10.96.0.10",
+			ClusterIPs: []string{"10.96.0.10"},
+			Ports:      []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 8080, TargetPort: intstr.FromInt32(80), NodePort: 30080}},
+		},
+	}
+
+	return req, []runtime.Object{deployment, configMap, claim, service}
+}
+
+func TestExportContainer(t *testing.T) {
+	_, objects := newExportFixtures(t)
+	service, _ := newFakeService(t, objects...)
+
+	export, err := service.ExportContainer(context.Background(), newTestConnection(), "default", "web")
+	if err != nil {
+		t.Fatalf("ExportContainer() error = %v", err)
+	}
+
+	wantResources := []string{"ConfigMap/web-config", "PersistentVolumeClaim/web-data", "Deployment/web", "Service/web"}
+	if export.Kind != "Deployment" || !reflect.DeepEqual(export.Resources, wantResources) {
+		t.Errorf("ExportContainer() kind = %s, resources = %v, want %v", export.Kind, export.Resources, wantResources)
+	}
+	if documents := strings.Count(export.Manifest, "\n---\n"); documents != len(wantResources)-1 {
+		t.Errorf("manifest has %d separators, want %d:\n%s", documents, len(wantResources)-1, export.Manifest)
+	}
+	for _, field := range []string{"status:", "uid:", "resourceVersion:", "generation:", "namespace:", "creationTimestamp",
+		"deployment.kubernetes.io/revision", "pvc-protection", "bind-completed", "volumeName", "10.96.0.10", "30080"} {
+		if strings.Contains(export.Manifest, field) {
+			t.Errorf("manifest contains server field %q:\n%s", field, export.Manifest)
+		}
+	}
+	for _, want := range []string{"kind: ConfigMap", "level: info", "kind: PersistentVolumeClaim", "image: nginx:1.25", "type: NodePort", "port: 8080"} {
+		if !strings.Contains(export.Manifest, want) {
+			t.Errorf("manifest does not contain %q:\n%s", want, export.Manifest)
+		}
+	}
+}
+
+func TestExportContainerPod(t *testing.T) {
+	pod := newTestPod("job-pod", "default")
+	pod.Labels = map[string]string{appLabel: "job"}
+	pod.Spec.Volumes = []corev1.Volume{{Name: "kube-api-access-abcde", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{}}}}
+	pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "kube-api-access-abcde", MountPath: "/var/run/secrets/kubernetes.io/serviceaccount"}}
+	service, _ := newFakeService(t, pod)
+
+	export, err := service.ExportContainer(context.Background(), newTestConnection(), "default", "job-pod")
+	if err != nil {
+		t.Fatalf("ExportContainer() error = %v", err)
+	}
+	if !reflect.DeepEqual(export.Resources, []string{"Pod/job-pod"}) {
+		t.Errorf("resources = %v, want only the pod", export.Resources)
+	}
+	for _, field := range []string{"nodeName", "kube-api-access", "volumeMounts"} {
+		if strings.Contains(export.Manifest, field) {
+			t.Errorf("manifest contains %q:\n%s", field, export.Manifest)
+		}
+	}
+}
+
+func TestParseContainerManifestRoundTrip(t *testing.T) {
+	req, objects := newExportFixtures(t)
+	service, _ := newFakeService(t, objects...)
+
+	export, err := service.ExportContainer(context.Background(), newTestConnection(), "default", "web")
+	if err != nil {
+		t.Fatalf("ExportContainer() error = %v", err)
+	}
+	imported, err := ParseContainerManifest(export.Manifest, "staging")
+	if err != nil {
+		t.Fatalf("ParseContainerManifest() error = %v", err)
+	}
+
+	want := *req
+	want.Namespace = "staging"
+	want.Expose = &ExposeSpec{Type: "NodePort", Ports: []ServicePortSpec{{ContainerPort: 80, Protocol: "TCP", ServicePort: 8080}}}
+	if !reflect.DeepEqual(imported.Request, &want) {
+		t.Errorf("imported request = %+v\nwant %+v", imported.Request, &want)
+	}
+	if len(imported.configMaps) != 1 || len(imported.claims) != 1 || len(imported.Warnings) != 0 {
+		t.Errorf("imported configMaps = %d, claims = %d, warnings = %v", len(imported.configMaps), len(imported.claims), imported.Warnings)
+	}
+}
+
+func TestParseContainerManifestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		field    string
+	}{
+		{"no workload", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n", "documents"},
+		{"unsupported kind", "apiVersion: v1\nkind: Secret\nmetadata:\n  name: s\n", "documents[0].kind"},
+		{"unknown field", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: p\nspec:\n  containerz: []\n", "documents[0]"},
+		{"two containers", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: p\nspec:\n  containers:\n  - name: a\n    image: a\n  - name: b\n    image: b\n",
+			"documents[0].spec.containers"},
+		{"field env", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: p\nspec:\n  containers:\n  - name: a\n    image: a\n    env:\n    - name: NODE\n      valueFrom:\n        fieldRef:\n          fieldPath: spec.nodeName\n",
+			"documents[0].spec.containers[0].env[0].valueFrom"},
+		{"service name", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n      - name: web\n        image: nginx\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: api\n",
+			"documents[1].metadata.name"},
+		{"mixed namespaces", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: a\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: p\n  namespace: b\nspec:\n  containers:\n  - name: a\n    image: a\n",
+			"documents[1].metadata.namespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseContainerManifest(tt.manifest, "")
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.field {
+				t.Errorf("ParseContainerManifest() error = %v, want field error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestParseContainerManifestWarnings(t *testing.T) {
+	manifest := `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "worker", "namespace": "jobs", "labels": {"team": "a"}},
+		"spec": {"nodeSelector": {"disk": "ssd"}, "containers": [{"name": "worker", "image": "busybox", "ports": [{"containerPort": 9000, "hostPort": 9000}]}]}}`
+
+	imported, err := ParseContainerManifest(manifest, "")
+	if err != nil {
+		t.Fatalf("ParseContainerManifest() error = %v", err)
+	}
+	if imported.Request.Name != "worker" || imported.Request.Kind != WorkloadPod || imported.Request.Namespace != "jobs" {
+		t.Errorf("imported request = %+v", imported.Request)
+	}
+	want := []string{
+		"documents[0].metadata.name: the pod will be created as worker-pod",
+		"documents[0].metadata.labels: labels team are ignored",
+		"documents[0].spec.containers[0].ports[0].hostPort: is ignored",
+		"documents[0].spec.nodeSelector: is not supported and is ignored",
+	}
+	if !reflect.DeepEqual(imported.Warnings, want) {
+		t.Errorf("warnings = %v\nwant %v", imported.Warnings, want)
+	}
+}
+
+func TestApplyImportResources(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "staging", Labels: map[string]string{managedLabel: managedLabelValue}},
+		Data:       map[string]string{"level": "debug"},
+	}
+	service, clientSet := newFakeService(t, existing)
+
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  level: info
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: web-data
+spec:
+  accessModes: [ReadWriteOnce]
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        volumeMounts:
+        - name: data
+          mountPath: /data
+      volumes:
+      - name: data
+        persistentVolumeClaim:
+          claimName: web-data
+`
+	imported, err := ParseContainerManifest(manifest, "staging")
+	if err != nil {
+		t.Fatalf("ParseContainerManifest() error = %v", err)
+	}
+
+	if err := service.ApplyImportResources(context.Background(), newTestConnection(), imported, false); err != nil {
+		t.Fatalf("ApplyImportResources() error = %v", err)
+	}
+	want := []ImportedResource{
+		{Kind: "ConfigMap", Name: "web-config", Action: ImportActionUpdated},
+		{Kind: "PersistentVolumeClaim", Name: "web-data", Action: ImportActionCreated},
+	}
+	if !reflect.DeepEqual(imported.Resources, want) {
+		t.Errorf("resources = %+v, want %+v", imported.Resources, want)
+	}
+	configMap, err := clientSet.CoreV1().ConfigMaps("staging").Get(context.Background(), "web-config", metav1.GetOptions{})
+	if err != nil || configMap.Data["level"] != "info" {
+		t.Errorf("configmap = %+v, %v, want level=info", configMap, err)
+	}
+
+	// 清单中包含的 PVC 尚不存在时校验也应通过
+	service, _ = newFakeService(t)
+	validation, err := service.ValidateImport(context.Background(), newTestConnection(), imported)
+	if err != nil {
+		t.Fatalf("ValidateImport() error = %v", err)
+	}
+	if !validation.Valid {
+		t.Errorf("ValidateImport() = %+v, want valid", validation)
+	}
+}
+
+func TestApplyImportResourcesUnmanagedConfigMap(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared-config", Namespace: "default"},
+		Data:       map[string]string{"level": "debug"},
+	}
+	service, clientSet := newFakeService(t, existing)
+
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+data:
+  level: info
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx
+`
+	imported, err := ParseContainerManifest(fmt.Sprintf(manifest, "shared-config"), "default")
+	if err != nil {
+		t.Fatalf("ParseContainerManifest() error = %v", err)
+	}
+	if err := service.ApplyImportResources(context.Background(), newTestConnection(), imported, false); !errors.Is(err, ErrConfigMapConflict) {
+		t.Fatalf("ApplyImportResources() error = %v, want ErrConfigMapConflict", err)
+	}
+	configMap, _ := clientSet.CoreV1().ConfigMaps("default").Get(context.Background(), "shared-config", metav1.GetOptions{})
+	if configMap.Data["level"] != "debug" {
+		t.Errorf("unmanaged configmap data = %v, want it unchanged", configMap.Data)
+	}
+
+	// 新建的 ConfigMap 带有平台标签，之后的导入可以更新它
+	imported, err = ParseContainerManifest(fmt.Sprintf(manifest, "web-config"), "default")
+	if err != nil {
+		t.Fatalf("ParseContainerManifest() error = %v", err)
+	}
+	if err := service.ApplyImportResources(context.Background(), newTestConnection(), imported, false); err != nil {
+		t.Fatalf("ApplyImportResources() error = %v", err)
+	}
+	configMap, _ = clientSet.CoreV1().ConfigMaps("default").Get(context.Background(), "web-config", metav1.GetOptions{})
+	if configMap.Labels[managedLabel] != managedLabelValue {
+		t.Errorf("created configmap labels = %v, want the managed label", configMap.Labels)
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"container-platform-backend/internal/model"
+)
+
+// ContainerRecordService 记录平台创建的容器及其存储卷挂载、端口映射和环境变量
+type ContainerRecordService struct {
+	db *gorm.DB
+}
+
+// NewContainerRecordService 创建容器记录服务
+func NewContainerRecordService(db *gorm.DB) *ContainerRecordService {
+	return &ContainerRecordService{db: db}
+}
+
+// RecordCreated 记录新创建的容器及其存储卷挂载、端口映射和环境变量
+// 同一连接和命名空间下同名的旧记录对应的工作负载已不存在（否则创建会冲突），会被软删除
+func (s *ContainerRecordService) RecordCreated(ctx context.Context, connection *model.K8sConnection, req *CreateContainerRequest) (*model.Container, error) {
+	var createdBy *uint
+	if user, ok := PlatformUserFromContext(ctx); ok && user.ID != 0 {
+		createdBy = &user.ID
+	}
+
+	container := &model.Container{
+		Name:         req.Name,
+		ConnectionID: &connection.ID,
+		K8sName:      req.WorkloadName(),
+		Status:       model.ContainerStatusPending,
+		CreatedBy:    createdBy,
+	}
+	if req.Kind == WorkloadPod {
+		container.PodName = container.K8sName
+	} else {
+		container.DeploymentName = container.K8sName
+	}
+	if req.Resources != nil {
+		container.CPURequest = req.Resources.CPURequest
+		container.CPULimit = req.Resources.CPULimit
+		container.MemoryRequest = req.Resources.MemoryRequest
+		container.MemoryLimit = req.Resources.MemoryLimit
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		namespace, err := ensureNamespaceRecord(tx, connection, req.Namespace, createdBy)
+		if err != nil {
+			return err
+		}
+		image, err := ensureImageRecord(tx, req.Image)
+		if err != nil {
+			return err
+		}
+		container.NamespaceID = namespace.ID
+		container.ImageID = image.ID
+
+		stale := tx.Model(&model.Container{}).Select("id").
+			Where("connection_id = ? AND namespace_id = ? AND k8s_name = ?", connection.ID, namespace.ID, container.K8sName)
+		if err := deleteContainerRecords(tx, stale); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Create(container).Error; err != nil {
+			return fmt.Errorf("failed to create container record: %w", err)
+		}
+		container.Namespace = *namespace
+		container.Image = *image
+
+		volumeIDs := make(map[string]uint)
+		for _, spec := range req.Volumes {
+			volumeID, ok := volumeIDs[spec.Name]
+			if !ok {
+				volume, err := ensureVolumeRecord(tx, namespace.ID, spec, createdBy)
+				if err != nil {
+					return err
+				}
+				volumeID = volume.ID
+				volumeIDs[spec.Name] = volumeID
+			}
+
+			mount := &model.ContainerVolume{
+				ContainerID: container.ID,
+				VolumeID:    volumeID,
+				MountPath:   spec.MountPath,
+				ReadOnly:    spec.ReadOnly,
+				SubPath:     spec.SubPath,
+			}
+			if err := tx.Omit(clause.Associations).Create(mount).Error; err != nil {
+				return fmt.Errorf("failed to create container volume record: %w", err)
+			}
+			container.Volumes = append(container.Volumes, *mount)
+		}
+
+		for _, port := range req.Ports {
+			mapping := &model.PortMapping{
+				ContainerID:   container.ID,
+				Name:          port.Name,
+				ContainerPort: int(port.ContainerPort),
+				Protocol:      string(portProtocol(port.Protocol)),
+			}
+			if err := tx.Omit(clause.Associations).Create(mapping).Error; err != nil {
+				return fmt.Errorf("failed to create port mapping record: %w", err)
+			}
+			container.PortMappings = append(container.PortMappings, *mapping)
+		}
+
+		for _, env := range req.Env {
+			envVar := &model.EnvironmentVariable{
+				ContainerID: container.ID,
+				Name:        env.Name,
+				Value:       env.Value,
+			}
+			if source := env.ValueFrom; source != nil {
+				envVar.ValueFrom = source.Type
+				envVar.SourceName = source.Name
+				envVar.SourceKey = source.Key
+			}
+			if err := tx.Omit(clause.Associations).Create(envVar).Error; err != nil {
+				return fmt.Errorf("failed to create environment variable record: %w", err)
+			}
+			container.EnvVars = append(container.EnvVars, *envVar)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return container, nil
+}
+
+// RecordExposure 将 Service 名称、类型、ClusterIP 和节点端口写回容器的端口映射
+// 容器不是由平台创建时没有记录，直接忽略
+func (s *ContainerRecordService) RecordExposure(ctx context.Context, connection *model.K8sConnection, exposure *ServiceExposure) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		container, err := findContainerRecord(tx, connection.ID, exposure.Namespace, exposure.WorkloadName)
+		if err != nil || container == nil {
+			return err
+		}
+
+		var mappings []model.PortMapping
+		if err := tx.Where("container_id = ?", container.ID).Find(&mappings).Error; err != nil {
+			return fmt.Errorf("failed to get port mappings: %w", err)
+		}
+
+		exposed := make(map[string]ExposedPort)
+		for _, port := range exposure.Ports {
+			exposed[fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol)] = port
+		}
+
+		for i := range mappings {
+			mapping := &mappings[i]
+			key := fmt.Sprintf("%d/%s", mapping.ContainerPort, mapping.Protocol)
+			port, ok := exposed[key]
+			delete(exposed, key)
+
+			updates := map[string]interface{}{
+				"service_name": "", "service_type": "", "cluster_ip": "", "service_port": nil, "node_port": nil,
+			}
+			if ok {
+				updates["service_name"] = exposure.ServiceName
+				updates["service_type"] = exposure.Type
+				updates["cluster_ip"] = exposure.ClusterIP
+				updates["service_port"] = int(port.ServicePort)
+				if port.NodePort != 0 {
+					updates["node_port"] = int(port.NodePort)
+				}
+			}
+			if err := tx.Model(mapping).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update port mapping: %w", err)
+			}
+		}
+
+		// 容器声明了但尚未记录的端口
+		for _, port := range exposure.Ports {
+			if _, ok := exposed[fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol)]; !ok {
+				continue
+			}
+			servicePort := int(port.ServicePort)
+			mapping := &model.PortMapping{
+				ContainerID:   container.ID,
+				Name:          port.Name,
+				ContainerPort: int(port.ContainerPort),
+				Protocol:      port.Protocol,
+				ServiceName:   exposure.ServiceName,
+				ServiceType:   exposure.Type,
+				ClusterIP:     exposure.ClusterIP,
+				ServicePort:   &servicePort,
+			}
+			if port.NodePort != 0 {
+				nodePort := int(port.NodePort)
+				mapping.NodePort = &nodePort
+			}
+			if err := tx.Omit(clause.Associations).Create(mapping).Error; err != nil {
+				return fmt.Errorf("failed to create port mapping record: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// RecordPaused 将容器记录标记为已暂停并记录暂停时间
+// 容器不是由平台创建时没有记录，直接忽略
+func (s *ContainerRecordService) RecordPaused(ctx context.Context, connection *model.K8sConnection, namespace, workloadName string) error {
+	now := time.Now()
+	return s.updateStatus(ctx, connection, namespace, workloadName, map[string]interface{}{
+		"status":    model.ContainerStatusPaused,
+		"paused_at": &now,
+	})
+}
+
+// RecordResumed 将已恢复容器的记录改回运行状态并清除暂停时间
+func (s *ContainerRecordService) RecordResumed(ctx context.Context, connection *model.K8sConnection, namespace, workloadName string) error {
+	return s.updateStatus(ctx, connection, namespace, workloadName, map[string]interface{}{
+		"status":    model.ContainerStatusRunning,
+		"paused_at": nil,
+	})
+}
+
+// updateStatus 更新工作负载对应的容器记录的状态字段
+func (s *ContainerRecordService) updateStatus(ctx context.Context, connection *model.K8sConnection, namespace, workloadName string, updates map[string]interface{}) error {
+	if user, ok := PlatformUserFromContext(ctx); ok && user.ID != 0 {
+		updates["updated_by"] = user.ID
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		container, err := findContainerRecord(tx, connection.ID, namespace, workloadName)
+		if err != nil || container == nil {
+			return err
+		}
+		if err := tx.Model(container).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update container status: %w", err)
+		}
+		return nil
+	})
+}
+
+// RecordDeleted 软删除已删除工作负载的容器记录及其存储卷挂载、端口映射、环境变量和镜像修订
+func (s *ContainerRecordService) RecordDeleted(ctx context.Context, connection *model.K8sConnection, namespace, workloadName string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("connection_id = ? AND namespace = ? AND workload_name = ?", connection.ID, namespace, workloadName).
+			Delete(&model.ContainerRevision{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete container revisions: %w", err)
+		}
+
+		container, err := findContainerRecord(tx, connection.ID, namespace, workloadName)
+		if err != nil || container == nil {
+			return err
+		}
+		return deleteContainerRecords(tx, []uint{container.ID})
+	})
+}
+
+// findContainerRecord 按连接、命名空间和工作负载名称查找容器记录，不存在时返回 nil
+func findContainerRecord(tx *gorm.DB, connectionID uint, namespace, k8sName string) (*model.Container, error) {
+	var container model.Container
+	err := tx.Joins("JOIN namespaces ON namespaces.id = containers.namespace_id").
+		Where("containers.connection_id = ? AND namespaces.name = ? AND containers.k8s_name = ?", connectionID, namespace, k8sName).
+		Order("containers.id DESC").
+		First(&container).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container record: %w", err)
+	}
+	return &container, nil
+}
+
+// deleteContainerRecords 软删除容器记录及其关联的存储卷挂载、端口映射和环境变量，ids 可以是子查询
+func deleteContainerRecords(tx *gorm.DB, ids interface{}) error {
+	if err := tx.Where("container_id IN (?)", ids).Delete(&model.ContainerVolume{}).Error; err != nil {
+		return fmt.Errorf("failed to delete container volume records: %w", err)
+	}
+	if err := tx.Where("container_id IN (?)", ids).Delete(&model.PortMapping{}).Error; err != nil {
+		return fmt.Errorf("failed to delete port mapping records: %w", err)
+	}
+	if err := tx.Where("container_id IN (?)", ids).Delete(&model.EnvironmentVariable{}).Error; err != nil {
+		return fmt.Errorf("failed to delete environment variable records: %w", err)
+	}
+	if err := tx.Where("id IN (?)", ids).Delete(&model.Container{}).Error; err != nil {
+		return fmt.Errorf("failed to delete container records: %w", err)
+	}
+	return nil
+}
+
+// ensureNamespaceRecord 获取或创建命名空间记录
+func ensureNamespaceRecord(tx *gorm.DB, connection *model.K8sConnection, name string, createdBy *uint) (*model.Namespace, error) {
+	var namespace model.Namespace
+	err := tx.Where("name = ?", name).First(&namespace).Error
+	if err == nil {
+		return &namespace, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get namespace record: %w", err)
+	}
+
+	namespace = model.Namespace{
+		Name:        name,
+		K8sName:     name,
+		ClusterName: connection.Name,
+		CreatedBy:   createdBy,
+	}
+	if err := tx.Omit(clause.Associations).Create(&namespace).Error; err != nil {
+		return nil, fmt.Errorf("failed to create namespace record: %w", err)
+	}
+	return &namespace, nil
+}
+
+// ensureImageRecord 获取或创建镜像记录
+func ensureImageRecord(tx *gorm.DB, reference string) (*model.ContainerImage, error) {
+	name, tag, digest := splitImageReference(reference)
+
+	var image model.ContainerImage
+	err := tx.Where("name = ? AND tag = ? AND digest = ?", name, tag, digest).First(&image).Error
+	if err == nil {
+		return &image, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get image record: %w", err)
+	}
+
+	image = model.ContainerImage{Name: name, Tag: tag, Digest: digest, PulledAt: time.Now()}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		image.Repository = name[:i]
+	}
+	if err := tx.Create(&image).Error; err != nil {
+		return nil, fmt.Errorf("failed to create image record: %w", err)
+	}
+	return &image, nil
+}
+
+// ensureVolumeRecord 获取或创建存储卷记录
+// PVC、ConfigMap 和 Secret 按名称复用已有记录，emptyDir 和 hostPath 随容器创建
+func ensureVolumeRecord(tx *gorm.DB, namespaceID uint, spec VolumeMountSpec, createdBy *uint) (*model.Volume, error) {
+	volume := model.Volume{
+		Name:        spec.Name,
+		NamespaceID: namespaceID,
+		Type:        spec.Type,
+		CreatedBy:   createdBy,
+	}
+
+	switch spec.Type {
+	case VolumeTypePVC, VolumeTypeConfigMap, VolumeTypeSecret:
+		volume.Name = spec.Source
+		volume.K8sName = spec.Source
+
+		var existing model.Volume
+		err := tx.Where("namespace_id = ? AND type = ? AND k8s_name = ?", namespaceID, spec.Type, spec.Source).First(&existing).Error
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get volume record: %w", err)
+		}
+	case VolumeTypeEmptyDir:
+		volume.Size = spec.SizeLimit
+	case VolumeTypeHostPath:
+		volume.HostPath = spec.HostPath
+	}
+
+	if err := tx.Omit(clause.Associations).Create(&volume).Error; err != nil {
+		return nil, fmt.Errorf("failed to create volume record: %w", err)
+	}
+	return &volume, nil
+}
+
+// splitImageReference 将镜像引用拆分为名称、标签和摘要，未指定标签和摘要时标签为 latest
+func splitImageReference(reference string) (string, string, string) {
+	var digest string
+	if i := strings.Index(reference, "@"); i >= 0 {
+		reference, digest = reference[:i], reference[i+1:]
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:], digest
+	}
+	if digest != "" {
+		return reference, "", digest
+	}
+	return reference, "latest", ""
+}
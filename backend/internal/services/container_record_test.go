@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"container-platform-backend/internal/model"
+)
+
+// recordedStatement 执行过的语句
+type recordedStatement struct {
+	query string
+	args  []driver.NamedValue
+}
+
+// recordingDriver 记录所有语句的 database/sql 驱动，查询返回预设的结果
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []recordedStatement
+	// 查询容器记录时返回的 id，为 0 时没有记录
+	containerID int64
+	// 查询返回的结果，为 nil 时按 containerID 返回容器记录
+	results func(query string) *recordingRows
+	// 包含该内容的语句执行失败
+	fail string
+}
+
+func (d *recordingDriver) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *recordingDriver) Driver() driver.Driver                        { return nil }
+func (d *recordingDriver) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (d *recordingDriver) Close() error                                 { return nil }
+func (d *recordingDriver) Begin() (driver.Tx, error)                    { return d, nil }
+func (d *recordingDriver) Commit() error                                { return nil }
+func (d *recordingDriver) Rollback() error                              { return nil }
+
+func (d *recordingDriver) record(query string, args []driver.NamedValue) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, recordedStatement{query: query, args: args})
+	if d.fail != "" && strings.Contains(query, d.fail) {
+		return errors.New("statement failed")
+	}
+	return nil
+}
+
+func (d *recordingDriver) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := d.record(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (d *recordingDriver) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := d.record(query, args); err != nil {
+		return nil, err
+	}
+	if d.results != nil {
+		if rows := d.results(query); rows != nil {
+			return rows, nil
+		}
+		return &recordingRows{}, nil
+	}
+	rows := &recordingRows{}
+	if d.containerID != 0 {
+		rows.values = [][]driver.Value{{d.containerID, model.ContainerStatusRunning}}
+	}
+	return rows, nil
+}
+
+// matching 返回以 prefix 开头的语句
+func (d *recordingDriver) matching(prefix string) []recordedStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var matched []recordedStatement
+	for _, statement := range d.statements {
+		if strings.HasPrefix(statement.query, prefix) {
+			matched = append(matched, statement)
+		}
+	}
+	return matched
+}
+
+// recordingRows 查询结果，columns 为 nil 时为容器记录的 id 和 status
+type recordingRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *recordingRows) Columns() []string {
+	if r.columns == nil {
+		return []string{"id", "status"}
+	}
+	return r.columns
+}
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newRecordingDB(t *testing.T, containerID int64) (*gorm.DB, *recordingDriver) {
+	t.Helper()
+
+	recorder := &recordingDriver{containerID: containerID}
+	sqlDB := sql.OpenDB(recorder)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db, recorder
+}
+
+// namedArg 返回语句中第一个满足条件的参数
+func namedArg(args []driver.NamedValue, match func(driver.Value) bool) (driver.Value, bool) {
+	for _, arg := range args {
+		if match(arg.Value) {
+			return arg.Value, true
+		}
+	}
+	return nil, false
+}
+
+func TestRecordPausedAndResumed(t *testing.T) {
+	db, recorder := newRecordingDB(t, 42)
+	records := NewContainerRecordService(db)
+	connection := newTestConnection()
+
+	if err := records.RecordPaused(context.Background(), connection, "default", "web"); err != nil {
+		t.Fatalf("RecordPaused() error = %v", err)
+	}
+	if err := records.RecordResumed(context.Background(), connection, "default", "web"); err != nil {
+		t.Fatalf("RecordResumed() error = %v", err)
+	}
+
+	updates := recorder.matching("UPDATE")
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2: %+v", len(updates), updates)
+	}
+
+	paused := updates[0]
+	if !strings.Contains(paused.query, `UPDATE "containers" SET "paused_at"=$1,"status"=$2`) {
+		t.Errorf("pause update = %s", paused.query)
+	}
+	if paused.args[1].Value != model.ContainerStatusPaused {
+		t.Errorf("status = %v, want paused", paused.args[1].Value)
+	}
+	if _, ok := paused.args[0].Value.(time.Time); !ok {
+		t.Errorf("paused_at = %v, want a timestamp", paused.args[0].Value)
+	}
+	if _, ok := namedArg(paused.args, func(v driver.Value) bool { return v == int64(42) }); !ok {
+		t.Errorf("pause update should target container 42: %+v", paused.args)
+	}
+
+	resumed := updates[1]
+	if !strings.Contains(resumed.query, `UPDATE "containers" SET "paused_at"=$1,"status"=$2`) {
+		t.Errorf("resume update = %s", resumed.query)
+	}
+	if resumed.args[0].Value != nil || resumed.args[1].Value != model.ContainerStatusRunning {
+		t.Errorf("resume args = %v, %v, want nil paused_at and running", resumed.args[0].Value, resumed.args[1].Value)
+	}
+}
+
+func TestRecordPausedWithoutRecord(t *testing.T) {
+	db, recorder := newRecordingDB(t, 0)
+	records := NewContainerRecordService(db)
+
+	if err := records.RecordPaused(context.Background(), newTestConnection(), "default", "external"); err != nil {
+		t.Fatalf("RecordPaused() error = %v", err)
+	}
+	if updates := recorder.matching("UPDATE"); len(updates) != 0 {
+		t.Errorf("containers not created by the platform should not be updated: %+v", updates)
+	}
+}
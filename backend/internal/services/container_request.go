@@ -0,0 +1,549 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// 创建容器请求的版本
+const (
+	// ContainerRequestV1 旧版请求：command、env、ports、resources 均为字符串
+	ContainerRequestV1 = "v1"
+	// ContainerRequestV2 结构化请求
+	ContainerRequestV2 = "v2"
+)
+
+// 环境变量引用来源
+const (
+	EnvSourceSecret    = "secret"
+	EnvSourceConfigMap = "configMap"
+)
+
+// CreateContainerRequest 创建容器请求
+// 同时兼容旧版字符串格式：command 为空格分隔的字符串，env 为按行分隔的 KEY=value，
+// ports 为逗号分隔的端口号，resources 为 "cpu: 500m, memory: 256Mi"（仅设置 requests）。
+type CreateContainerRequest struct {
+	// 请求版本：v1 或 v2，为空时根据字段格式自动识别
+	Version      string `json:"version,omitempty"`
+	ConnectionID uint   `json:"connectionId,omitempty"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Image        string `json:"image"`
+	// 覆盖镜像的 ENTRYPOINT
+	Command StringList `json:"command,omitempty" swaggertype:"array,string"`
+	// 覆盖镜像的 CMD
+	Args      StringList    `json:"args,omitempty" swaggertype:"array,string"`
+	Ports     PortList      `json:"ports,omitempty"`
+	Env       EnvVarList    `json:"env,omitempty"`
+	Resources *ResourceSpec `json:"resources,omitempty"`
+	// 存储卷挂载：pvc、configMap、secret、emptyDir 或 hostPath
+	Volumes []VolumeMountSpec `json:"volumes,omitempty"`
+	// 创建后通过同名 Service 暴露端口，为空时不创建 Service
+	Expose *ExposeSpec `json:"expose,omitempty"`
+	// 健康检查探针、生命周期钩子和优雅退出时间
+	HealthSpec
+	// 工作负载类型：deployment（默认）或 pod
+	Kind string `json:"kind,omitempty"`
+	// Deployment 副本数，默认为 1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// 使用旧版字符串格式的字段
+	legacyFields []string
+}
+
+// EnvVarSpec 环境变量，value 与 valueFrom 二选一
+type EnvVarSpec struct {
+	Name      string        `json:"name"`
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource 从 Secret 或 ConfigMap 的键读取环境变量
+type EnvVarSource struct {
+	// 来源类型：secret 或 configMap
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// PortSpec 容器端口
+type PortSpec struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	// 协议：TCP（默认）、UDP 或 SCTP
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// ResourceSpec 容器资源请求与限制
+type ResourceSpec struct {
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+}
+
+// FieldError 字段级校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors 请求校验失败的字段列表，可通过 errors.Is 匹配 ErrInvalidContainerRequest
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return ErrInvalidContainerRequest.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() error {
+	return ErrInvalidContainerRequest
+}
+
+func (e *ValidationErrors) add(field, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// StringList 字符串数组，兼容空格分隔的旧版字符串
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*l = strings.Fields(value)
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// EnvVarList 环境变量列表，兼容按行分隔的 KEY=value 字符串
+type EnvVarList []EnvVarSpec
+
+func (l *EnvVarList) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		env, err := parseEnvVars(value)
+		if err != nil {
+			return err
+		}
+		*l = env
+		return nil
+	}
+
+	var values []EnvVarSpec
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+// PortList 端口列表，兼容逗号分隔的端口号字符串
+type PortList []PortSpec
+
+func (l *PortList) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		ports, err := parsePortMappings(value)
+		if err != nil {
+			return err
+		}
+		*l = ports
+		return nil
+	}
+
+	var values []PortSpec
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+func (r *ResourceSpec) UnmarshalJSON(data []byte) error {
+	if isJSONString(data) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*r = parseResources(value)
+		return nil
+	}
+
+	type plain ResourceSpec
+	var spec plain
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return err
+	}
+	*r = ResourceSpec(spec)
+	return nil
+}
+
+// UnmarshalJSON 解析请求并记录使用旧版字符串格式的字段，未指定版本时据此识别版本
+func (r *CreateContainerRequest) UnmarshalJSON(data []byte) error {
+	type plain CreateContainerRequest
+	var req plain
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*r = CreateContainerRequest(req)
+	for _, field := range []string{"command", "args", "env", "ports", "resources"} {
+		if isJSONString(raw[field]) {
+			r.legacyFields = append(r.legacyFields, field)
+		}
+	}
+	if r.Version == "" {
+		r.Version = ContainerRequestV2
+		if len(r.legacyFields) > 0 {
+			r.Version = ContainerRequestV1
+		}
+	}
+	return nil
+}
+
+// Validate 校验请求并返回全部字段错误
+func (r *CreateContainerRequest) Validate() error {
+	var errs ValidationErrors
+
+	switch r.Version {
+	case "", ContainerRequestV1:
+	case ContainerRequestV2:
+		for _, field := range r.legacyFields {
+			errs.add(field, "string form is only accepted in version %s", ContainerRequestV1)
+		}
+	default:
+		errs.add("version", "unsupported version %q, must be %s or %s", r.Version, ContainerRequestV1, ContainerRequestV2)
+	}
+
+	if r.Name == "" {
+		errs.add("name", "is required")
+	} else {
+		for _, msg := range validation.IsDNS1123Label(r.Name) {
+			errs.add("name", "%s", msg)
+		}
+	}
+	if r.Namespace == "" {
+		errs.add("namespace", "is required")
+	} else {
+		for _, msg := range validation.IsDNS1123Label(r.Namespace) {
+			errs.add("namespace", "%s", msg)
+		}
+	}
+	if strings.TrimSpace(r.Image) == "" {
+		errs.add("image", "is required")
+	}
+
+	switch r.Kind {
+	case "", WorkloadDeployment:
+		if r.Replicas != nil && *r.Replicas < 0 {
+			errs.add("replicas", "must not be negative")
+		}
+	case WorkloadPod:
+	default:
+		errs.add("kind", "unsupported kind %q, must be %s or %s", r.Kind, WorkloadDeployment, WorkloadPod)
+	}
+
+	r.validateEnv(&errs)
+	r.validatePorts(&errs)
+	r.validateVolumes(&errs)
+	r.HealthSpec.validate(&errs, "")
+	if r.Expose != nil {
+		if r.Name != "" {
+			for _, msg := range validation.IsDNS1035Label(r.Name) {
+				errs.add("name", "%s (required for the service name)", msg)
+			}
+		}
+		if len(r.Ports) == 0 {
+			errs.add("expose", "requires at least one port")
+		} else {
+			r.Expose.validate(&errs, "expose.", r.Ports)
+		}
+	}
+	if r.Resources != nil {
+		if _, resourceErrs := r.Resources.requirements(); resourceErrs != nil {
+			errs = append(errs, resourceErrs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (r *CreateContainerRequest) validateEnv(errs *ValidationErrors) {
+	seen := make(map[string]bool)
+	for i, env := range r.Env {
+		field := fmt.Sprintf("env[%d]", i)
+		if env.Name == "" {
+			errs.add(field+".name", "is required")
+		} else {
+			for _, msg := range validation.IsEnvVarName(env.Name) {
+				errs.add(field+".name", "%s", msg)
+			}
+			if seen[env.Name] {
+				errs.add(field+".name", "duplicate environment variable %q", env.Name)
+			}
+			seen[env.Name] = true
+		}
+
+		source := env.ValueFrom
+		if source == nil {
+			continue
+		}
+		if env.Value != "" {
+			errs.add(field, "value and valueFrom are mutually exclusive")
+		}
+		if source.Type != EnvSourceSecret && source.Type != EnvSourceConfigMap {
+			errs.add(field+".valueFrom.type", "unsupported type %q, must be %s or %s", source.Type, EnvSourceSecret, EnvSourceConfigMap)
+		}
+		if source.Name == "" {
+			errs.add(field+".valueFrom.name", "is required")
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(source.Name) {
+				errs.add(field+".valueFrom.name", "%s", msg)
+			}
+		}
+		if source.Key == "" {
+			errs.add(field+".valueFrom.key", "is required")
+		} else {
+			for _, msg := range validation.IsConfigMapKey(source.Key) {
+				errs.add(field+".valueFrom.key", "%s", msg)
+			}
+		}
+	}
+}
+
+func (r *CreateContainerRequest) validatePorts(errs *ValidationErrors) {
+	seenPorts := make(map[string]bool)
+	seenNames := make(map[string]bool)
+	for i, port := range r.Ports {
+		field := fmt.Sprintf("ports[%d]", i)
+		for _, msg := range validation.IsValidPortNum(int(port.ContainerPort)) {
+			errs.add(field+".containerPort", "%s", msg)
+		}
+
+		protocol := portProtocol(port.Protocol)
+		switch protocol {
+		case corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP:
+		default:
+			errs.add(field+".protocol", "unsupported protocol %q, must be TCP, UDP or SCTP", port.Protocol)
+		}
+
+		key := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+		if seenPorts[key] {
+			errs.add(field+".containerPort", "duplicate port %s", key)
+		}
+		seenPorts[key] = true
+
+		if port.Name == "" {
+			continue
+		}
+		for _, msg := range validation.IsValidPortName(port.Name) {
+			errs.add(field+".name", "%s", msg)
+		}
+		if seenNames[port.Name] {
+			errs.add(field+".name", "duplicate port name %q", port.Name)
+		}
+		seenNames[port.Name] = true
+	}
+}
+
+// WorkloadName 创建的工作负载名称，独立 Pod 使用 -pod 后缀
+func (r *CreateContainerRequest) WorkloadName() string {
+	if r.Kind == WorkloadPod {
+		return r.Name + "-pod"
+	}
+	return r.Name
+}
+
+// container 根据已校验的请求构建容器定义
+func (r *CreateContainerRequest) container() (corev1.Container, error) {
+	container := corev1.Container{
+		Name:    r.Name,
+		Image:   r.Image,
+		Command: r.Command,
+		Args:    r.Args,
+	}
+
+	for _, env := range r.Env {
+		envVar := corev1.EnvVar{Name: env.Name, Value: env.Value}
+		if source := env.ValueFrom; source != nil {
+			optional := source.Optional
+			switch source.Type {
+			case EnvSourceSecret:
+				envVar.ValueFrom = &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: source.Name},
+					Key:                  source.Key,
+					Optional:             &optional,
+				}}
+			case EnvSourceConfigMap:
+				envVar.ValueFrom = &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: source.Name},
+					Key:                  source.Key,
+					Optional:             &optional,
+				}}
+			}
+		}
+		container.Env = append(container.Env, envVar)
+	}
+
+	for _, port := range r.Ports {
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.ContainerPort,
+			Protocol:      portProtocol(port.Protocol),
+		})
+	}
+
+	if r.Resources != nil {
+		requirements, errs := r.Resources.requirements()
+		if errs != nil {
+			return corev1.Container{}, errs
+		}
+		container.Resources = requirements
+	}
+
+	return container, nil
+}
+
+// requirements 解析资源数量，并检查 request 不超过 limit
+func (r *ResourceSpec) requirements() (corev1.ResourceRequirements, ValidationErrors) {
+	var errs ValidationErrors
+	requirements := corev1.ResourceRequirements{}
+
+	parse := func(field, value string, list *corev1.ResourceList, name corev1.ResourceName) {
+		if value == "" {
+			return
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+		if err != nil {
+			errs.add("resources."+field, "invalid quantity %q", value)
+			return
+		}
+		if quantity.Sign() < 0 {
+			errs.add("resources."+field, "must not be negative")
+			return
+		}
+		if *list == nil {
+			*list = make(corev1.ResourceList)
+		}
+		(*list)[name] = quantity
+	}
+
+	parse("cpuRequest", r.CPURequest, &requirements.Requests, corev1.ResourceCPU)
+	parse("memoryRequest", r.MemoryRequest, &requirements.Requests, corev1.ResourceMemory)
+	parse("cpuLimit", r.CPULimit, &requirements.Limits, corev1.ResourceCPU)
+	parse("memoryLimit", r.MemoryLimit, &requirements.Limits, corev1.ResourceMemory)
+
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		field := string(name) + "Request"
+		request, hasRequest := requirements.Requests[name]
+		limit, hasLimit := requirements.Limits[name]
+		if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+			errs.add("resources."+field, "must be less than or equal to the %s limit", name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return corev1.ResourceRequirements{}, errs
+	}
+	return requirements, nil
+}
+
+// portProtocol 规范化端口协议，默认为 TCP
+func portProtocol(protocol string) corev1.Protocol {
+	if protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return corev1.Protocol(strings.ToUpper(protocol))
+}
+
+func isJSONString(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '"'
+}
+
+// parseEnvVars 解析旧版按行分隔的 KEY=value 环境变量
+func parseEnvVars(envStr string) (EnvVarList, error) {
+	var env EnvVarList
+	for i, line := range strings.Split(envStr, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, ValidationErrors{{Field: "env", Message: fmt.Sprintf("line %d: expected KEY=value", i+1)}}
+		}
+		env = append(env, EnvVarSpec{Name: strings.TrimSpace(parts[0]), Value: strings.TrimSpace(parts[1])})
+	}
+	return env, nil
+}
+
+// parsePortMappings 解析旧版逗号分隔的端口号
+func parsePortMappings(portsStr string) (PortList, error) {
+	var ports PortList
+	for _, part := range strings.Split(portsStr, ",") {
+		portStr := strings.TrimSpace(part)
+		if portStr == "" {
+			continue
+		}
+		port, err := strconv.ParseInt(portStr, 10, 32)
+		if err != nil {
+			return nil, ValidationErrors{{Field: "ports", Message: fmt.Sprintf("invalid port %q", portStr)}}
+		}
+		ports = append(ports, PortSpec{ContainerPort: int32(port)})
+	}
+	return ports, nil
+}
+
+// parseResources 解析旧版 "cpu: 500m, memory: 256Mi" 格式，仅设置 requests，数量在校验时解析
+func parseResources(resourcesStr string) ResourceSpec {
+	var spec ResourceSpec
+	for _, part := range strings.Split(resourcesStr, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "cpu":
+			spec.CPURequest = strings.TrimSpace(value)
+		case "memory":
+			spec.MemoryRequest = strings.TrimSpace(value)
+		}
+	}
+	return spec
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateContainerRequestLegacyJSON(t *testing.T) {
+	body := `{
+		"name": "web",
+		"namespace": "default",
+		"image": "nginx",
+		"command": "nginx -g daemon",
+		"ports": "80, 443",
+		"env": "MODE=production\nDSN = user=admin\n\n",
+		"resources": "cpu: 500m, memory: 256Mi, gpu: 1"
+	}`
+
+	var req CreateContainerRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if req.Version != ContainerRequestV1 {
+		t.Errorf("Version = %q, want %q", req.Version, ContainerRequestV1)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(req.Command, StringList{"nginx", "-g", "daemon"}) {
+		t.Errorf("Command = %v", req.Command)
+	}
+	if !reflect.DeepEqual(req.Ports, PortList{{ContainerPort: 80}, {ContainerPort: 443}}) {
+		t.Errorf("Ports = %v", req.Ports)
+	}
+	wantEnv := EnvVarList{{Name: "MODE", Value: "production"}, {Name: "DSN", Value: "user=admin"}}
+	if !reflect.DeepEqual(req.Env, wantEnv) {
+		t.Errorf("Env = %v, want %v", req.Env, wantEnv)
+	}
+	if req.Resources == nil || *req.Resources != (ResourceSpec{CPURequest: "500m", MemoryRequest: "256Mi"}) {
+		t.Errorf("Resources = %+v", req.Resources)
+	}
+}
+
+func TestCreateContainerRequestStructuredJSON(t *testing.T) {
+	body := `{
+		"name": "web",
+		"namespace": "default",
+		"image": "nginx",
+		"command": ["sh", "-c"],
+		"args": ["echo hello world"],
+		"ports": [{"name": "dns", "containerPort": 53, "protocol": "udp"}],
+		"env": [{"name": "LEVEL", "valueFrom": {"type": "configMap", "name": "settings", "key": "level"}}],
+		"resources": {"cpuRequest": "100m", "cpuLimit": "200m"}
+	}`
+
+	var req CreateContainerRequest
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if req.Version != ContainerRequestV2 {
+		t.Errorf("Version = %q, want %q", req.Version, ContainerRequestV2)
+	}
+
+	container, err := req.container()
+	if err != nil {
+		t.Fatalf("container() error = %v", err)
+	}
+	if !reflect.DeepEqual(container.Args, []string{"echo hello world"}) {
+		t.Errorf("Args = %v", container.Args)
+	}
+	if container.Ports[0].Protocol != corev1.ProtocolUDP || container.Ports[0].Name != "dns" {
+		t.Errorf("Ports = %v", container.Ports)
+	}
+	if ref := container.Env[0].ValueFrom; ref == nil || ref.ConfigMapKeyRef == nil || ref.ConfigMapKeyRef.Key != "level" {
+		t.Errorf("Env = %+v", container.Env)
+	}
+	if _, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+		t.Errorf("unexpected memory request: %v", container.Resources.Requests)
+	}
+}
+
+func TestCreateContainerRequestLegacyParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"invalid port", `{"ports": "80,http"}`, "ports"},
+		{"env without value", `{"env": "A=1\ninvalid"}`, "env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateContainerRequest
+			err := json.Unmarshal([]byte(tt.body), &req)
+
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.field {
+				t.Errorf("Unmarshal() error = %v, want field error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestCreateContainerRequestValidate(t *testing.T) {
+	valid := func() *CreateContainerRequest {
+		return &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx"}
+	}
+	negative := int32(-1)
+
+	tests := []struct {
+		name   string
+		modify func(*CreateContainerRequest)
+		fields []string
+	}{
+		{"valid", func(*CreateContainerRequest) {}, nil},
+		{"missing fields", func(r *CreateContainerRequest) { *r = CreateContainerRequest{} }, []string{"name", "namespace", "image"}},
+		{"invalid name", func(r *CreateContainerRequest) { r.Name = "Web_App" }, []string{"name"}},
+		{"unknown version", func(r *CreateContainerRequest) { r.Version = "v3" }, []string{"version"}},
+		{"negative replicas", func(r *CreateContainerRequest) { r.Replicas = &negative }, []string{"replicas"}},
+		{"unsupported kind", func(r *CreateContainerRequest) { r.Kind = "statefulset" }, []string{"kind"}},
+		{"invalid env name", func(r *CreateContainerRequest) { r.Env = EnvVarList{{Name: "1A"}} }, []string{"env[0].name"}},
+		{"duplicate env", func(r *CreateContainerRequest) { r.Env = EnvVarList{{Name: "A"}, {Name: "A"}} }, []string{"env[1].name"}},
+		{
+			name: "invalid env source",
+			modify: func(r *CreateContainerRequest) {
+				r.Env = EnvVarList{{Name: "A", Value: "1", ValueFrom: &EnvVarSource{Type: "vault"}}}
+			},
+			fields: []string{"env[0]", "env[0].valueFrom.type", "env[0].valueFrom.name", "env[0].valueFrom.key"},
+		},
+		{"port out of range", func(r *CreateContainerRequest) { r.Ports = PortList{{ContainerPort: 70000}} }, []string{"ports[0].containerPort"}},
+		{"invalid protocol", func(r *CreateContainerRequest) { r.Ports = PortList{{ContainerPort: 80, Protocol: "HTTP"}} }, []string{"ports[0].protocol"}},
+		{
+			name: "duplicate port",
+			modify: func(r *CreateContainerRequest) {
+				r.Ports = PortList{{Name: "http", ContainerPort: 80}, {Name: "http", ContainerPort: 80, Protocol: "TCP"}}
+			},
+			fields: []string{"ports[1].containerPort", "ports[1].name"},
+		},
+		{"same port different protocol", func(r *CreateContainerRequest) {
+			r.Ports = PortList{{ContainerPort: 53}, {ContainerPort: 53, Protocol: "UDP"}}
+		}, nil},
+		{"invalid quantity", func(r *CreateContainerRequest) { r.Resources = &ResourceSpec{CPURequest: "lots"} }, []string{"resources.cpuRequest"}},
+		{"request above limit", func(r *CreateContainerRequest) {
+			r.Resources = &ResourceSpec{MemoryRequest: "1Gi", MemoryLimit: "512Mi"}
+		}, []string{"resources.memoryRequest"}},
+		{"legacy form in v2", func(r *CreateContainerRequest) { r.Version = ContainerRequestV2; r.legacyFields = []string{"env"} }, []string{"env"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+
+			err := req.Validate()
+			if tt.fields == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+			if !errors.Is(err, ErrInvalidContainerRequest) {
+				t.Errorf("Validate() error does not match ErrInvalidContainerRequest")
+			}
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestCreateContainerValidationErrorDoesNotSubmit(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", Resources: &ResourceSpec{CPURequest: "abc"}}
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); !errors.Is(err, ErrInvalidContainerRequest) {
+		t.Fatalf("CreateContainer() error = %v, want ErrInvalidContainerRequest", err)
+	}
+
+	deployments, err := clientSet.AppsV1().Deployments("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list deployments: %v", err)
+	}
+	if len(deployments.Items) != 0 {
+		t.Errorf("deployments = %d, want 0", len(deployments.Items))
+	}
+}
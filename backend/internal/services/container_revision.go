@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"container-platform-backend/internal/model"
+)
+
+// 修订的变更方式
+const (
+	// RevisionActionCreate 首次修改镜像前补记的原始镜像
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+)
+
+const (
+	// rolloutPollInterval 跟踪发布进度的轮询间隔
+	rolloutPollInterval = 2 * time.Second
+	// rolloutPollTimeout 单次查询发布进度的超时时间
+	rolloutPollTimeout = 10 * time.Second
+	// rolloutTimeout 超过该时间仍未完成的发布视为失败，略长于 Deployment 默认的 10 分钟进度期限
+	rolloutTimeout = 15 * time.Minute
+)
+
+// ErrRevisionNotFound 修订不存在
+var ErrRevisionNotFound = errors.New("container revision not found")
+
+// UpdateImageRequest 修改容器镜像的请求
+type UpdateImageRequest struct {
+	// 容器名称，为空时修改第一个容器
+	Container string `json:"container"`
+	Image     string `json:"image" binding:"required"`
+}
+
+// RollbackRequest 回滚容器镜像的请求
+type RollbackRequest struct {
+	Revision int `json:"revision" binding:"required"`
+}
+
+// ContainerRollout 容器镜像的实时发布进度和最新修订
+type ContainerRollout struct {
+	*RolloutStatus
+	LatestRevision *model.ContainerRevision `json:"latestRevision,omitempty"`
+}
+
+// ImageRolloutService 修改和回滚容器镜像，记录修订历史并在后台跟踪发布进度
+type ImageRolloutService struct {
+	db            *gorm.DB
+	k8sService    *K8sService
+	connections   *ConnectionService
+	operationLogs *OperationLogService
+
+	mu       sync.Mutex
+	tracking map[uint]bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewImageRolloutService 创建镜像发布服务
+func NewImageRolloutService(db *gorm.DB, k8sService *K8sService, connections *ConnectionService, operationLogs *OperationLogService) *ImageRolloutService {
+	return &ImageRolloutService{
+		db:            db,
+		k8sService:    k8sService,
+		connections:   connections,
+		operationLogs: operationLogs,
+		tracking:      make(map[uint]bool),
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Start 继续跟踪服务重启前尚未完成的发布
+func (s *ImageRolloutService) Start() {
+	var revisions []model.ContainerRevision
+	if err := s.db.Where("status = ?", RolloutProgressing).Find(&revisions).Error; err != nil {
+		log.Printf("Image rollout tracker: failed to load progressing revisions: %v", err)
+		return
+	}
+
+	for _, revision := range revisions {
+		connection, err := s.connections.GetConnection(revision.ConnectionID)
+		if err != nil {
+			s.finish(&revision, RolloutFailed, fmt.Sprintf("failed to resume tracking: %v", err))
+			continue
+		}
+		s.track(revision, connection)
+	}
+	if len(revisions) > 0 {
+		log.Printf("Image rollout tracker resumed %d rollouts", len(revisions))
+	}
+}
+
+// Stop 停止跟踪，未完成的发布在下次启动时继续跟踪
+func (s *ImageRolloutService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+// UpdateImage 修改容器镜像并记录新的修订，后台跟踪发布进度直到可用、失败或超时
+func (s *ImageRolloutService) UpdateImage(ctx context.Context, connection *model.K8sConnection, namespace, podName string, req *UpdateImageRequest) (*model.ContainerRevision, error) {
+	return s.apply(ctx, connection, namespace, podName, req.Container, req.Image, nil)
+}
+
+// Rollback 将容器镜像恢复为指定修订的镜像，回滚本身记录为新的修订
+func (s *ImageRolloutService) Rollback(ctx context.Context, connection *model.K8sConnection, namespace, podName string, revision int) (*model.ContainerRevision, error) {
+	status, err := s.k8sService.GetRolloutStatus(ctx, connection, namespace, podName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var target model.ContainerRevision
+	err = revisionScope(s.db.WithContext(ctx), connection.ID, namespace, status.Kind, status.Name).
+		Where("revision = ?", revision).
+		First(&target).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s %s revision %d", ErrRevisionNotFound, status.Kind, status.Name, revision)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container revision: %w", err)
+	}
+
+	return s.apply(ctx, connection, namespace, podName, target.ContainerName, target.Image, &target.Revision)
+}
+
+// ListRevisions 获取容器所属工作负载的修订历史，按修订号倒序
+func (s *ImageRolloutService) ListRevisions(ctx context.Context, connection *model.K8sConnection, namespace, podName string) ([]model.ContainerRevision, error) {
+	status, err := s.k8sService.GetRolloutStatus(ctx, connection, namespace, podName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []model.ContainerRevision{}
+	err = revisionScope(s.db.WithContext(ctx), connection.ID, namespace, status.Kind, status.Name).
+		Order("revision DESC").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetRollout 获取容器镜像的实时发布进度和最新修订
+func (s *ImageRolloutService) GetRollout(ctx context.Context, connection *model.K8sConnection, namespace, podName, containerName string) (*ContainerRollout, error) {
+	status, err := s.k8sService.GetRolloutStatus(ctx, connection, namespace, podName, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := &ContainerRollout{RolloutStatus: status}
+	var latest model.ContainerRevision
+	err = revisionScope(s.db.WithContext(ctx), connection.ID, namespace, status.Kind, status.Name).
+		Order("revision DESC").
+		First(&latest).Error
+	if err == nil {
+		rollout.LatestRevision = &latest
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get container revision: %w", err)
+	}
+	return rollout, nil
+}
+
+// apply 修改镜像并写入修订，rollbackTo 非空时为回滚
+// 同一工作负载的修改在数据库事务中串行执行，修订号与镜像修改的顺序一致；修订写入失败时返回错误
+func (s *ImageRolloutService) apply(ctx context.Context, connection *model.K8sConnection, namespace, podName, containerName, image string, rollbackTo *int) (*model.ContainerRevision, error) {
+	action, changeCause := RevisionActionUpdate, fmt.Sprintf("update image to %s", image)
+	if rollbackTo != nil {
+		action, changeCause = RevisionActionRollback, fmt.Sprintf("rollback to revision %d (%s)", *rollbackTo, image)
+	}
+
+	// 先解析出工作负载，podName 可能是其中一个 Pod 的名称
+	clientSet, err := s.k8sService.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &model.ContainerRevision{
+		ConnectionID: connection.ID,
+		Namespace:    namespace,
+		Action:       action,
+		RollbackTo:   rollbackTo,
+		Status:       RolloutProgressing,
+		OperationID:  NewOperationID(),
+	}
+	if user, ok := PlatformUserFromContext(ctx); ok {
+		if user.ID != 0 {
+			revision.CreatedBy = &user.ID
+		}
+		revision.Username = user.Username
+	}
+
+	// 镜像修改后请求被取消时仍要写完修订
+	err = s.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		if err := lockWorkloadRevisions(tx, connection.ID, namespace, workload.kind(), workload.name()); err != nil {
+			return err
+		}
+
+		update, err := s.k8sService.UpdateContainerImage(ctx, connection, namespace, podName, containerName, image, changeCause)
+		if err != nil {
+			return err
+		}
+		revision.WorkloadKind = update.Kind
+		revision.WorkloadName = update.Name
+		revision.ContainerName = update.Container
+		revision.Image = update.Image
+		revision.PreviousImage = update.PreviousImage
+
+		if err := recordRevision(tx, connection, update, revision); err != nil {
+			return fmt.Errorf("image of %s %s/%s was changed to %s but the revision could not be recorded: %w",
+				update.Kind, namespace, update.Name, update.Image, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.track(*revision, connection)
+	return revision, nil
+}
+
+// lockWorkloadRevisions 以事务级 advisory lock 串行化同一工作负载的镜像修改和修订编号，事务结束时自动释放
+func lockWorkloadRevisions(tx *gorm.DB, connectionID uint, namespace, kind, name string) error {
+	key := fmt.Sprintf("container_revisions/%d/%s/%s/%s", connectionID, namespace, kind, name)
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+		return fmt.Errorf("failed to lock container revisions: %w", err)
+	}
+	return nil
+}
+
+// recordRevision 在事务中写入新的修订并取代仍在发布中的修订，首次修改时先补记原始镜像作为第一个修订
+func recordRevision(tx *gorm.DB, connection *model.K8sConnection, update *ImageUpdate, revision *model.ContainerRevision) error {
+	latest, next, err := revisionNumbers(tx, connection.ID, revision.Namespace, update.Kind, update.Name)
+	if err != nil {
+		return err
+	}
+
+	record, err := findContainerRecord(tx, connection.ID, revision.Namespace, update.Name)
+	if err != nil {
+		return err
+	}
+
+	if latest == 0 {
+		initial := &model.ContainerRevision{
+			ConnectionID:  connection.ID,
+			Namespace:     revision.Namespace,
+			WorkloadKind:  update.Kind,
+			WorkloadName:  update.Name,
+			Revision:      next,
+			ContainerName: update.Container,
+			Image:         update.PreviousImage,
+			Action:        RevisionActionCreate,
+			Status:        RolloutAvailable,
+		}
+		initial.CreatedAt = update.CreatedAt
+		if record != nil {
+			initial.CreatedAt = record.CreatedAt
+			initial.CreatedBy = record.CreatedBy
+		}
+		initial.CompletedAt = &initial.CreatedAt
+		if err := tx.Create(initial).Error; err != nil {
+			return fmt.Errorf("failed to create initial container revision: %w", err)
+		}
+		next++
+	}
+
+	now := time.Now()
+	err = revisionScope(tx, connection.ID, revision.Namespace, update.Kind, update.Name).
+		Where("status = ?", RolloutProgressing).
+		Updates(map[string]interface{}{
+			"status":       RolloutSuperseded,
+			"message":      fmt.Sprintf("superseded by revision %d", next),
+			"completed_at": now,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to supersede container revisions: %w", err)
+	}
+
+	revision.Revision = next
+	if err := tx.Create(revision).Error; err != nil {
+		return fmt.Errorf("failed to create container revision: %w", err)
+	}
+
+	if record == nil {
+		return nil
+	}
+	image, err := ensureImageRecord(tx, update.Image)
+	if err != nil {
+		return err
+	}
+	return tx.Model(record).Updates(map[string]interface{}{
+		"image_id":   image.ID,
+		"updated_by": revision.CreatedBy,
+	}).Error
+}
+
+// track 在后台轮询发布进度，同一修订只跟踪一次
+func (s *ImageRolloutService) track(revision model.ContainerRevision, connection *model.K8sConnection) {
+	s.mu.Lock()
+	if s.tracking[revision.ID] {
+		s.mu.Unlock()
+		return
+	}
+	s.tracking[revision.ID] = true
+	s.mu.Unlock()
+
+	// 以修改镜像的用户身份查询，保证身份模拟下的权限一致
+	user := &PlatformUser{Username: revision.Username}
+	if revision.CreatedBy != nil {
+		user.ID = *revision.CreatedBy
+	}
+	ctx := WithPlatformUser(context.Background(), user)
+	deadline := revision.CreatedAt.Add(rolloutTimeout)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.tracking, revision.ID)
+			s.mu.Unlock()
+		}()
+
+		ticker := time.NewTicker(rolloutPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				if s.poll(ctx, &revision, connection, deadline) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// poll 查询一次发布进度，发布结束（包括被取代）时返回 true
+func (s *ImageRolloutService) poll(ctx context.Context, revision *model.ContainerRevision, connection *model.K8sConnection, deadline time.Time) bool {
+	var current model.ContainerRevision
+	err := s.db.Select("status").First(&current, revision.ID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && current.Status != RolloutProgressing) {
+		return true
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, rolloutPollTimeout)
+	defer cancel()
+
+	status, err := s.k8sService.GetRolloutStatus(pollCtx, connection, revision.Namespace, revision.WorkloadName, revision.ContainerName)
+	switch {
+	case apierrors.IsNotFound(err):
+		return s.finish(revision, RolloutFailed, fmt.Sprintf("%s %s no longer exists", revision.WorkloadKind, revision.WorkloadName))
+	case err != nil:
+		if time.Now().After(deadline) {
+			return s.finish(revision, RolloutFailed, fmt.Sprintf("timed out waiting for rollout: %v", err))
+		}
+		log.Printf("Image rollout tracker: failed to get rollout status of %s %s/%s: %v", revision.WorkloadKind, revision.Namespace, revision.WorkloadName, err)
+		return false
+	case status.Image != revision.Image:
+		return s.finish(revision, RolloutSuperseded, fmt.Sprintf("image was changed to %s outside the platform", status.Image))
+	case status.Status != RolloutProgressing:
+		return s.finish(revision, status.Status, status.Message)
+	case time.Now().After(deadline):
+		return s.finish(revision, RolloutFailed, fmt.Sprintf("timed out waiting for rollout: %s", status.Message))
+	}
+	return false
+}
+
+// finish 写入发布结果和操作日志，修订已不在发布中时不做修改
+func (s *ImageRolloutService) finish(revision *model.ContainerRevision, status, message string) bool {
+	now := time.Now()
+	result := s.db.Model(&model.ContainerRevision{}).
+		Where("id = ? AND status = ?", revision.ID, RolloutProgressing).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"message":      message,
+			"completed_at": now,
+		})
+	if result.Error != nil {
+		log.Printf("Image rollout tracker: failed to update revision %d: %v", revision.ID, result.Error)
+		return true
+	}
+	if result.RowsAffected == 0 {
+		return true
+	}
+	log.Printf("Rollout of %s %s/%s revision %d %s: %s", revision.WorkloadKind, revision.Namespace, revision.WorkloadName, revision.Revision, status, message)
+
+	duration := int(now.Sub(revision.CreatedAt).Milliseconds())
+	entry := &model.OperationLog{
+		ParentOperationID: revision.OperationID,
+		UserID:            revision.CreatedBy,
+		Username:          revision.Username,
+		Action:            "rollout",
+		ResourceType:      "container",
+		ResourceName:      revision.Namespace + "/" + revision.WorkloadName,
+		StartedAt:         revision.CreatedAt,
+		CompletedAt:       &now,
+		DurationMs:        &duration,
+		Metadata: model.JSONB{
+			"connectionId": revision.ConnectionID,
+			"kind":         revision.WorkloadKind,
+			"container":    revision.ContainerName,
+			"revision":     revision.Revision,
+			"image":        revision.Image,
+			"status":       status,
+			"message":      message,
+		},
+	}
+	if status == RolloutFailed {
+		entry.ErrorMessage = message
+	}
+	if err := s.operationLogs.Record(entry); err != nil {
+		log.Printf("Failed to record rollout operation log: %v", err)
+	}
+	return true
+}
+
+// revisionScope 限定到某个工作负载的修订
+func revisionScope(tx *gorm.DB, connectionID uint, namespace, kind, name string) *gorm.DB {
+	return tx.Model(&model.ContainerRevision{}).
+		Where("connection_id = ? AND namespace = ? AND workload_kind = ? AND workload_name = ?", connectionID, namespace, kind, name)
+}
+
+// revisionNumbers 返回工作负载当前最大的修订号（不存在时为 0）和下一个修订号，已删除的修订号不再复用
+func revisionNumbers(tx *gorm.DB, connectionID uint, namespace, kind, name string) (int, int, error) {
+	var revisions struct {
+		Latest *int
+		Max    *int
+	}
+	err := revisionScope(tx.Unscoped(), connectionID, namespace, kind, name).
+		Select("MAX(CASE WHEN deleted_at IS NULL THEN revision END) AS latest, MAX(revision) AS max").
+		Scan(&revisions).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get container revision: %w", err)
+	}
+
+	latest, next := 0, 1
+	if revisions.Latest != nil {
+		latest = *revisions.Latest
+	}
+	if revisions.Max != nil {
+		next = *revisions.Max + 1
+	}
+	return latest, next, nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUpdateImageRecordsRevisionUnderWorkloadLock(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 1)
+	k8sService, clientSet := newFakeService(t, deployment, replicaSet, pod)
+	db, recorder := newRecordingDB(t, 0)
+	rollouts := NewImageRolloutService(db, k8sService, nil, nil)
+	defer rollouts.Stop()
+
+	revision, err := rollouts.UpdateImage(context.Background(), newTestConnection(), "default", pod.Name, &UpdateImageRequest{Image: "nginx:1.27"})
+	if err != nil {
+		t.Fatalf("UpdateImage() error = %v", err)
+	}
+	// 首次修改时先补记原始镜像为修订 1
+	if revision.Revision != 2 || revision.WorkloadName != "web" || revision.Image != "nginx:1.27" {
+		t.Errorf("revision = %+v", revision)
+	}
+	if image := getDeployment(t, clientSet, "default", "web").Spec.Template.Spec.Containers[0].Image; image != "nginx:1.27" {
+		t.Errorf("image = %s, want nginx:1.27", image)
+	}
+
+	// 修订编号之前先锁定该工作负载
+	if len(recorder.statements) == 0 || !strings.Contains(recorder.statements[0].query, "pg_advisory_xact_lock") {
+		t.Fatalf("first statement = %+v, want the workload lock", recorder.statements)
+	}
+	if key := recorder.statements[0].args[0].Value; key != "container_revisions/1/default/deployment/web" {
+		t.Errorf("lock key = %v", key)
+	}
+	if inserts := recorder.matching(`INSERT INTO "container_revisions"`); len(inserts) != 2 {
+		t.Errorf("got %d revision inserts, want 2", len(inserts))
+	}
+}
+
+func TestUpdateImageSurfacesRevisionFailure(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 1)
+	k8sService, _ := newFakeService(t, deployment, replicaSet, pod)
+	db, recorder := newRecordingDB(t, 0)
+	recorder.fail = `INSERT INTO "container_revisions"`
+	rollouts := NewImageRolloutService(db, k8sService, nil, nil)
+	defer rollouts.Stop()
+
+	revision, err := rollouts.UpdateImage(context.Background(), newTestConnection(), "default", "web", &UpdateImageRequest{Image: "nginx:1.27"})
+	if err == nil || !strings.Contains(err.Error(), "revision could not be recorded") {
+		t.Fatalf("UpdateImage() = %+v, %v, want the revision failure", revision, err)
+	}
+}
@@ -0,0 +1,549 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"container-platform-backend/internal/model"
+)
+
+// 模板变量类型
+const (
+	TemplateVariableString  = "string"
+	TemplateVariableInteger = "integer"
+	TemplateVariableNumber  = "number"
+	TemplateVariableBoolean = "boolean"
+)
+
+var (
+	// ErrTemplateNotFound 模板或指定版本不存在
+	ErrTemplateNotFound = errors.New("container template not found")
+	// ErrTemplateExists 同一作用域下已存在同名模板
+	ErrTemplateExists = errors.New("container template already exists")
+)
+
+var (
+	// templateVariablePattern 模板中的变量引用 ${name}
+	templateVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// templateVariableName 变量名
+	templateVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// TemplateVariable 模板变量定义，未提供值且没有默认值时 required 变量会校验失败，
+// 非 required 变量渲染为类型的零值
+type TemplateVariable struct {
+	// 类型：string、integer、number 或 boolean
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// TemplateRequest 创建或修改模板的请求，修改时生成新版本
+type TemplateRequest struct {
+	Name string `json:"name"`
+	// 为空时为全局模板
+	Namespace   string `json:"namespace,omitempty"`
+	Description string `json:"description,omitempty"`
+	// 创建容器请求（镜像、资源、环境变量、端口、探针、存储卷等），字符串中可以用 ${变量名} 引用变量，
+	// 整个字符串只有一个变量引用时替换为变量的类型化值（如 replicas），字符串字段只能单独引用 string 类型的变量
+	Spec      model.JSONB                 `json:"spec" swaggertype:"object"`
+	Variables map[string]TemplateVariable `json:"variables,omitempty"`
+}
+
+// InstantiateTemplateRequest 使用模板创建容器的请求
+type InstantiateTemplateRequest struct {
+	// 容器名称，为空时使用模板中的 name，仍为空时使用模板名称
+	Name string `json:"name,omitempty"`
+	// 命名空间，为空时使用命名空间模板所属的命名空间或连接的默认命名空间
+	Namespace string `json:"namespace,omitempty"`
+	// 模板版本，为 0 时使用最新版本
+	Version    int                    `json:"version,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	// 只校验渲染结果，不创建容器
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// TemplateService 容器模板服务
+type TemplateService struct {
+	db *gorm.DB
+}
+
+// NewTemplateService 创建容器模板服务
+func NewTemplateService(db *gorm.DB) *TemplateService {
+	return &TemplateService{db: db}
+}
+
+// ListTemplates 获取各模板的最新版本，namespace 不为空时只返回全局模板和该命名空间的模板
+func (s *TemplateService) ListTemplates(ctx context.Context, namespace string) ([]model.ContainerTemplate, error) {
+	latest := s.db.Table("container_templates AS latest").Select("MAX(latest.version)").
+		Where("latest.name = container_templates.name AND latest.namespace = container_templates.namespace AND latest.deleted_at IS NULL")
+	query := s.db.WithContext(ctx).Where("version = (?)", latest)
+	if namespace != "" {
+		query = query.Where("namespace IN ?", []string{"", namespace})
+	}
+
+	var templates []model.ContainerTemplate
+	if err := query.Order("namespace, name").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list container templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate 获取模板的指定版本（version 为 0 时为最新版本）
+// namespace 不为空时优先使用该命名空间的模板，不存在时使用同名的全局模板
+func (s *TemplateService) GetTemplate(ctx context.Context, namespace, name string, version int) (*model.ContainerTemplate, error) {
+	scopes := []string{""}
+	if namespace != "" {
+		scopes = []string{namespace, ""}
+	}
+
+	for _, scope := range scopes {
+		query := s.db.WithContext(ctx).Where("name = ? AND namespace = ?", name, scope)
+		if version > 0 {
+			query = query.Where("version = ?", version)
+		}
+
+		var template model.ContainerTemplate
+		err := query.Order("version DESC").First(&template).Error
+		if err == nil {
+			return &template, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get container template: %w", err)
+		}
+	}
+
+	if version > 0 {
+		return nil, fmt.Errorf("%w: %s version %d", ErrTemplateNotFound, name, version)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// ListTemplateVersions 获取模板在指定作用域下的所有版本，最新版本在前
+func (s *TemplateService) ListTemplateVersions(ctx context.Context, namespace, name string) ([]model.ContainerTemplate, error) {
+	var templates []model.ContainerTemplate
+	err := s.db.WithContext(ctx).Where("name = ? AND namespace = ?", name, namespace).Order("version DESC").Find(&templates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container template versions: %w", err)
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return templates, nil
+}
+
+// CreateTemplate 创建模板的第一个版本
+func (s *TemplateService) CreateTemplate(ctx context.Context, req *TemplateRequest) (*model.ContainerTemplate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var template *model.ContainerTemplate
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		latest, next, err := templateVersions(tx, req.Namespace, req.Name)
+		if err != nil {
+			return err
+		}
+		if latest > 0 {
+			return fmt.Errorf("%w: %s", ErrTemplateExists, req.Name)
+		}
+
+		template, err = req.template(ctx, next)
+		if err != nil {
+			return err
+		}
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		return nil, wrapTemplateError("failed to create container template", err)
+	}
+	return template, nil
+}
+
+// UpdateTemplate 为已有模板生成新版本，旧版本保持不变
+func (s *TemplateService) UpdateTemplate(ctx context.Context, req *TemplateRequest) (*model.ContainerTemplate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var template *model.ContainerTemplate
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		latest, next, err := templateVersions(tx, req.Namespace, req.Name)
+		if err != nil {
+			return err
+		}
+		if latest == 0 {
+			return fmt.Errorf("%w: %s", ErrTemplateNotFound, req.Name)
+		}
+
+		template, err = req.template(ctx, next)
+		if err != nil {
+			return err
+		}
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		return nil, wrapTemplateError("failed to update container template", err)
+	}
+	return template, nil
+}
+
+// DeleteTemplate 删除模板在指定作用域下的所有版本
+func (s *TemplateService) DeleteTemplate(ctx context.Context, namespace, name string) error {
+	result := s.db.WithContext(ctx).Where("name = ? AND namespace = ?", name, namespace).Delete(&model.ContainerTemplate{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete container template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return nil
+}
+
+// templateVersions 模板在指定作用域下的最新版本号（不存在时为 0）和下一个版本号
+// 已删除的版本仍占用版本号，删除后重新创建的模板从下一个版本号开始
+func templateVersions(tx *gorm.DB, namespace, name string) (int, int, error) {
+	var versions struct {
+		Latest *int
+		Max    *int
+	}
+	err := tx.Unscoped().Model(&model.ContainerTemplate{}).Where("name = ? AND namespace = ?", name, namespace).
+		Select("MAX(CASE WHEN deleted_at IS NULL THEN version END) AS latest, MAX(version) AS max").
+		Scan(&versions).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get container template version: %w", err)
+	}
+
+	latest, next := 0, 1
+	if versions.Latest != nil {
+		latest = *versions.Latest
+	}
+	if versions.Max != nil {
+		next = *versions.Max + 1
+	}
+	return latest, next, nil
+}
+
+// wrapTemplateError 为数据库错误添加上下文，模板相关的错误原样返回
+func wrapTemplateError(message string, err error) error {
+	if errors.Is(err, ErrTemplateNotFound) || errors.Is(err, ErrTemplateExists) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// template 构建模板记录
+func (r *TemplateRequest) template(ctx context.Context, version int) (*model.ContainerTemplate, error) {
+	variables := model.JSONB{}
+	for name, variable := range r.Variables {
+		variables[name] = variable
+	}
+	// 经过 JSON 往返，保证返回的记录与从数据库读取的记录一致
+	data, err := json.Marshal(variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template variables: %w", err)
+	}
+	variables = model.JSONB{}
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("failed to encode template variables: %w", err)
+	}
+
+	template := &model.ContainerTemplate{
+		Name:        r.Name,
+		Namespace:   r.Namespace,
+		Version:     version,
+		Description: r.Description,
+		Spec:        r.Spec,
+		Variables:   variables,
+	}
+	if user, ok := PlatformUserFromContext(ctx); ok && user.ID != 0 {
+		template.CreatedBy = &user.ID
+	}
+	return template, nil
+}
+
+// Validate 校验模板名称、变量定义和变量引用，并检查使用默认值渲染的结果是否是合法的创建容器请求结构
+func (r *TemplateRequest) Validate() error {
+	var errs ValidationErrors
+
+	if r.Name == "" {
+		errs.add("name", "is required")
+	} else {
+		for _, msg := range validation.IsDNS1123Label(r.Name) {
+			errs.add("name", "%s", msg)
+		}
+	}
+	if r.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(r.Namespace) {
+			errs.add("namespace", "%s", msg)
+		}
+	}
+	if len(r.Spec) == 0 {
+		errs.add("spec", "is required")
+	}
+
+	sample := make(map[string]interface{}, len(r.Variables))
+	for _, name := range sortedVariableNames(r.Variables) {
+		variable := r.Variables[name]
+		field := "variables." + name
+		if !templateVariableName.MatchString(name) {
+			errs.add(field, "name must start with a letter or underscore and contain only letters, digits and underscores")
+		}
+		if _, ok := zeroValue(variable.Type); !ok {
+			errs.add(field+".type", "unsupported type %q, must be string, integer, number or boolean", variable.Type)
+			continue
+		}
+
+		value, err := variable.resolve(variable.Default, variable.Default != nil)
+		if err != nil {
+			errs.add(field+".default", "%s", err.Error())
+			continue
+		}
+		sample[name] = value
+	}
+
+	for _, name := range templateReferences(r.Spec) {
+		if _, ok := r.Variables[name]; !ok {
+			errs.add("spec", "references undefined variable %q", name)
+		}
+	}
+
+	if len(errs) == 0 {
+		if _, err := renderTemplateSpec(r.Spec, sample); err != nil {
+			errs.add("spec", "%s", err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// RenderTemplate 使用参数渲染模板，返回创建容器请求（名称和命名空间由调用方设置）
+func RenderTemplate(template *model.ContainerTemplate, parameters map[string]interface{}) (*CreateContainerRequest, error) {
+	variables, err := templateVariables(template)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs ValidationErrors
+	for name := range parameters {
+		if _, ok := variables[name]; !ok {
+			errs.add("parameters."+name, "is not a variable of template %s", template.Name)
+		}
+	}
+
+	values := make(map[string]interface{}, len(variables))
+	for _, name := range sortedVariableNames(variables) {
+		variable := variables[name]
+		value, provided := parameters[name]
+		if !provided {
+			value, provided = variable.Default, variable.Default != nil
+		}
+		if !provided && variable.Required {
+			errs.add("parameters."+name, "is required")
+			continue
+		}
+
+		resolved, err := variable.resolve(value, provided)
+		if err != nil {
+			errs.add("parameters."+name, "%s", err.Error())
+			continue
+		}
+		values[name] = resolved
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	req, err := renderTemplateSpec(template.Spec, values)
+	if err != nil {
+		return nil, ValidationErrors{{Field: "spec", Message: err.Error()}}
+	}
+	return req, nil
+}
+
+// templateVariables 解析模板记录中的变量定义
+func templateVariables(template *model.ContainerTemplate) (map[string]TemplateVariable, error) {
+	variables := map[string]TemplateVariable{}
+	if len(template.Variables) == 0 {
+		return variables, nil
+	}
+
+	data, err := json.Marshal(template.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode template variables: %w", err)
+	}
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("failed to decode template variables: %w", err)
+	}
+	return variables, nil
+}
+
+// renderTemplateSpec 替换模板中的变量引用并解析为创建容器请求
+func renderTemplateSpec(spec model.JSONB, values map[string]interface{}) (*CreateContainerRequest, error) {
+	rendered := substituteVariables(map[string]interface{}(spec), values)
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	var req CreateContainerRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("rendered template is not a valid container request: %w", err)
+	}
+	return &req, nil
+}
+
+// substituteVariables 递归替换字符串中的变量引用；整个字符串只有一个变量引用时替换为类型化的值
+func substituteVariables(value interface{}, values map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = substituteVariables(item, values)
+		}
+		return result
+	case model.JSONB:
+		return substituteVariables(map[string]interface{}(v), values)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = substituteVariables(item, values)
+		}
+		return result
+	case string:
+		if match := templateVariablePattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			return values[match[1]]
+		}
+		return templateVariablePattern.ReplaceAllStringFunc(v, func(reference string) string {
+			name := templateVariablePattern.FindStringSubmatch(reference)[1]
+			return fmt.Sprint(values[name])
+		})
+	default:
+		return value
+	}
+}
+
+// templateReferences 模板中引用的变量名，按名称排序且不重复
+func templateReferences(value interface{}) []string {
+	found := make(map[string]bool)
+	var walk func(interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case model.JSONB:
+			walk(map[string]interface{}(v))
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case string:
+			for _, match := range templateVariablePattern.FindAllStringSubmatch(v, -1) {
+				found[match[1]] = true
+			}
+		}
+	}
+	walk(value)
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve 将变量值转换为变量类型，未提供值时返回类型的零值
+// 数值和布尔变量也接受字符串形式的值
+func (v TemplateVariable) resolve(value interface{}, provided bool) (interface{}, error) {
+	if !provided || value == nil {
+		zero, _ := zeroValue(v.Type)
+		return zero, nil
+	}
+
+	switch v.Type {
+	case TemplateVariableString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case TemplateVariableInteger:
+		switch n := value.(type) {
+		case float64:
+			if n == math.Trunc(n) {
+				return int64(n), nil
+			}
+		case int:
+			return int64(n), nil
+		case int64:
+			return n, nil
+		case string:
+			if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case TemplateVariableNumber:
+		switch n := value.(type) {
+		case float64:
+			return n, nil
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case string:
+			if f, err := strconv.ParseFloat(n, 64); err == nil {
+				return f, nil
+			}
+		}
+	case TemplateVariableBoolean:
+		switch b := value.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(b); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("must be a %s, got %v", v.Type, value)
+}
+
+// zeroValue 变量类型的零值，类型不支持时返回 false
+func zeroValue(variableType string) (interface{}, bool) {
+	switch variableType {
+	case TemplateVariableString:
+		return "", true
+	case TemplateVariableInteger:
+		return int64(0), true
+	case TemplateVariableNumber:
+		return float64(0), true
+	case TemplateVariableBoolean:
+		return false, true
+	default:
+		return nil, false
+	}
+}
+
+// sortedVariableNames 按名称排序的变量名，保证错误顺序稳定
+func sortedVariableNames(variables map[string]TemplateVariable) []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
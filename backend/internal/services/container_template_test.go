@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"container-platform-backend/internal/model"
+)
+
+func newTestTemplate() *TemplateRequest {
+	return &TemplateRequest{
+		Name: "web",
+		Spec: model.JSONB{
+			"image":    "nginx:${tag}",
+			"replicas": "${replicas}",
+			"ports":    []interface{}{map[string]interface{}{"containerPort": "${port}"}},
+			"env": []interface{}{
+				map[string]interface{}{"name": "DEBUG", "value": "${debug}"},
+				map[string]interface{}{"name": "VERBOSE", "value": "verbose=${verbose}"},
+				map[string]interface{}{"name": "GREETING", "value": "hello ${user}"},
+			},
+		},
+		Variables: map[string]TemplateVariable{
+			"tag":      {Type: TemplateVariableString, Default: "1.25"},
+			"replicas": {Type: TemplateVariableInteger, Default: float64(1)},
+			"port":     {Type: TemplateVariableInteger, Default: float64(80)},
+			"debug":    {Type: TemplateVariableString, Default: "false"},
+			"verbose":  {Type: TemplateVariableBoolean},
+			"user":     {Type: TemplateVariableString, Required: true},
+		},
+	}
+}
+
+// storedTemplate 模拟模板保存到数据库后再读取的记录
+func storedTemplate(t *testing.T, req *TemplateRequest) *model.ContainerTemplate {
+	t.Helper()
+
+	template, err := req.template(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("template() error = %v", err)
+	}
+	return template
+}
+
+func TestTemplateRequestValidate(t *testing.T) {
+	if err := newTestTemplate().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*TemplateRequest)
+		field  string
+	}{
+		{"invalid name", func(r *TemplateRequest) { r.Name = "Web" }, "name"},
+		{"missing spec", func(r *TemplateRequest) { r.Spec = nil }, "spec"},
+		{"unknown type", func(r *TemplateRequest) { r.Variables["debug"] = TemplateVariable{Type: "list"} }, "variables.debug.type"},
+		{"typed value in string field", func(r *TemplateRequest) { r.Variables["debug"] = TemplateVariable{Type: TemplateVariableBoolean} }, "spec"},
+		{"invalid default", func(r *TemplateRequest) {
+			r.Variables["port"] = TemplateVariable{Type: TemplateVariableInteger, Default: "http"}
+		}, "variables.port.default"},
+		{"invalid variable name", func(r *TemplateRequest) { r.Variables["my-var"] = TemplateVariable{Type: TemplateVariableString} }, "variables.my-var"},
+		{"undefined variable", func(r *TemplateRequest) { delete(r.Variables, "tag") }, "spec"},
+		{"invalid spec structure", func(r *TemplateRequest) { r.Spec["replicas"] = "${tag}" }, "spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestTemplate()
+			tt.mutate(req)
+
+			var validationErrs ValidationErrors
+			if err := req.Validate(); !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.field {
+				t.Errorf("Validate() error = %v, want field error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	template := storedTemplate(t, newTestTemplate())
+
+	req, err := RenderTemplate(template, map[string]interface{}{"user": "alice", "replicas": "3", "verbose": true})
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+
+	if req.Image != "nginx:1.25" || req.Replicas == nil || *req.Replicas != 3 {
+		t.Errorf("rendered image = %s, replicas = %v", req.Image, req.Replicas)
+	}
+	if !reflect.DeepEqual(req.Ports, PortList{{ContainerPort: 80}}) {
+		t.Errorf("rendered ports = %+v", req.Ports)
+	}
+	wantEnv := EnvVarList{{Name: "DEBUG", Value: "false"}, {Name: "VERBOSE", Value: "verbose=true"}, {Name: "GREETING", Value: "hello alice"}}
+	if !reflect.DeepEqual(req.Env, wantEnv) {
+		t.Errorf("rendered env = %+v, want %+v", req.Env, wantEnv)
+	}
+}
+
+func TestRenderTemplateParameterErrors(t *testing.T) {
+	template := storedTemplate(t, newTestTemplate())
+
+	_, err := RenderTemplate(template, map[string]interface{}{"replicas": 1.5, "color": "blue"})
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("RenderTemplate() error = %v, want ValidationErrors", err)
+	}
+
+	fields := make([]string, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields = append(fields, fieldErr.Field)
+	}
+	want := []string{"parameters.color", "parameters.replicas", "parameters.user"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("error fields = %v, want %v", fields, want)
+	}
+}
+
+func TestTemplateVariableResolve(t *testing.T) {
+	tests := []struct {
+		variableType string
+		value        interface{}
+		provided     bool
+		want         interface{}
+		wantErr      bool
+	}{
+		{TemplateVariableString, "v1", true, "v1", false},
+		{TemplateVariableString, float64(1), true, nil, true},
+		{TemplateVariableInteger, float64(8080), true, int64(8080), false},
+		{TemplateVariableInteger, "8080", true, int64(8080), false},
+		{TemplateVariableInteger, nil, false, int64(0), false},
+		{TemplateVariableNumber, "0.5", true, 0.5, false},
+		{TemplateVariableBoolean, "true", true, true, false},
+		{TemplateVariableBoolean, "yes", true, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := TemplateVariable{Type: tt.variableType}.resolve(tt.value, tt.provided)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolve(%s, %v) = %v, %v; want %v, error %v", tt.variableType, tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"container-platform-backend/internal/model"
+)
+
+// ContainerValidation 容器配置的校验结果
+type ContainerValidation struct {
+	Valid  bool             `json:"valid"`
+	Errors ValidationErrors `json:"errors,omitempty"`
+	// 将要提交的工作负载类型和 YAML 清单，请求字段无法构建工作负载时为空
+	Kind     string `json:"kind,omitempty"`
+	Manifest string `json:"manifest,omitempty"`
+}
+
+// quotaFields 配额资源对应的请求字段
+var quotaFields = map[corev1.ResourceName]string{
+	corev1.ResourceRequestsCPU:    "resources.cpuRequest",
+	corev1.ResourceCPU:            "resources.cpuRequest",
+	corev1.ResourceRequestsMemory: "resources.memoryRequest",
+	corev1.ResourceMemory:         "resources.memoryRequest",
+	corev1.ResourceLimitsCPU:      "resources.cpuLimit",
+	corev1.ResourceLimitsMemory:   "resources.memoryLimit",
+	corev1.ResourcePods:           "replicas",
+}
+
+// ValidateContainer 校验容器配置但不创建：构建与 CreateContainer 完全相同的 Deployment/Pod，
+// 检查引用的 PVC 和命名空间配额后以 DryRun 提交给 API Server，准入控制的错误转换为字段错误
+func (s *K8sService) ValidateContainer(ctx context.Context, connection *model.K8sConnection, req *CreateContainerRequest) (*ContainerValidation, error) {
+	result := &ContainerValidation{}
+	if err := result.merge(req.Validate()); err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+	workload, err := req.workload()
+	if err != nil {
+		if err := result.merge(err); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	manifest, err := renderManifest(workload)
+	if err != nil {
+		return nil, err
+	}
+	result.Kind = workload.GetObjectKind().GroupVersionKind().Kind
+	result.Manifest = manifest
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []func() error{
+		func() error { return checkVolumeClaims(ctx, clientSet, req.Namespace, req.Volumes) },
+		func() error { return checkResourceQuota(ctx, clientSet, workload) },
+		func() error {
+			dryRun := metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+			return admissionErrors(submitWorkload(ctx, clientSet, workload, dryRun))
+		},
+	}
+	for _, check := range checks {
+		if err := result.merge(check()); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// merge 将字段错误合并到结果中，非字段错误原样返回
+func (v *ContainerValidation) merge(err error) error {
+	var validationErrs ValidationErrors
+	if err == nil {
+		return nil
+	}
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+	v.Errors = append(v.Errors, validationErrs...)
+	return nil
+}
+
+// admissionErrors 将 API Server 的校验、准入和冲突错误转换为字段错误，其他错误原样返回
+func admissionErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	status := statusErr.Status()
+
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		return ValidationErrors{{Field: "name", Message: status.Message}}
+	case apierrors.IsInvalid(err), apierrors.IsForbidden(err), apierrors.IsBadRequest(err):
+	default:
+		return err
+	}
+
+	var errs ValidationErrors
+	if status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			errs = append(errs, FieldError{Field: cause.Field, Message: cause.Message})
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, FieldError{Message: status.Message})
+	}
+	return errs
+}
+
+// renderManifest 渲染 YAML 清单，多个对象以多文档形式输出，省略未设置的状态字段
+func renderManifest(objects ...runtime.Object) (string, error) {
+	documents := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to render manifest: %w", err)
+		}
+		delete(object, "status")
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "creationTimestamp")
+		}
+		if spec, ok := object["spec"].(map[string]interface{}); ok {
+			if template, ok := spec["template"].(map[string]interface{}); ok {
+				if metadata, ok := template["metadata"].(map[string]interface{}); ok {
+					delete(metadata, "creationTimestamp")
+				}
+			}
+		}
+
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return "", fmt.Errorf("failed to render manifest: %w", err)
+		}
+		documents = append(documents, string(data))
+	}
+	return strings.Join(documents, "---\n"), nil
+}
+
+// checkResourceQuota 检查工作负载所需的资源是否超出命名空间的 ResourceQuota
+// Deployment 按副本数计算；配额限制的计算资源必须在容器上设置或由 LimitRange 提供默认值。
+func checkResourceQuota(ctx context.Context, clientSet kubernetes.Interface, workload runtime.Object) error {
+	var namespace string
+	var podSpec *corev1.PodSpec
+	replicas := int64(1)
+	switch obj := workload.(type) {
+	case *appsv1.Deployment:
+		namespace, podSpec = obj.Namespace, &obj.Spec.Template.Spec
+		replicas = int64(*obj.Spec.Replicas)
+	case *corev1.Pod:
+		namespace, podSpec = obj.Namespace, &obj.Spec
+	default:
+		return nil
+	}
+
+	quotas, err := clientSet.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	if replicas == 0 || len(quotas.Items) == 0 {
+		return nil
+	}
+
+	limitRanges, err := clientSet.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsForbidden(err) {
+		return fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+	var defaults []corev1.LimitRangeItem
+	if err == nil {
+		for _, limitRange := range limitRanges.Items {
+			for _, item := range limitRange.Spec.Limits {
+				if item.Type == corev1.LimitTypeContainer {
+					defaults = append(defaults, item)
+				}
+			}
+		}
+	}
+
+	usage := podQuotaUsage(podSpec, defaults)
+	var errs ValidationErrors
+	for _, quota := range quotas.Items {
+		names := make([]string, 0, len(quota.Spec.Hard))
+		for name := range quota.Spec.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			resourceName := corev1.ResourceName(name)
+			field, tracked := quotaFields[resourceName]
+			if !tracked {
+				continue
+			}
+			perPod, ok := usage[resourceName]
+			if !ok {
+				errs.add(field, "must be set, resource quota %s limits %s", quota.Name, name)
+				continue
+			}
+
+			hard := quota.Spec.Hard[resourceName]
+			used := quota.Status.Used[resourceName]
+			requested := resource.NewMilliQuantity(perPod.MilliValue()*replicas, perPod.Format)
+			total := used.DeepCopy()
+			total.Add(*requested)
+			if total.Cmp(hard) > 0 {
+				errs.add(field, "exceeds resource quota %s: requested %s of %s, used %s, limited to %s",
+					quota.Name, requested.String(), name, used.String(), hard.String())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// podQuotaUsage 单个 Pod 计入配额的资源用量，容器未设置的资源使用 LimitRange 的默认值，
+// 仍未设置的计算资源不包含在结果中
+func podQuotaUsage(podSpec *corev1.PodSpec, defaults []corev1.LimitRangeItem) corev1.ResourceList {
+	usage := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+
+	add := func(quantity resource.Quantity, keys ...corev1.ResourceName) {
+		for _, key := range keys {
+			total := usage[key]
+			total.Add(quantity)
+			usage[key] = total
+		}
+	}
+	for _, container := range podSpec.Containers {
+		requests, limits := containerResources(container.Resources, defaults)
+		for name, keys := range map[corev1.ResourceName][]corev1.ResourceName{
+			corev1.ResourceCPU:    {corev1.ResourceRequestsCPU, corev1.ResourceCPU},
+			corev1.ResourceMemory: {corev1.ResourceRequestsMemory, corev1.ResourceMemory},
+		} {
+			if quantity, ok := requests[name]; ok {
+				add(quantity, keys...)
+			}
+		}
+		if quantity, ok := limits[corev1.ResourceCPU]; ok {
+			add(quantity, corev1.ResourceLimitsCPU)
+		}
+		if quantity, ok := limits[corev1.ResourceMemory]; ok {
+			add(quantity, corev1.ResourceLimitsMemory)
+		}
+	}
+	return usage
+}
+
+// containerResources 按 LimitRange 准入的规则补全容器的资源：未设置的 limit 使用 default，
+// 未设置的 request 使用 defaultRequest，都没有时与 limit 相同
+func containerResources(resources corev1.ResourceRequirements, defaults []corev1.LimitRangeItem) (corev1.ResourceList, corev1.ResourceList) {
+	requests := resources.Requests.DeepCopy()
+	limits := resources.Limits.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	if limits == nil {
+		limits = corev1.ResourceList{}
+	}
+
+	for _, item := range defaults {
+		for name, quantity := range item.Default {
+			if _, ok := limits[name]; !ok {
+				limits[name] = quantity.DeepCopy()
+			}
+		}
+		for name, quantity := range item.DefaultRequest {
+			if _, ok := requests[name]; !ok {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for name, quantity := range limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = quantity.DeepCopy()
+		}
+	}
+	return requests, limits
+}
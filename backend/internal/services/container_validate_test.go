@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestValidateContainer(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx:1.25", Ports: PortList{{ContainerPort: 80}}}
+	result, err := service.ValidateContainer(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("ValidateContainer() error = %v", err)
+	}
+
+	if !result.Valid || len(result.Errors) != 0 || result.Kind != "Deployment" {
+		t.Fatalf("ValidateContainer() = %+v", result)
+	}
+	if actions := clientSet.Actions(); len(actions) == 0 || !actions[len(actions)-1].Matches("create", "deployments") {
+		t.Errorf("actions = %v, want deployment submitted", actions)
+	}
+	for _, want := range []string{"apiVersion: apps/v1", "kind: Deployment", "name: web", "image: nginx:1.25", "containerPort: 80"} {
+		if !strings.Contains(result.Manifest, want) {
+			t.Errorf("manifest does not contain %q:\n%s", want, result.Manifest)
+		}
+	}
+	if strings.Contains(result.Manifest, "status:") || strings.Contains(result.Manifest, "creationTimestamp") {
+		t.Errorf("manifest contains server fields:\n%s", result.Manifest)
+	}
+}
+
+func TestValidateContainerRequestErrors(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{Name: "Web", Namespace: "default", Image: "nginx", Resources: &ResourceSpec{CPURequest: "abc"}}
+	result, err := service.ValidateContainer(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("ValidateContainer() error = %v", err)
+	}
+
+	if result.Valid || result.Manifest != "" {
+		t.Errorf("ValidateContainer() = %+v, want invalid without manifest", result)
+	}
+	fields := map[string]bool{}
+	for _, fieldErr := range result.Errors {
+		fields[fieldErr.Field] = true
+	}
+	if !fields["name"] || !fields["resources.cpuRequest"] {
+		t.Errorf("errors = %+v, want name and resources.cpuRequest", result.Errors)
+	}
+	if len(clientSet.Actions()) != 0 {
+		t.Errorf("actions = %v, want none", clientSet.Actions())
+	}
+}
+
+func TestValidateContainerAdmissionErrors(t *testing.T) {
+	existing, _, _ := newTestDeployment("web", "default", 1)
+	service, clientSet := newFakeService(t, existing)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx"}
+	result, err := service.ValidateContainer(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("ValidateContainer() error = %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0].Field != "name" || result.Manifest == "" {
+		t.Errorf("ValidateContainer() = %+v, want name conflict with manifest", result)
+	}
+
+	clientSet.PrependReactor("create", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "api", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "template", "spec", "containers").Index(0).Child("image"), "", "must not be empty"),
+		})
+	})
+	req.Name = "api"
+	result, err = service.ValidateContainer(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("ValidateContainer() error = %v", err)
+	}
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0].Field != "spec.template.spec.containers[0].image" {
+		t.Errorf("ValidateContainer() errors = %+v, want admission field error", result.Errors)
+	}
+
+	clientSet.PrependReactor("create", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable("apiserver is shutting down")
+	})
+	if _, err := service.ValidateContainer(context.Background(), newTestConnection(), req); !apierrors.IsServiceUnavailable(err) {
+		t.Errorf("ValidateContainer() error = %v, want ServiceUnavailable", err)
+	}
+}
+
+func TestValidateContainerResourceQuota(t *testing.T) {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "default"},
+		Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+			corev1.ResourceRequestsCPU:  resource.MustParse("1"),
+			corev1.ResourceLimitsMemory: resource.MustParse("1Gi"),
+			corev1.ResourcePods:         resource.MustParse("10"),
+		}},
+		Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{
+			corev1.ResourceRequestsCPU: resource.MustParse("500m"),
+			corev1.ResourcePods:        resource.MustParse("2"),
+		}},
+	}
+	service, _ := newFakeService(t, quota)
+
+	replicas := int32(2)
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", Replicas: &replicas, Resources: &ResourceSpec{CPURequest: "300m"}}
+	result, err := service.ValidateContainer(context.Background(), newTestConnection(), req)
+	if err != nil {
+		t.Fatalf("ValidateContainer() error = %v", err)
+	}
+
+	want := []string{"resources.memoryLimit", "resources.cpuRequest"}
+	if result.Valid || len(result.Errors) != len(want) {
+		t.Fatalf("ValidateContainer() errors = %+v, want %v", result.Errors, want)
+	}
+	for i, field := range want {
+		if result.Errors[i].Field != field {
+			t.Errorf("errors[%d] = %+v, want field %s", i, result.Errors[i], field)
+		}
+	}
+	if !strings.Contains(result.Errors[1].Message, "requested 600m") {
+		t.Errorf("cpu quota error = %q", result.Errors[1].Message)
+	}
+}
+
+func TestPodQuotaUsageLimitRangeDefaults(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}},
+		{Name: "sidecar"},
+	}}
+	defaults := []corev1.LimitRangeItem{{
+		Type:    corev1.LimitTypeContainer,
+		Default: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+	}}
+
+	usage := podQuotaUsage(podSpec, defaults)
+	want := map[corev1.ResourceName]string{
+		corev1.ResourceRequestsCPU:    "300m",
+		corev1.ResourceLimitsCPU:      "400m",
+		corev1.ResourceRequestsMemory: "256Mi",
+		corev1.ResourceLimitsMemory:   "256Mi",
+		corev1.ResourcePods:           "1",
+	}
+	for name, quantity := range want {
+		got := usage[name]
+		if got.Cmp(resource.MustParse(quantity)) != 0 {
+			t.Errorf("usage[%s] = %s, want %s", name, got.String(), quantity)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// 存储卷类型，与 model.Volume.Type 一致
+const (
+	VolumeTypePVC       = "pvc"
+	VolumeTypeConfigMap = "configMap"
+	VolumeTypeSecret    = "secret"
+	VolumeTypeEmptyDir  = "emptyDir"
+	VolumeTypeHostPath  = "hostPath"
+)
+
+// VolumeMountSpec 存储卷及其在容器内的挂载点
+// 同名存储卷可以挂载多次（例如使用不同的 subPath），但定义必须一致
+type VolumeMountSpec struct {
+	// Pod 内的存储卷名称
+	Name string `json:"name"`
+	// 类型：pvc、configMap、secret、emptyDir 或 hostPath
+	Type string `json:"type"`
+	// PVC、ConfigMap 或 Secret 的名称
+	Source string `json:"source,omitempty"`
+	// hostPath 类型的宿主机路径
+	HostPath string `json:"hostPath,omitempty"`
+	// emptyDir 的容量上限
+	SizeLimit string `json:"sizeLimit,omitempty"`
+	// emptyDir 的存储介质，Memory 表示使用 tmpfs
+	Medium    string `json:"medium,omitempty"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// sameVolume 两个挂载是否引用同一个存储卷定义
+func (v VolumeMountSpec) sameVolume(other VolumeMountSpec) bool {
+	return v.Type == other.Type && v.Source == other.Source && v.HostPath == other.HostPath &&
+		v.SizeLimit == other.SizeLimit && v.Medium == other.Medium
+}
+
+func (r *CreateContainerRequest) validateVolumes(errs *ValidationErrors) {
+	volumes := make(map[string]VolumeMountSpec)
+	mountPaths := make(map[string]bool)
+	for i, volume := range r.Volumes {
+		field := fmt.Sprintf("volumes[%d]", i)
+
+		if volume.Name == "" {
+			errs.add(field+".name", "is required")
+		} else {
+			for _, msg := range validation.IsDNS1123Label(volume.Name) {
+				errs.add(field+".name", "%s", msg)
+			}
+			if existing, ok := volumes[volume.Name]; ok && !existing.sameVolume(volume) {
+				errs.add(field+".name", "volume %q is already defined with a different source", volume.Name)
+			}
+			volumes[volume.Name] = volume
+		}
+
+		switch volume.Type {
+		case VolumeTypePVC, VolumeTypeConfigMap, VolumeTypeSecret:
+			if volume.Source == "" {
+				errs.add(field+".source", "is required for %s volumes", volume.Type)
+			} else {
+				for _, msg := range validation.IsDNS1123Subdomain(volume.Source) {
+					errs.add(field+".source", "%s", msg)
+				}
+			}
+		case VolumeTypeEmptyDir:
+			if volume.SizeLimit != "" {
+				if quantity, err := resource.ParseQuantity(volume.SizeLimit); err != nil || quantity.Sign() <= 0 {
+					errs.add(field+".sizeLimit", "invalid quantity %q", volume.SizeLimit)
+				}
+			}
+			if volume.Medium != "" && volume.Medium != string(corev1.StorageMediumMemory) {
+				errs.add(field+".medium", "unsupported medium %q, must be empty or Memory", volume.Medium)
+			}
+		case VolumeTypeHostPath:
+			if volume.HostPath == "" {
+				errs.add(field+".hostPath", "is required for hostPath volumes")
+			} else if !path.IsAbs(volume.HostPath) {
+				errs.add(field+".hostPath", "must be an absolute path")
+			}
+		default:
+			errs.add(field+".type", "unsupported type %q, must be one of %s", volume.Type,
+				strings.Join([]string{VolumeTypePVC, VolumeTypeConfigMap, VolumeTypeSecret, VolumeTypeEmptyDir, VolumeTypeHostPath}, ", "))
+		}
+
+		if volume.MountPath == "" {
+			errs.add(field+".mountPath", "is required")
+		} else if !path.IsAbs(volume.MountPath) {
+			errs.add(field+".mountPath", "must be an absolute path")
+		} else {
+			mountPath := path.Clean(volume.MountPath)
+			if mountPaths[mountPath] {
+				errs.add(field+".mountPath", "duplicate mount path %q", mountPath)
+			}
+			mountPaths[mountPath] = true
+		}
+
+		if volume.SubPath != "" {
+			if path.IsAbs(volume.SubPath) {
+				errs.add(field+".subPath", "must be a relative path")
+			}
+			for _, part := range strings.Split(volume.SubPath, "/") {
+				if part == ".." {
+					errs.add(field+".subPath", "must not contain '..'")
+					break
+				}
+			}
+		}
+	}
+}
+
+// podVolumes 根据挂载定义构建 Pod 存储卷和容器挂载点，同名存储卷只生成一次
+func (r *CreateContainerRequest) podVolumes() ([]corev1.Volume, []corev1.VolumeMount) {
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	defined := make(map[string]bool)
+
+	for _, spec := range r.Volumes {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      spec.Name,
+			MountPath: spec.MountPath,
+			SubPath:   spec.SubPath,
+			ReadOnly:  spec.ReadOnly,
+		})
+		if defined[spec.Name] {
+			continue
+		}
+		defined[spec.Name] = true
+
+		volume := corev1.Volume{Name: spec.Name}
+		switch spec.Type {
+		case VolumeTypePVC:
+			volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: spec.Source, ReadOnly: spec.ReadOnly}
+		case VolumeTypeConfigMap:
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: spec.Source}}
+		case VolumeTypeSecret:
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: spec.Source}
+		case VolumeTypeEmptyDir:
+			emptyDir := &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMedium(spec.Medium)}
+			if sizeLimit, err := resource.ParseQuantity(spec.SizeLimit); err == nil && spec.SizeLimit != "" {
+				emptyDir.SizeLimit = &sizeLimit
+			}
+			volume.EmptyDir = emptyDir
+		case VolumeTypeHostPath:
+			volume.HostPath = &corev1.HostPathVolumeSource{Path: spec.HostPath}
+		}
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, mounts
+}
+
+// checkVolumeClaims 提交工作负载前确认引用的 PVC 存在，避免 Pod 一直处于 Pending
+func checkVolumeClaims(ctx context.Context, clientSet kubernetes.Interface, namespace string, volumes []VolumeMountSpec) error {
+	var errs ValidationErrors
+	checked := make(map[string]bool)
+	for i, volume := range volumes {
+		if volume.Type != VolumeTypePVC || checked[volume.Source] {
+			continue
+		}
+		checked[volume.Source] = true
+
+		_, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, volume.Source, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			errs.add(fmt.Sprintf("volumes[%d].source", i), "persistentvolumeclaim %q not found in namespace %s", volume.Source, namespace)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get persistentvolumeclaim %s: %w", volume.Source, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newVolumeRequest(volumes ...VolumeMountSpec) *CreateContainerRequest {
+	return &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", Volumes: volumes}
+}
+
+func TestCreateContainerWithVolumes(t *testing.T) {
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"}}
+	service, clientSet := newFakeService(t, claim)
+
+	req := newVolumeRequest(
+		VolumeMountSpec{Name: "data", Type: VolumeTypePVC, Source: "data", MountPath: "/var/lib/data"},
+		VolumeMountSpec{Name: "config", Type: VolumeTypeConfigMap, Source: "web-config", MountPath: "/etc/nginx/nginx.conf", SubPath: "nginx.conf", ReadOnly: true},
+		VolumeMountSpec{Name: "config", Type: VolumeTypeConfigMap, Source: "web-config", MountPath: "/etc/nginx/mime.types", SubPath: "mime.types", ReadOnly: true},
+		VolumeMountSpec{Name: "tls", Type: VolumeTypeSecret, Source: "web-tls", MountPath: "/etc/tls"},
+		VolumeMountSpec{Name: "cache", Type: VolumeTypeEmptyDir, SizeLimit: "1Gi", Medium: "Memory", MountPath: "/cache"},
+		VolumeMountSpec{Name: "logs", Type: VolumeTypeHostPath, HostPath: "/var/log/web", MountPath: "/var/log/nginx"},
+	)
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	spec := getDeployment(t, clientSet, "default", "web").Spec.Template.Spec
+
+	var names []string
+	for _, volume := range spec.Volumes {
+		names = append(names, volume.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"data", "config", "tls", "cache", "logs"}) {
+		t.Fatalf("pod volumes = %v", names)
+	}
+	if spec.Volumes[0].PersistentVolumeClaim == nil || spec.Volumes[0].PersistentVolumeClaim.ClaimName != "data" {
+		t.Errorf("pvc volume = %+v", spec.Volumes[0])
+	}
+	if spec.Volumes[1].ConfigMap == nil || spec.Volumes[1].ConfigMap.Name != "web-config" {
+		t.Errorf("configmap volume = %+v", spec.Volumes[1])
+	}
+	if spec.Volumes[2].Secret == nil || spec.Volumes[2].Secret.SecretName != "web-tls" {
+		t.Errorf("secret volume = %+v", spec.Volumes[2])
+	}
+	if emptyDir := spec.Volumes[3].EmptyDir; emptyDir == nil || emptyDir.Medium != corev1.StorageMediumMemory || emptyDir.SizeLimit.String() != "1Gi" {
+		t.Errorf("emptyDir volume = %+v", spec.Volumes[3])
+	}
+	if spec.Volumes[4].HostPath == nil || spec.Volumes[4].HostPath.Path != "/var/log/web" {
+		t.Errorf("hostPath volume = %+v", spec.Volumes[4])
+	}
+
+	mounts := spec.Containers[0].VolumeMounts
+	if len(mounts) != 6 {
+		t.Fatalf("volume mounts = %d, want 6", len(mounts))
+	}
+	want := corev1.VolumeMount{Name: "config", MountPath: "/etc/nginx/mime.types", SubPath: "mime.types", ReadOnly: true}
+	if mounts[2] != want {
+		t.Errorf("mount = %+v, want %+v", mounts[2], want)
+	}
+}
+
+func TestCreateContainerRejectsMissingClaim(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	req := newVolumeRequest(VolumeMountSpec{Name: "data", Type: VolumeTypePVC, Source: "missing", MountPath: "/data"})
+	err := service.CreateContainer(context.Background(), newTestConnection(), req)
+
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || validationErrs[0].Field != "volumes[0].source" {
+		t.Fatalf("CreateContainer() error = %v, want field error on volumes[0].source", err)
+	}
+
+	deployments, err := clientSet.AppsV1().Deployments("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list deployments: %v", err)
+	}
+	if len(deployments.Items) != 0 {
+		t.Errorf("deployments = %d, want 0", len(deployments.Items))
+	}
+}
+
+func TestValidateVolumes(t *testing.T) {
+	tests := []struct {
+		name    string
+		volumes []VolumeMountSpec
+		fields  []string
+	}{
+		{"missing fields", []VolumeMountSpec{{}}, []string{"volumes[0].name", "volumes[0].type", "volumes[0].mountPath"}},
+		{"missing source", []VolumeMountSpec{{Name: "data", Type: VolumeTypePVC, MountPath: "/data"}}, []string{"volumes[0].source"}},
+		{"relative host path", []VolumeMountSpec{{Name: "logs", Type: VolumeTypeHostPath, HostPath: "var/log", MountPath: "/logs"}}, []string{"volumes[0].hostPath"}},
+		{
+			name:    "invalid emptyDir",
+			volumes: []VolumeMountSpec{{Name: "cache", Type: VolumeTypeEmptyDir, SizeLimit: "big", Medium: "Disk", MountPath: "/cache"}},
+			fields:  []string{"volumes[0].sizeLimit", "volumes[0].medium"},
+		},
+		{
+			name:    "unsafe paths",
+			volumes: []VolumeMountSpec{{Name: "cache", Type: VolumeTypeEmptyDir, MountPath: "cache", SubPath: "../etc"}},
+			fields:  []string{"volumes[0].mountPath", "volumes[0].subPath"},
+		},
+		{
+			name: "duplicate mount path",
+			volumes: []VolumeMountSpec{
+				{Name: "a", Type: VolumeTypeEmptyDir, MountPath: "/data"},
+				{Name: "b", Type: VolumeTypeEmptyDir, MountPath: "/data/"},
+			},
+			fields: []string{"volumes[1].mountPath"},
+		},
+		{
+			name: "conflicting definitions",
+			volumes: []VolumeMountSpec{
+				{Name: "config", Type: VolumeTypeConfigMap, Source: "a", MountPath: "/a"},
+				{Name: "config", Type: VolumeTypeConfigMap, Source: "b", MountPath: "/b"},
+			},
+			fields: []string{"volumes[1].name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newVolumeRequest(tt.volumes...).Validate()
+
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestSplitImageReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      [3]string
+	}{
+		{"nginx", [3]string{"nginx", "latest", ""}},
+		{"nginx:1.25", [3]string{"nginx", "1.25", ""}},
+		{"registry.local:5000/team/app", [3]string{"registry.local:5000/team/app", "latest", ""}},
+		{"registry.local:5000/team/app:v2", [3]string{"registry.local:5000/team/app", "v2", ""}},
+		{"nginx@sha256:abc", [3]string{"nginx", "", "sha256:abc"}},
+	}
+
+	for _, tt := range tests {
+		name, tag, digest := splitImageReference(tt.reference)
+		if got := [3]string{name, tag, digest}; got != tt.want {
+			t.Errorf("splitImageReference(%q) = %v, want %v", tt.reference, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"container-platform-backend/internal/model"
+)
+
+// 集群健康状态
+const (
+	ClusterStatusUnknown     = "unknown"
+	ClusterStatusHealthy     = "healthy"
+	ClusterStatusDegraded    = "degraded"
+	ClusterStatusUnreachable = "unreachable"
+)
+
+const (
+	// defaultHealthCheckInterval 默认健康检查间隔
+	defaultHealthCheckInterval = time.Minute
+	// healthCheckTimeout 单次探测的超时时间
+	healthCheckTimeout = 10 * time.Second
+	// slowClusterThreshold 超过该延迟视为降级
+	slowClusterThreshold = 2 * time.Second
+	// healthHistoryRetention 健康检查记录的保留时间
+	healthHistoryRetention = 7 * 24 * time.Hour
+	// healthCheckConcurrency 同时探测的连接数量上限
+	healthCheckConcurrency = 4
+)
+
+// ProbeResult 单次集群探测结果
+type ProbeResult struct {
+	Status        string
+	Reachable     bool
+	AuthError     bool
+	ServerVersion string
+	Latency       time.Duration
+	Err           error
+}
+
+// ClusterHealth 连接的当前健康状态
+type ClusterHealth struct {
+	ConnectionID  uint                      `json:"connectionId"`
+	Cluster       string                    `json:"cluster"`
+	Status        string                    `json:"status"`
+	StatusMessage string                    `json:"statusMessage,omitempty"`
+	LastCheckedAt *time.Time                `json:"lastCheckedAt"`
+	LastCheck     *model.ClusterHealthCheck `json:"lastCheck,omitempty"`
+}
+
+// ProbeConnection 探测集群：握手获取版本并测量延迟，再在连接的命名空间列出 Pod 以检查授权
+func (s *K8sService) ProbeConnection(ctx context.Context, connection *model.K8sConnection) *ProbeResult {
+	result := &ProbeResult{Status: ClusterStatusUnreachable}
+
+	cached, err := s.pool.base(ctx, connection)
+	if err != nil {
+		result.Err = err
+		if isAuthError(err) {
+			result.Reachable = true
+			result.AuthError = true
+			result.Status = ClusterStatusDegraded
+		}
+		return result
+	}
+
+	start := time.Now()
+	version, err := serverVersion(ctx, cached.clientSet)
+	result.Latency = time.Since(start)
+	if err != nil {
+		// 缓存的客户端已不可用，下次请求时重新创建
+		s.pool.Invalidate(connection.ID)
+		result.Err = classifyCertificateError(err)
+		if isAuthError(err) {
+			result.Reachable = true
+			result.AuthError = true
+			result.Status = ClusterStatusDegraded
+		}
+		return result
+	}
+	result.Reachable = true
+	result.ServerVersion = version.GitVersion
+
+	namespace := connection.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	_, err = cached.clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+	switch {
+	case err != nil && isAuthError(err):
+		result.AuthError = true
+		result.Status = ClusterStatusDegraded
+		result.Err = fmt.Errorf("cannot list pods in namespace %s: %w", namespace, err)
+	case err != nil:
+		result.Status = ClusterStatusDegraded
+		result.Err = err
+	case result.Latency > slowClusterThreshold:
+		result.Status = ClusterStatusDegraded
+		result.Err = fmt.Errorf("API server latency %s exceeds %s", result.Latency.Round(time.Millisecond), slowClusterThreshold)
+	default:
+		result.Status = ClusterStatusHealthy
+	}
+
+	return result
+}
+
+// isAuthError 判断是否为认证/授权错误
+func isAuthError(err error) bool {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err)
+}
+
+// HealthMonitor 后台集群健康检查
+type HealthMonitor struct {
+	db         *gorm.DB
+	k8sService *K8sService
+	interval   time.Duration
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewHealthMonitor 创建集群健康检查
+func NewHealthMonitor(db *gorm.DB, k8sService *K8sService) *HealthMonitor {
+	return &HealthMonitor{
+		db:         db,
+		k8sService: k8sService,
+		interval:   defaultHealthCheckInterval,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start 按 interval 周期性检查所有已注册的连接
+func (m *HealthMonitor) Start(interval time.Duration) {
+	if interval > 0 {
+		m.interval = interval
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		log.Printf("Cluster health monitor started, interval %s", m.interval)
+		m.CheckAll()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				m.CheckAll()
+			}
+		}
+	}()
+}
+
+// Stop 停止后台检查
+func (m *HealthMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+	m.wg.Wait()
+}
+
+// CheckAll 检查所有已注册的连接并清理过期记录
+func (m *HealthMonitor) CheckAll() {
+	var connections []model.K8sConnection
+	if err := m.db.Find(&connections).Error; err != nil {
+		log.Printf("Cluster health monitor: failed to load connections: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, healthCheckConcurrency)
+	for i := range connections {
+		wg.Add(1)
+		go func(connection *model.K8sConnection) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if _, err := m.Check(connection); err != nil {
+				log.Printf("Cluster health monitor: failed to record check for %s: %v", connection.Name, err)
+			}
+		}(&connections[i])
+	}
+	wg.Wait()
+
+	cutoff := time.Now().Add(-healthHistoryRetention)
+	if err := m.db.Where("checked_at < ?", cutoff).Delete(&model.ClusterHealthCheck{}).Error; err != nil {
+		log.Printf("Cluster health monitor: failed to prune history: %v", err)
+	}
+}
+
+// Check 探测单个连接，写入检查记录并更新连接状态
+func (m *HealthMonitor) Check(connection *model.K8sConnection) (*model.ClusterHealthCheck, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	result := m.k8sService.ProbeConnection(ctx, connection)
+	check := &model.ClusterHealthCheck{
+		ConnectionID:  connection.ID,
+		Status:        result.Status,
+		Reachable:     result.Reachable,
+		AuthError:     result.AuthError,
+		ServerVersion: result.ServerVersion,
+		LatencyMs:     result.Latency.Milliseconds(),
+		CheckedAt:     time.Now(),
+	}
+	if result.Err != nil {
+		check.Error = result.Err.Error()
+	}
+
+	if connection.Status != result.Status && connection.Status != "" {
+		log.Printf("Kubernetes connection %s status changed: %s -> %s", connection.Name, connection.Status, result.Status)
+	}
+
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(check).Error; err != nil {
+			return err
+		}
+		// UpdateColumns 不会修改 UpdatedAt，避免状态更新使连接池中的客户端失效
+		return tx.Model(connection).UpdateColumns(map[string]interface{}{
+			"status":          check.Status,
+			"status_message":  check.Error,
+			"last_checked_at": check.CheckedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	connection.Status = check.Status
+	connection.StatusMessage = check.Error
+	connection.LastCheckedAt = &check.CheckedAt
+	return check, nil
+}
+
+// GetHealth 获取连接的当前健康状态
+func (m *HealthMonitor) GetHealth(connection *model.K8sConnection) (*ClusterHealth, error) {
+	health := &ClusterHealth{
+		ConnectionID:  connection.ID,
+		Cluster:       connection.Name,
+		Status:        connection.Status,
+		StatusMessage: connection.StatusMessage,
+		LastCheckedAt: connection.LastCheckedAt,
+	}
+	if health.Status == "" {
+		health.Status = ClusterStatusUnknown
+	}
+
+	history, err := m.GetHistory(connection.ID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) > 0 {
+		health.LastCheck = &history[0]
+	}
+
+	return health, nil
+}
+
+// GetHistory 获取连接最近的健康检查记录
+func (m *HealthMonitor) GetHistory(connectionID uint, limit int) ([]model.ClusterHealthCheck, error) {
+	var history []model.ClusterHealthCheck
+	err := m.db.Where("connection_id = ?", connectionID).
+		Order("checked_at DESC").
+		Limit(limit).
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"container-platform-backend/internal/model"
+)
+
+// failReactor 使 verb/resource 请求返回 err
+func failReactor(clientSet *fake.Clientset, verb, resource string, err error) {
+	clientSet.PrependReactor(verb, resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, err
+	})
+}
+
+func TestProbeConnection(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("access denied"))
+
+	tests := []struct {
+		name          string
+		setup         func(*fake.Clientset)
+		wantStatus    string
+		wantReachable bool
+		wantAuthError bool
+		wantErr       string
+	}{
+		{
+			name:          "healthy",
+			wantStatus:    ClusterStatusHealthy,
+			wantReachable: true,
+		},
+		{
+			name:          "cannot list pods",
+			setup:         func(c *fake.Clientset) { failReactor(c, "list", "pods", forbidden) },
+			wantStatus:    ClusterStatusDegraded,
+			wantReachable: true,
+			wantAuthError: true,
+			wantErr:       "cannot list pods in namespace default",
+		},
+		{
+			name:          "list error",
+			setup:         func(c *fake.Clientset) { failReactor(c, "list", "pods", errors.New("etcdserver: request timed out")) },
+			wantStatus:    ClusterStatusDegraded,
+			wantReachable: true,
+			wantErr:       "etcdserver",
+		},
+		{
+			name:          "invalid credentials",
+			setup:         func(c *fake.Clientset) { failReactor(c, "get", "version", apierrors.NewUnauthorized("invalid token")) },
+			wantStatus:    ClusterStatusDegraded,
+			wantReachable: true,
+			wantAuthError: true,
+			wantErr:       "invalid token",
+		},
+		{
+			name:       "unreachable",
+			setup:      func(c *fake.Clientset) { failReactor(c, "get", "version", errors.New("dial tcp: connection refused")) },
+			wantStatus: ClusterStatusUnreachable,
+			wantErr:    "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, clientSet := newFakeService(t)
+			clientSet.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.28.3"}
+			if tt.setup != nil {
+				tt.setup(clientSet)
+			}
+
+			result := service.ProbeConnection(context.Background(), newTestConnection())
+			if result.Status != tt.wantStatus || result.Reachable != tt.wantReachable || result.AuthError != tt.wantAuthError {
+				t.Errorf("result = %+v, want status %s, reachable %v, auth error %v", result, tt.wantStatus, tt.wantReachable, tt.wantAuthError)
+			}
+			if tt.wantErr == "" {
+				if result.Err != nil || result.ServerVersion != "v1.28.3" {
+					t.Errorf("Err = %v, ServerVersion = %q", result.Err, result.ServerVersion)
+				}
+				return
+			}
+			if result.Err == nil || !strings.Contains(result.Err.Error(), tt.wantErr) {
+				t.Errorf("Err = %v, want it to contain %q", result.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProbeConnectionCertificateError(t *testing.T) {
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"gitVersion":"v1.28.3"}`))
+	}))
+	defer apiServer.Close()
+
+	service := NewK8sService()
+	t.Cleanup(service.pool.Close)
+	connection := newTestConnection()
+	connection.Endpoint = apiServer.URL
+
+	// 未配置 CA 时无法校验测试服务器的自签名证书
+	result := service.ProbeConnection(context.Background(), connection)
+	var certErr *CertificateError
+	if result.Status != ClusterStatusUnreachable || !errors.As(result.Err, &certErr) || certErr.Reason != CertificateUnknownAuthority {
+		t.Errorf("result = %+v, want unreachable with an unknown authority certificate error", result)
+	}
+}
+
+// newHealthMonitorDB 返回查询连接时得到 connections 的健康检查
+func newHealthMonitorDB(t *testing.T, service *K8sService, connections ...model.K8sConnection) (*HealthMonitor, *recordingDriver) {
+	t.Helper()
+
+	db, recorder := newRecordingDB(t, 0)
+	recorder.results = func(query string) *recordingRows {
+		if strings.HasPrefix(query, `SELECT * FROM "k8s_connections"`) {
+			return connectionRows(connections...)
+		}
+		return nil
+	}
+	return NewHealthMonitor(db, service), recorder
+}
+
+func TestHealthMonitorCheckAll(t *testing.T) {
+	service, clientSet := newFakeService(t)
+	failReactor(clientSet, "list", "pods", errors.New("etcdserver: request timed out"))
+	monitor, recorder := newHealthMonitorDB(t, service, *newTestConnection())
+
+	start := time.Now()
+	monitor.CheckAll()
+
+	inserts := recorder.matching(`INSERT INTO "cluster_health_checks"`)
+	if len(inserts) != 1 {
+		t.Fatalf("got %d health checks, want 1", len(inserts))
+	}
+	updates := recorder.matching(`UPDATE "k8s_connections"`)
+	if len(updates) != 1 {
+		t.Fatalf("got %d status updates, want 1", len(updates))
+	}
+	if _, ok := namedArg(updates[0].args, func(v driver.Value) bool { return v == ClusterStatusDegraded }); !ok {
+		t.Errorf("status update = %+v, want degraded", updates[0].args)
+	}
+
+	// 超过保留时间的记录被清理
+	deletes := recorder.matching(`DELETE FROM "cluster_health_checks"`)
+	if len(deletes) != 1 || !strings.Contains(deletes[0].query, "checked_at <") {
+		t.Fatalf("deletes = %+v, want history pruning", deletes)
+	}
+	cutoff, ok := deletes[0].args[0].Value.(time.Time)
+	if !ok || cutoff.Before(start.Add(-healthHistoryRetention)) || cutoff.After(time.Now().Add(-healthHistoryRetention)) {
+		t.Errorf("cutoff = %v, want about %v before now", deletes[0].args[0].Value, healthHistoryRetention)
+	}
+}
+
+func TestHealthMonitorStop(t *testing.T) {
+	service, _ := newFakeService(t)
+	monitor, recorder := newHealthMonitorDB(t, service, *newTestConnection())
+	loads := func() int { return len(recorder.matching(`SELECT * FROM "k8s_connections"`)) }
+
+	monitor.Start(10 * time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for loads() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("monitor did not run periodic checks")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		monitor.Stop()
+		// 重复停止不会阻塞或 panic
+		monitor.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop() did not return")
+	}
+
+	checks := loads()
+	time.Sleep(50 * time.Millisecond)
+	if got := loads(); got != checks {
+		t.Errorf("monitor ran %d checks after Stop()", got-checks)
+	}
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"container-platform-backend/internal/model"
+)
+
+// 镜像发布状态
+const (
+	RolloutProgressing = "progressing"
+	RolloutAvailable   = "available"
+	RolloutFailed      = "failed"
+	// RolloutSuperseded 发布完成前镜像再次被修改，不再跟踪
+	RolloutSuperseded = "superseded"
+)
+
+const (
+	// changeCauseAnnotation 与 kubectl rollout history 显示的变更原因相同
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	// revisionAnnotation Deployment 控制器维护的修订号
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// failedWaitingReasons 容器处于这些等待原因时视为发布失败，不再等待进度超时
+var failedWaitingReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"ErrImageNeverPull":          true,
+	"CreateContainerConfigError": true,
+	"CrashLoopBackOff":           true,
+}
+
+// ImageUpdate 修改镜像的结果
+type ImageUpdate struct {
+	Kind          string `json:"kind"`
+	Name          string `json:"name"`
+	Container     string `json:"container"`
+	PreviousImage string `json:"previousImage"`
+	Image         string `json:"image"`
+	// 工作负载的创建时间，用于补全首个修订
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RolloutStatus 工作负载中容器镜像的发布进度
+type RolloutStatus struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	// progressing、available 或 failed
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Deployment 的副本数和修订号，独立 Pod 为空
+	Replicas          int32  `json:"replicas,omitempty"`
+	UpdatedReplicas   int32  `json:"updatedReplicas,omitempty"`
+	AvailableReplicas int32  `json:"availableReplicas,omitempty"`
+	Revision          string `json:"revision,omitempty"`
+}
+
+// UpdateContainerImage 修改 Deployment 或独立 Pod 中容器的镜像，Deployment 会触发滚动更新
+// containerName 为空时修改第一个容器，changeCause 写入 Deployment 的变更原因注解
+func (s *K8sService) UpdateContainerImage(ctx context.Context, connection *model.K8sConnection, namespace, podName, containerName, image, changeCause string) (*ImageUpdate, error) {
+	image = strings.TrimSpace(image)
+	if image == "" || strings.ContainsAny(image, " \t\n") {
+		return nil, ValidationErrors{{Field: "image", Message: "must be a valid image reference"}}
+	}
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &ImageUpdate{Kind: workload.kind(), Name: workload.name(), Image: image}
+	var podSpec *corev1.PodSpec
+	switch workload.kind() {
+	case WorkloadDeployment:
+		podSpec = &workload.deployment.Spec.Template.Spec
+		update.CreatedAt = workload.deployment.CreationTimestamp.Time
+	case WorkloadPod:
+		podSpec = &workload.pod.Spec
+		update.CreatedAt = workload.pod.CreationTimestamp.Time
+	default:
+		return nil, fmt.Errorf("%w: image can only be updated on deployments and pods, not %s %s", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+
+	index := containerIndex(podSpec.Containers, containerName)
+	if index < 0 {
+		return nil, ValidationErrors{{Field: "container", Message: fmt.Sprintf("container %q not found in %s %s", containerName, workload.kind(), workload.name())}}
+	}
+	update.Container = podSpec.Containers[index].Name
+	update.PreviousImage = podSpec.Containers[index].Image
+	if update.PreviousImage == image {
+		return nil, ValidationErrors{{Field: "image", Message: fmt.Sprintf("container %s already uses image %s", update.Container, image)}}
+	}
+
+	if workload.kind() == WorkloadDeployment {
+		err = updateDeployment(ctx, clientSet, namespace, workload.name(), func(deployment *appsv1.Deployment) {
+			setContainerImage(&deployment.Spec.Template.Spec, update.Container, image)
+			if changeCause != "" {
+				if deployment.Annotations == nil {
+					deployment.Annotations = make(map[string]string)
+				}
+				deployment.Annotations[changeCauseAnnotation] = changeCause
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update deployment: %w", err)
+		}
+	} else {
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			pod, err := clientSet.CoreV1().Pods(namespace).Get(ctx, workload.name(), metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			setContainerImage(&pod.Spec, update.Container, image)
+			_, err = clientSet.CoreV1().Pods(namespace).Update(ctx, pod, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update pod: %w", err)
+		}
+	}
+
+	log.Printf("Updated image of %s %s/%s container %s: %s -> %s", update.Kind, namespace, update.Name, update.Container, update.PreviousImage, image)
+	return update, nil
+}
+
+// setContainerImage 修改指定名称容器的镜像
+func setContainerImage(podSpec *corev1.PodSpec, containerName, image string) {
+	if index := containerIndex(podSpec.Containers, containerName); index >= 0 {
+		podSpec.Containers[index].Image = image
+	}
+}
+
+// GetRolloutStatus 获取 Deployment 或独立 Pod 中容器镜像的发布进度
+// containerName 为空时使用第一个容器
+func (s *K8sService) GetRolloutStatus(ctx context.Context, connection *model.K8sConnection, namespace, podName, containerName string) (*RolloutStatus, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch workload.kind() {
+	case WorkloadDeployment:
+		return deploymentRollout(ctx, clientSet, workload.deployment, containerName)
+	case WorkloadPod:
+		return podRollout(workload.pod, containerName)
+	default:
+		return nil, fmt.Errorf("%w: rollout status is only available for deployments and pods, not %s %s", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+}
+
+// deploymentRollout 按 kubectl rollout status 的规则判断 Deployment 的发布进度，
+// 新 Pod 的容器拉取镜像失败或反复崩溃时直接视为失败
+func deploymentRollout(ctx context.Context, clientSet kubernetes.Interface, deployment *appsv1.Deployment, containerName string) (*RolloutStatus, error) {
+	containers := deployment.Spec.Template.Spec.Containers
+	index := containerIndex(containers, containerName)
+	if index < 0 {
+		return nil, ValidationErrors{{Field: "container", Message: fmt.Sprintf("container %q not found in deployment %s", containerName, deployment.Name)}}
+	}
+
+	rollout := &RolloutStatus{
+		Kind:              WorkloadDeployment,
+		Name:              deployment.Name,
+		Container:         containers[index].Name,
+		Image:             containers[index].Image,
+		Replicas:          deploymentReplicas(deployment),
+		UpdatedReplicas:   deployment.Status.UpdatedReplicas,
+		AvailableReplicas: deployment.Status.AvailableReplicas,
+		Revision:          deployment.Annotations[revisionAnnotation],
+	}
+	rollout.Status, rollout.Message = deploymentRolloutStatus(deployment)
+	if rollout.Status != RolloutProgressing || deployment.Spec.Selector == nil {
+		return rollout, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return rollout, nil
+	}
+	pods, err := clientSet.CoreV1().Pods(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		podIndex := containerIndex(pod.Spec.Containers, rollout.Container)
+		if podIndex < 0 || pod.Spec.Containers[podIndex].Image != rollout.Image {
+			continue
+		}
+		if reason := containerFailure(pod, rollout.Container); reason != "" {
+			rollout.Status = RolloutFailed
+			rollout.Message = fmt.Sprintf("pod %s: %s", pod.Name, reason)
+			break
+		}
+	}
+	return rollout, nil
+}
+
+// deploymentRolloutStatus 根据 Deployment 的状态判断发布是否完成
+func deploymentRolloutStatus(deployment *appsv1.Deployment) (string, string) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return RolloutProgressing, "waiting for the deployment spec update to be observed"
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return RolloutFailed, fmt.Sprintf("deployment %s exceeded its progress deadline", deployment.Name)
+		}
+	}
+
+	replicas := deploymentReplicas(deployment)
+	status := deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas:
+		return RolloutProgressing, fmt.Sprintf("%d of %d new replicas have been updated", status.UpdatedReplicas, replicas)
+	case status.Replicas > status.UpdatedReplicas:
+		return RolloutProgressing, fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return RolloutProgressing, fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas)
+	}
+	return RolloutAvailable, fmt.Sprintf("deployment %s successfully rolled out", deployment.Name)
+}
+
+// podRollout 判断独立 Pod 的容器是否已使用新镜像运行并就绪
+func podRollout(pod *corev1.Pod, containerName string) (*RolloutStatus, error) {
+	index := containerIndex(pod.Spec.Containers, containerName)
+	if index < 0 {
+		return nil, ValidationErrors{{Field: "container", Message: fmt.Sprintf("container %q not found in pod %s", containerName, pod.Name)}}
+	}
+
+	rollout := &RolloutStatus{
+		Kind:      WorkloadPod,
+		Name:      pod.Name,
+		Container: pod.Spec.Containers[index].Name,
+		Image:     pod.Spec.Containers[index].Image,
+		Status:    RolloutProgressing,
+		Message:   fmt.Sprintf("waiting for container %s to restart with the new image", pod.Spec.Containers[index].Name),
+	}
+	if reason := containerFailure(pod, rollout.Container); reason != "" {
+		rollout.Status, rollout.Message = RolloutFailed, reason
+		return rollout, nil
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != rollout.Container || !sameImage(status.Image, rollout.Image) {
+			continue
+		}
+		if status.State.Running != nil && status.Ready {
+			rollout.Status = RolloutAvailable
+			rollout.Message = fmt.Sprintf("container %s is running image %s", rollout.Container, rollout.Image)
+		} else {
+			rollout.Message = fmt.Sprintf("waiting for container %s to become ready", rollout.Container)
+		}
+	}
+	return rollout, nil
+}
+
+// containerFailure 容器因镜像或配置错误无法启动时返回原因，否则返回空字符串
+func containerFailure(pod *corev1.Pod, containerName string) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != containerName || status.State.Waiting == nil {
+			continue
+		}
+		waiting := status.State.Waiting
+		if failedWaitingReasons[waiting.Reason] {
+			if waiting.Message != "" {
+				return fmt.Sprintf("container %s is waiting: %s: %s", containerName, waiting.Reason, waiting.Message)
+			}
+			return fmt.Sprintf("container %s is waiting: %s", containerName, waiting.Reason)
+		}
+	}
+	return ""
+}
+
+// sameImage 比较容器状态中的镜像和 spec 中的镜像，状态中的镜像可能补全了仓库地址和 latest 标签
+func sameImage(statusImage, specImage string) bool {
+	if statusImage == specImage || strings.HasSuffix(statusImage, "/"+specImage) {
+		return true
+	}
+	if _, tag, digest := splitImageReference(specImage); tag == "latest" && digest == "" && !strings.HasSuffix(specImage, ":latest") {
+		return sameImage(statusImage, specImage+":latest")
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateContainerImageDeployment(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 2)
+	service, clientSet := newFakeService(t, deployment, replicaSet, pod)
+
+	update, err := service.UpdateContainerImage(context.Background(), newTestConnection(), "default", pod.Name, "", "nginx:1.27", "update image to nginx:1.27")
+	if err != nil {
+		t.Fatalf("UpdateContainerImage() error = %v", err)
+	}
+	want := ImageUpdate{Kind: WorkloadDeployment, Name: "web", Container: "web", PreviousImage: "nginx", Image: "nginx:1.27"}
+	if update.Kind != want.Kind || update.Name != want.Name || update.Container != want.Container || update.PreviousImage != want.PreviousImage || update.Image != want.Image {
+		t.Errorf("update = %+v, want %+v", update, want)
+	}
+
+	updated := getDeployment(t, clientSet, "default", "web")
+	if image := updated.Spec.Template.Spec.Containers[0].Image; image != "nginx:1.27" {
+		t.Errorf("image = %q, want nginx:1.27", image)
+	}
+	if cause := updated.Annotations[changeCauseAnnotation]; cause != "update image to nginx:1.27" {
+		t.Errorf("change cause = %q", cause)
+	}
+
+	_, err = service.UpdateContainerImage(context.Background(), newTestConnection(), "default", "web", "web", "nginx:1.27", "")
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) || validationErrs[0].Field != "image" {
+		t.Errorf("UpdateContainerImage() with unchanged image error = %v, want image validation error", err)
+	}
+}
+
+func TestUpdateContainerImagePod(t *testing.T) {
+	service, clientSet := newFakeService(t, newTestPod("worker", "default"))
+
+	update, err := service.UpdateContainerImage(context.Background(), newTestConnection(), "default", "worker", "app", "nginx:1.27", "")
+	if err != nil {
+		t.Fatalf("UpdateContainerImage() error = %v", err)
+	}
+	if update.Kind != WorkloadPod || update.PreviousImage != "nginx:1.25" || update.CreatedAt.IsZero() {
+		t.Errorf("update = %+v", update)
+	}
+
+	pod, err := clientSet.CoreV1().Pods("default").Get(context.Background(), "worker", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := pod.Spec.Containers[0].Image; image != "nginx:1.27" {
+		t.Errorf("image = %q, want nginx:1.27", image)
+	}
+}
+
+func TestUpdateContainerImageErrors(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"},
+		Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate", Image: "migrate:1"}}},
+		}},
+	}
+	service, _ := newFakeService(t, newTestPod("worker", "default"), job)
+
+	tests := []struct {
+		name      string
+		podName   string
+		container string
+		image     string
+		field     string
+		wantErr   error
+	}{
+		{name: "empty image", podName: "worker", image: " ", field: "image"},
+		{name: "image with spaces", podName: "worker", image: "nginx 1.27", field: "image"},
+		{name: "missing container", podName: "worker", container: "sidecar", image: "nginx:1.27", field: "container"},
+		{name: "job", podName: "migrate", image: "migrate:2", wantErr: ErrUnsupportedWorkload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.UpdateContainerImage(context.Background(), newTestConnection(), "default", tt.podName, tt.container, tt.image, "")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) || validationErrs[0].Field != tt.field {
+				t.Errorf("error = %v, want validation error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*appsv1.Deployment)
+		want   string
+	}{
+		{
+			name: "spec not observed",
+			mutate: func(d *appsv1.Deployment) {
+				d.Generation = 3
+				d.Status.ObservedGeneration = 2
+			},
+			want: RolloutProgressing,
+		},
+		{
+			name: "progress deadline exceeded",
+			mutate: func(d *appsv1.Deployment) {
+				d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"}}
+			},
+			want: RolloutFailed,
+		},
+		{
+			name:   "replicas not updated",
+			mutate: func(d *appsv1.Deployment) { d.Status.UpdatedReplicas = 1 },
+			want:   RolloutProgressing,
+		},
+		{
+			name:   "old replicas terminating",
+			mutate: func(d *appsv1.Deployment) { d.Status.Replicas = 3 },
+			want:   RolloutProgressing,
+		},
+		{
+			name:   "updated replicas unavailable",
+			mutate: func(d *appsv1.Deployment) { d.Status.AvailableReplicas = 1 },
+			want:   RolloutProgressing,
+		},
+		{
+			name:   "complete",
+			mutate: func(d *appsv1.Deployment) {},
+			want:   RolloutAvailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment, _, _ := newTestDeployment("web", "default", 2)
+			deployment.Generation = 2
+			deployment.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+			tt.mutate(deployment)
+
+			if got, message := deploymentRolloutStatus(deployment); got != tt.want {
+				t.Errorf("deploymentRolloutStatus() = %s (%s), want %s", got, message, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRolloutStatusFailsOnImagePull(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 1)
+	deployment.Spec.Template.Spec.Containers[0].Image = "nginx:missing"
+	deployment.Annotations = map[string]string{revisionAnnotation: "2"}
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1}
+
+	newPod := newTestPod("web-5c6b7-q8zr2", "default", corev1.ContainerStatus{
+		Name:  "web",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+	})
+	newPod.Labels = pod.Labels
+	newPod.Spec.Containers = []corev1.Container{{Name: "web", Image: "nginx:missing"}}
+	service, _ := newFakeService(t, deployment, replicaSet, pod, newPod)
+
+	status, err := service.GetRolloutStatus(context.Background(), newTestConnection(), "default", "web", "")
+	if err != nil {
+		t.Fatalf("GetRolloutStatus() error = %v", err)
+	}
+	if status.Status != RolloutFailed || status.Container != "web" || status.Image != "nginx:missing" || status.Revision != "2" {
+		t.Errorf("status = %+v, want failed rollout of nginx:missing", status)
+	}
+	if status.Replicas != 1 || status.UpdatedReplicas != 1 || status.AvailableReplicas != 1 {
+		t.Errorf("replicas = %d/%d/%d", status.Replicas, status.UpdatedReplicas, status.AvailableReplicas)
+	}
+}
+
+func TestPodRollout(t *testing.T) {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	tests := []struct {
+		name   string
+		image  string
+		status corev1.ContainerStatus
+		want   string
+	}{
+		{
+			name:   "old image still running",
+			image:  "nginx:1.27",
+			status: corev1.ContainerStatus{Name: "app", Image: "docker.io/library/nginx:1.25", State: running, Ready: true},
+			want:   RolloutProgressing,
+		},
+		{
+			name:   "new image not ready",
+			image:  "nginx:1.27",
+			status: corev1.ContainerStatus{Name: "app", Image: "docker.io/library/nginx:1.27", State: running},
+			want:   RolloutProgressing,
+		},
+		{
+			name:   "new image ready",
+			image:  "nginx:1.27",
+			status: corev1.ContainerStatus{Name: "app", Image: "docker.io/library/nginx:1.27", State: running, Ready: true},
+			want:   RolloutAvailable,
+		},
+		{
+			name:   "implicit latest tag",
+			image:  "nginx",
+			status: corev1.ContainerStatus{Name: "app", Image: "docker.io/library/nginx:latest", State: running, Ready: true},
+			want:   RolloutAvailable,
+		},
+		{
+			name:   "image pull error",
+			image:  "nginx:missing",
+			status: corev1.ContainerStatus{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}}},
+			want:   RolloutFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newTestPod("worker", "default", tt.status)
+			pod.Spec.Containers[0].Image = tt.image
+
+			rollout, err := podRollout(pod, "")
+			if err != nil {
+				t.Fatalf("podRollout() error = %v", err)
+			}
+			if rollout.Status != tt.want {
+				t.Errorf("status = %s (%s), want %s", rollout.Status, rollout.Message, tt.want)
+			}
+		})
+	}
+}
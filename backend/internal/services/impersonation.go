@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/model"
+)
+
+// 连接的身份模拟模式
+const (
+	// ImpersonationNone 使用连接自身的凭据，不做身份模拟
+	ImpersonationNone = "none"
+	// ImpersonationFixed 模拟固定的用户/ServiceAccount/用户组
+	ImpersonationFixed = "fixed"
+	// ImpersonationPlatformUser 将当前登录的平台用户映射为 Kubernetes 身份进行模拟
+	ImpersonationPlatformUser = "platform_user"
+)
+
+// defaultImpersonateUserTemplate platform_user 模式下默认的用户名映射
+const defaultImpersonateUserTemplate = "{username}"
+
+// ErrPlatformUserRequired 连接需要模拟平台用户，但请求未携带已登录用户
+var ErrPlatformUserRequired = errors.New("connection impersonates the platform user but the request is not authenticated")
+
+// PlatformUser 发起请求的平台用户
+type PlatformUser struct {
+	ID       uint
+	Username string
+	Role     string
+}
+
+type platformUserKey struct{}
+
+// WithPlatformUser 将平台用户附加到上下文
+func WithPlatformUser(ctx context.Context, user *PlatformUser) context.Context {
+	if user == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, platformUserKey{}, user)
+}
+
+// PlatformUserFromContext 从上下文中获取平台用户
+func PlatformUserFromContext(ctx context.Context) (*PlatformUser, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	user, ok := ctx.Value(platformUserKey{}).(*PlatformUser)
+	return user, ok && user != nil && user.Username != ""
+}
+
+// fixedImpersonation fixed 模式下的身份模拟配置
+func fixedImpersonation(connection *model.K8sConnection) rest.ImpersonationConfig {
+	return rest.ImpersonationConfig{
+		UserName: connection.ImpersonateUser,
+		Groups:   splitImpersonateGroups(connection.ImpersonateGroups, nil),
+	}
+}
+
+// platformUserImpersonation platform_user 模式下将平台用户映射为 Kubernetes 身份
+func platformUserImpersonation(connection *model.K8sConnection, user *PlatformUser) rest.ImpersonationConfig {
+	template := connection.ImpersonateUser
+	if template == "" {
+		template = defaultImpersonateUserTemplate
+	}
+
+	return rest.ImpersonationConfig{
+		UserName: expandImpersonateTemplate(template, user),
+		Groups:   splitImpersonateGroups(connection.ImpersonateGroups, user),
+	}
+}
+
+// validateImpersonation 校验连接的身份模拟设置
+func validateImpersonation(connection *model.K8sConnection) error {
+	switch connection.ImpersonationMode {
+	case "", ImpersonationNone:
+		connection.ImpersonationMode = ImpersonationNone
+	case ImpersonationFixed:
+		if connection.ImpersonateUser == "" {
+			return fmt.Errorf("%w: impersonateUser is required for fixed impersonation", ErrInvalidConnection)
+		}
+	case ImpersonationPlatformUser:
+		if connection.ImpersonateUser != "" && !strings.Contains(connection.ImpersonateUser, "{username}") {
+			return fmt.Errorf("%w: impersonateUser template must contain {username}", ErrInvalidConnection)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported impersonation mode %q", ErrInvalidConnection, connection.ImpersonationMode)
+	}
+	return nil
+}
+
+// expandImpersonateTemplate 替换模板中的 {username} 和 {role}
+func expandImpersonateTemplate(template string, user *PlatformUser) string {
+	if user == nil {
+		return template
+	}
+	return strings.NewReplacer("{username}", user.Username, "{role}", user.Role).Replace(template)
+}
+
+// splitImpersonateGroups 解析逗号分隔的用户组列表
+func splitImpersonateGroups(groups string, user *PlatformUser) []string {
+	var result []string
+	for _, group := range strings.Split(groups, ",") {
+		group = strings.TrimSpace(expandImpersonateTemplate(group, user))
+		if group != "" {
+			result = append(result, group)
+		}
+	}
+	return result
+}
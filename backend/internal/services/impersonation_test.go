@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandImpersonateTemplate(t *testing.T) {
+	alice := &PlatformUser{ID: 1, Username: "alice", Role: "developer"}
+
+	tests := []struct {
+		template string
+		user     *PlatformUser
+		want     string
+	}{
+		{"{username}", alice, "alice"},
+		{"platform:{username}", alice, "platform:alice"},
+		{"role:{role}", alice, "role:developer"},
+		{"{username}-{username}", alice, "alice-alice"},
+		{"system:serviceaccount:ops:deployer", alice, "system:serviceaccount:ops:deployer"},
+		{"platform:{username}", nil, "platform:{username}"},
+	}
+
+	for _, tt := range tests {
+		if got := expandImpersonateTemplate(tt.template, tt.user); got != tt.want {
+			t.Errorf("expandImpersonateTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestSplitImpersonateGroups(t *testing.T) {
+	alice := &PlatformUser{ID: 1, Username: "alice", Role: "admin"}
+
+	tests := []struct {
+		groups string
+		user   *PlatformUser
+		want   []string
+	}{
+		{"", nil, nil},
+		{" , ,", nil, nil},
+		{"system:masters", nil, []string{"system:masters"}},
+		{"developers, ops ,,qa", nil, []string{"developers", "ops", "qa"}},
+		{"platform:{role}, users", alice, []string{"platform:admin", "users"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitImpersonateGroups(tt.groups, tt.user); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitImpersonateGroups(%q) = %q, want %q", tt.groups, got, tt.want)
+		}
+	}
+}
+
+func TestPlatformUserImpersonation(t *testing.T) {
+	connection := newTestConnection()
+	connection.ImpersonationMode = ImpersonationPlatformUser
+	connection.ImpersonateGroups = "platform:{role}"
+	user := &PlatformUser{ID: 1, Username: "alice", Role: "viewer"}
+
+	impersonation := platformUserImpersonation(connection, user)
+	if impersonation.UserName != "alice" || !reflect.DeepEqual(impersonation.Groups, []string{"platform:viewer"}) {
+		t.Errorf("impersonation = %+v", impersonation)
+	}
+
+	connection.ImpersonateUser = "oidc:{username}"
+	if impersonation := platformUserImpersonation(connection, user); impersonation.UserName != "oidc:alice" {
+		t.Errorf("UserName = %q, want oidc:alice", impersonation.UserName)
+	}
+}
+
+func TestValidateImpersonation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		user    string
+		wantErr bool
+	}{
+		{name: "default", mode: ""},
+		{name: "none", mode: ImpersonationNone},
+		{name: "fixed", mode: ImpersonationFixed, user: "system:serviceaccount:ops:deployer"},
+		{name: "fixed without user", mode: ImpersonationFixed, wantErr: true},
+		{name: "platform user default template", mode: ImpersonationPlatformUser},
+		{name: "platform user template", mode: ImpersonationPlatformUser, user: "oidc:{username}"},
+		{name: "platform user template without username", mode: ImpersonationPlatformUser, user: "oidc:{role}", wantErr: true},
+		{name: "unknown mode", mode: "sudo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connection := newTestConnection()
+			connection.ImpersonationMode = tt.mode
+			connection.ImpersonateUser = tt.user
+
+			err := validateImpersonation(connection)
+			if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, ErrInvalidConnection)) {
+				t.Errorf("validateImpersonation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.mode == "" && connection.ImpersonationMode != ImpersonationNone {
+				t.Errorf("ImpersonationMode = %q, want none", connection.ImpersonationMode)
+			}
+		})
+	}
+}
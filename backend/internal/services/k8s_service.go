@@ -2,147 +2,208 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
-	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/remotecommand"
 
+	"container-platform-backend/internal/k8s"
 	"container-platform-backend/internal/model"
 )
 
 type K8sService struct {
-	clientSet *kubernetes.Clientset
-	config    *rest.Config
+	pool    *ClientPool
+	factory k8s.ClientsetFactory
+	// executor 创建 exec 子资源的流式执行器，测试时可替换
+	executor ExecutorFactory
+	// portForwarder 创建 portforward 子资源的流连接器，测试时可替换
+	portForwarder PortForwardDialerFactory
 }
 
 type ContainerInfo struct {
-	Name         string            `json:"name"`
-	Namespace    string            `json:"namespace"`
-	Image        string            `json:"image"`
-	Status       string            `json:"status"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Image     string `json:"image"`
+	Status    string `json:"status"`
+	// 容器是否就绪（运行中且通过就绪探针）
+	Ready        bool              `json:"ready"`
 	PodName      string            `json:"podName"`
 	RestartCount int32             `json:"restartCount"`
 	Age          string            `json:"age"`
 	Node         string            `json:"node,omitempty"`
 	Labels       map[string]string `json:"labels,omitempty"`
 	ContainerID  string            `json:"containerId,omitempty"`
+	ConnectionID uint              `json:"connectionId,omitempty"`
+	Cluster      string            `json:"cluster,omitempty"`
+	// 所属工作负载：deployment、job、cronjob 或 pod
+	Workload     string `json:"workload,omitempty"`
+	WorkloadName string `json:"workloadName,omitempty"`
+	Replicas     *int32 `json:"replicas,omitempty"`
 }
 
 func NewK8sService() *K8sService {
-	return &K8sService{}
+	return NewK8sServiceWithFactory(k8s.DefaultClientsetFactory)
 }
 
-// ConnectToCluster 连接到 Kubernetes 集群
-func (s *K8sService) ConnectToCluster(connection *model.K8sConnection) error {
-	var config *rest.Config
-	var err error
-
-	if connection.ConfigType == "kubeconfig" {
-		// 使用 kubeconfig 文件连接
-		clusterConfig := api.NewConfig()
-		if err := json.Unmarshal([]byte(connection.Config), clusterConfig); err != nil {
-			return fmt.Errorf("failed to parse kubeconfig: %w", err)
-		}
+// NewK8sServiceWithFactory 使用指定的 clientset factory 创建服务，测试时可注入 fake clientset
+func NewK8sServiceWithFactory(factory k8s.ClientsetFactory) *K8sService {
+	return &K8sService{
+		pool:          NewClientPool(defaultClientIdleTimeout, factory),
+		factory:       factory,
+		executor:      remotecommand.NewSPDYExecutor,
+		portForwarder: newSPDYPortForwardDialer,
+	}
+}
 
-		// 创建临时的 kubeconfig 文件
-		tmpFile := "/tmp/kubeconfig-" + fmt.Sprintf("%d", connection.ID)
-		if err := clientcmd.WriteToFile(*clusterConfig, tmpFile); err != nil {
-			return fmt.Errorf("failed to write kubeconfig file: %w", err)
-		}
+// ConnectToCluster 连接到 Kubernetes 集群，已就绪的客户端直接从连接池复用
+func (s *K8sService) ConnectToCluster(ctx context.Context, connection *model.K8sConnection) error {
+	_, err := s.clientFor(ctx, connection)
+	return err
+}
 
-		config, err = clientcmd.BuildConfigFromFlags("", tmpFile)
-		if err != nil {
-			return fmt.Errorf("failed to build config from kubeconfig: %w", err)
-		}
-	} else {
-		// 使用 token 连接
-		config = &rest.Config{
-			Host:        connection.Endpoint,
-			BearerToken: connection.Token,
-			TLSClientConfig: rest.TLSClientConfig{
-				Insecure: true, // 生产环境应该配置 proper TLS
-			},
-		}
+// TestConnection 测试 K8s 连接，总是重新握手而不使用缓存
+// 证书校验失败时返回 *CertificateError，说明具体原因
+// 待测试的配置来自请求，不能使用内置连接的服务端凭据（本地 kubeconfig 或集群内 ServiceAccount）
+func (s *K8sService) TestConnection(ctx context.Context, connection *model.K8sConnection) error {
+	if connection.BuiltIn || connection.ConfigType == ConfigTypeLocal {
+		return fmt.Errorf("%w: config type %q is reserved for the built-in connection", ErrInvalidConnection, ConfigTypeLocal)
 	}
 
-	// 设置默认命名空间
-	if connection.Namespace != "" {
-		config.Impersonate = rest.ImpersonationConfig{
-			UserName: fmt.Sprintf("system:serviceaccount:%s:default", connection.Namespace),
-		}
+	config, err := buildRestConfig(connection)
+	if err != nil {
+		return err
 	}
 
-	// 创建 clientset
-	clientSet, err := kubernetes.NewForConfig(config)
+	clientSet, err := s.factory(config)
 	if err != nil {
 		return fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	s.clientSet = clientSet
-	s.config = config
+	if _, err := serverVersion(ctx, clientSet); err != nil {
+		return fmt.Errorf("failed to connect to cluster: %w", classifyCertificateError(err))
+	}
+	return nil
+}
+
+// InvalidateConnection 连接被修改或删除后丢弃其缓存的客户端
+func (s *K8sService) InvalidateConnection(connectionID uint) {
+	s.pool.Invalidate(connectionID)
+}
+
+// clientFor 获取连接对应的客户端，模拟平台用户时从 ctx 中获取当前用户
+func (s *K8sService) clientFor(ctx context.Context, connection *model.K8sConnection) (kubernetes.Interface, error) {
+	clientSet, _, err := s.clientAndConfigFor(ctx, connection)
+	return clientSet, err
+}
+
+// clientAndConfigFor 获取连接对应的客户端及其 rest.Config，用于 exec 等需要直接建立流的请求
+func (s *K8sService) clientAndConfigFor(ctx context.Context, connection *model.K8sConnection) (kubernetes.Interface, *rest.Config, error) {
+	if connection == nil {
+		return nil, nil, fmt.Errorf("kubernetes connection is required")
+	}
+
+	user, _ := PlatformUserFromContext(ctx)
+	return s.pool.Get(ctx, connection, user)
+}
 
-	// 测试连接
-	_, err = clientSet.ServerVersion()
+// streamingClientFor 获取不受请求超时限制的客户端，用于跟随日志等长时间保持的流
+func (s *K8sService) streamingClientFor(ctx context.Context, connection *model.K8sConnection) (kubernetes.Interface, error) {
+	_, config, err := s.clientAndConfigFor(ctx, connection)
 	if err != nil {
-		return fmt.Errorf("failed to connect to cluster: %w", err)
+		return nil, err
 	}
 
-	log.Printf("Successfully connected to Kubernetes cluster: %s", connection.Name)
-	return nil
+	clientSet, err := s.factory(streamingConfig(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return clientSet, nil
 }
 
-// TestConnection 测试 K8s 连接
-func (s *K8sService) TestConnection(connection *model.K8sConnection) error {
-	return s.ConnectToCluster(connection)
+// streamingConfig 返回去掉整体请求超时的配置副本，流的生命周期由 ctx 控制
+func streamingConfig(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Timeout = 0
+	return config
+}
+
+// buildRestConfig 根据连接配置构建 rest.Config
+func buildRestConfig(connection *model.K8sConnection) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+
+	switch connection.ConfigType {
+	case ConfigTypeKubeconfig:
+		// 使用 kubeconfig 连接，完全在内存中解析，不落盘
+		config, err = kubeconfigRestConfig(connection)
+		if err != nil {
+			return nil, err
+		}
+	case ConfigTypeLocal:
+		// 内置连接：指定的配置路径 -> 集群内配置 -> ~/.kube/config
+		config, err = localRestConfig(connection)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		// 使用 token 和/或客户端证书连接
+		config = &rest.Config{
+			Host:            connection.Endpoint,
+			BearerToken:     connection.Token,
+			TLSClientConfig: tokenTLSConfig(connection),
+		}
+		if connection.InsecureSkipTLSVerify {
+			log.Printf("WARNING: TLS verification is disabled for Kubernetes connection %q (id=%d)", connection.Name, connection.ID)
+		}
+	}
+
+	// 集群不可达时请求不会一直等待到 TCP 超时；跟随日志等长连接使用 streamingConfig
+	if config.Timeout == 0 {
+		config.Timeout = defaultClusterRequestTimeout
+	}
+
+	// 固定身份模拟；platform_user 模式在连接池中按请求用户派生
+	if connection.ImpersonationMode == ImpersonationFixed {
+		config.Impersonate = fixedImpersonation(connection)
+	}
+
+	return config, nil
 }
 
 // ListContainers 获取容器列表
-func (s *K8sService) ListContainers(namespace string) ([]ContainerInfo, error) {
-	if s.clientSet == nil {
-		return nil, fmt.Errorf("kubernetes client not initialized")
+// 已停止/暂停的工作负载没有 Pod，以其第一个容器作为 Stopped/Paused 条目返回。
+func (s *K8sService) ListContainers(ctx context.Context, connection *model.K8sConnection, namespace string) ([]ContainerInfo, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
 	}
 
-	pods, err := s.clientSet.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+	pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 
+	workloads := listWorkloads(ctx, clientSet, namespace)
+
 	var containers []ContainerInfo
+	hasPods := make(map[string]bool)
 	for _, pod := range pods.Items {
+		workload := workloads.ownerOf(&pod)
+		if workload.kind() != WorkloadPod {
+			hasPods[workload.kind()+"/"+pod.Namespace+"/"+workload.name()] = true
+		}
+
 		for _, container := range pod.Spec.Containers {
 			// 获取容器状态
-			status := "Unknown"
-			restartCount := int32(0)
-			containerID := ""
-
-			for _, containerStatus := range pod.Status.ContainerStatuses {
-				if containerStatus.Name == container.Name {
-					if containerStatus.State.Running != nil {
-						status = "Running"
-					} else if containerStatus.State.Waiting != nil {
-						status = "Pending"
-					} else if containerStatus.State.Terminated != nil {
-						if containerStatus.State.Terminated.ExitCode == 0 {
-							status = "Succeeded"
-						} else {
-							status = "Failed"
-						}
-					}
-					restartCount = containerStatus.RestartCount
-					containerID = containerStatus.ContainerID
-					break
-				}
-			}
+			status, restartCount, containerID := deriveContainerStatus(pod.Status.ContainerStatuses, container.Name)
 
 			// 计算容器年龄
 			age := calculateAge(pod.CreationTimestamp.Time)
@@ -152,146 +213,392 @@ func (s *K8sService) ListContainers(namespace string) ([]ContainerInfo, error) {
 				Namespace:    pod.Namespace,
 				Image:        container.Image,
 				Status:       status,
+				Ready:        status == "Running",
 				PodName:      pod.Name,
 				RestartCount: restartCount,
 				Age:          age,
 				Node:         pod.Spec.NodeName,
 				Labels:       pod.Labels,
 				ContainerID:  containerID,
+				ConnectionID: connection.ID,
+				Cluster:      connection.Name,
+				Workload:     workload.kind(),
+			}
+			if workload.kind() != WorkloadPod {
+				containerInfo.WorkloadName = workload.name()
+			}
+			if workload.deployment != nil {
+				replicas := deploymentReplicas(workload.deployment)
+				containerInfo.Replicas = &replicas
 			}
 
 			containers = append(containers, containerInfo)
 		}
 	}
 
+	// 没有 Pod 的已停止/暂停工作负载
+	idle := func(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec, status string, replicas *int32) {
+		if hasPods[kind+"/"+meta.Namespace+"/"+meta.Name] || len(spec.Containers) == 0 {
+			return
+		}
+		container := spec.Containers[0]
+		containers = append(containers, ContainerInfo{
+			Name:         container.Name,
+			Namespace:    meta.Namespace,
+			Image:        container.Image,
+			Status:       status,
+			PodName:      meta.Name,
+			Age:          calculateAge(meta.CreationTimestamp.Time),
+			Labels:       meta.Labels,
+			ConnectionID: connection.ID,
+			Cluster:      connection.Name,
+			Workload:     kind,
+			WorkloadName: meta.Name,
+			Replicas:     replicas,
+		})
+	}
+	for _, deployment := range workloads.deployments {
+		if deploymentReplicas(deployment) > 0 {
+			continue
+		}
+		replicas := int32(0)
+		status := "Stopped"
+		if deploymentPaused(deployment) {
+			status = "Paused"
+		}
+		idle(WorkloadDeployment, deployment.ObjectMeta, deployment.Spec.Template.Spec, status, &replicas)
+	}
+	for _, job := range workloads.jobs {
+		if isSuspended(job.Spec.Suspend) && metav1.GetControllerOf(job) == nil {
+			idle(WorkloadJob, job.ObjectMeta, job.Spec.Template.Spec, "Paused", nil)
+		}
+	}
+	for _, cronJob := range workloads.cronJobs {
+		if isSuspended(cronJob.Spec.Suspend) {
+			idle(WorkloadCronJob, cronJob.ObjectMeta, cronJob.Spec.JobTemplate.Spec.Template.Spec, "Paused", nil)
+		}
+	}
+
 	return containers, nil
 }
 
-// CreateContainer 创建容器 (通过创建 Pod)
-func (s *K8sService) CreateContainer(req *CreateContainerRequest) error {
-	if s.clientSet == nil {
-		return fmt.Errorf("kubernetes client not initialized")
-	}
-
-	// 解析环境变量
-	var envVars []corev1.EnvVar
-	if req.Env != "" {
-		envMap := parseEnvVars(req.Env)
-		for k, v := range envMap {
-			envVars = append(envVars, corev1.EnvVar{
-				Name:  k,
-				Value: v,
-			})
-		}
+// CreateContainer 创建容器，默认创建 Deployment，kind 为 pod 时创建独立 Pod
+func (s *K8sService) CreateContainer(ctx context.Context, connection *model.K8sConnection, req *CreateContainerRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	workload, err := req.workload()
+	if err != nil {
+		return err
 	}
 
-	// 解析端口映射
-	var ports []corev1.ContainerPort
-	if req.Ports != "" {
-		portMap := parsePortMappings(req.Ports)
-		for _, port := range portMap {
-			ports = append(ports, corev1.ContainerPort{
-				ContainerPort: port,
-			})
-		}
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return err
+	}
+	if err := checkVolumeClaims(ctx, clientSet, req.Namespace, req.Volumes); err != nil {
+		return err
+	}
+
+	if err := submitWorkload(ctx, clientSet, workload, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	switch obj := workload.(type) {
+	case *appsv1.Deployment:
+		log.Printf("Successfully created deployment: %s (replicas %d)", obj.Name, *obj.Spec.Replicas)
+	case *corev1.Pod:
+		log.Printf("Successfully created pod: %s", obj.Name)
 	}
+	return nil
+}
 
-	// 解析资源限制
-	var resources corev1.ResourceRequirements
-	if req.Resources != "" {
-		resources = parseResources(req.Resources)
+// workload 构建创建容器时提交的 Deployment 或 Pod
+func (r *CreateContainerRequest) workload() (runtime.Object, error) {
+	container, err := r.container()
+	if err != nil {
+		return nil, err
 	}
 
-	pod := &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      req.Name + "-pod",
-			Namespace: req.Namespace,
-			Labels: map[string]string{
-				"app":      req.Name,
-				"managed": "container-platform",
+	volumes, mounts := r.podVolumes()
+	container.VolumeMounts = mounts
+
+	labels := map[string]string{
+		appLabel:     r.Name,
+		managedLabel: managedLabelValue,
+	}
+	podSpec := corev1.PodSpec{
+		Containers:    []corev1.Container{container},
+		Volumes:       volumes,
+		RestartPolicy: corev1.RestartPolicyAlways,
+	}
+	r.HealthSpec.apply(&podSpec.Containers[0], &podSpec)
+
+	switch r.Kind {
+	case "", WorkloadDeployment:
+		replicas := defaultReplicas
+		if r.Replicas != nil {
+			replicas = *r.Replicas
+		}
+
+		return &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.WorkloadName(),
+				Namespace: r.Namespace,
+				Labels:    labels,
+				Annotations: map[string]string{
+					replicasAnnotation: strconv.Itoa(int(max(replicas, defaultReplicas))),
+				},
 			},
-		},
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{
-				{
-					Name:      req.Name,
-					Image:     req.Image,
-					Env:       envVars,
-					Ports:     ports,
-					Resources: resources,
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       podSpec,
 				},
 			},
-			RestartPolicy: corev1.RestartPolicyAlways,
-		},
+		}, nil
+	case WorkloadPod:
+		return &corev1.Pod{
+			TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.WorkloadName(),
+				Namespace: r.Namespace,
+				Labels:    labels,
+			},
+			Spec: podSpec,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported kind %q", ErrInvalidContainerRequest, r.Kind)
+	}
+}
+
+// submitWorkload 提交 Deployment 或 Pod，options 指定 DryRun 时只经过 API Server 校验和准入控制
+func submitWorkload(ctx context.Context, clientSet kubernetes.Interface, workload runtime.Object, options metav1.CreateOptions) error {
+	switch obj := workload.(type) {
+	case *appsv1.Deployment:
+		if _, err := clientSet.AppsV1().Deployments(obj.Namespace).Create(ctx, obj, options); err != nil {
+			return fmt.Errorf("failed to create deployment: %w", err)
+		}
+	case *corev1.Pod:
+		if _, err := clientSet.CoreV1().Pods(obj.Namespace).Create(ctx, obj, options); err != nil {
+			return fmt.Errorf("failed to create pod: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported workload %T", ErrInvalidContainerRequest, workload)
+	}
+	return nil
+}
+
+// StartContainer 启动容器：将 Deployment 恢复到停止前的副本数
+func (s *K8sService) StartContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) error {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return err
 	}
 
-	_, err := s.clientSet.CoreV1().Pods(req.Namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
 	if err != nil {
-		return fmt.Errorf("failed to create pod: %w", err)
+		return err
+	}
+	if workload.deployment == nil {
+		return fmt.Errorf("%w: %s %s cannot be started", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+
+	if err := scaleToRemembered(ctx, clientSet, namespace, workload.deployment.Name); err != nil {
+		return fmt.Errorf("failed to scale deployment: %w", err)
 	}
 
-	log.Printf("Successfully created pod: %s", pod.Name)
+	log.Printf("Successfully started deployment: %s", workload.deployment.Name)
 	return nil
 }
 
-// StartContainer 启动容器
-func (s *K8sService) StartContainer(namespace, podName string) error {
-	// 在 Kubernetes 中，Pod 的启动通常是通过创建或删除 Pod 来实现的
-	// 这里可以通过删除失败的 Pod 来让它重新启动
-	return s.RestartContainer(namespace, podName)
+// StopContainer 停止容器：记录副本数后将 Deployment 缩容到 0
+func (s *K8sService) StopContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) error {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return err
+	}
+	if workload.deployment == nil {
+		return fmt.Errorf("%w: %s %s cannot be stopped, delete it instead", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+
+	if err := scaleToZero(ctx, clientSet, namespace, workload.deployment.Name, false); err != nil {
+		return fmt.Errorf("failed to scale deployment: %w", err)
+	}
+
+	log.Printf("Successfully stopped deployment: %s", workload.deployment.Name)
+	return nil
 }
 
-// StopContainer 停止容器
-func (s *K8sService) StopContainer(namespace, podName string) error {
-	if s.clientSet == nil {
-		return fmt.Errorf("kubernetes client not initialized")
+// PauseContainer 暂停容器，保留完整的工作负载定义
+// Deployment 记录副本数后缩容到 0 并标记为暂停；Job/CronJob 设置 suspend。返回实际暂停的工作负载
+func (s *K8sService) PauseContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) (*ContainerWorkload, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
 	}
 
-	// 删除 Pod
-	deletePolicy := metav1.DeletePropagationForeground
-	err := s.clientSet.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{
-		PropagationPolicy: &deletePolicy,
-	})
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
 	if err != nil {
-		return fmt.Errorf("failed to delete pod: %w", err)
+		return nil, err
 	}
 
-	log.Printf("Successfully stopped pod: %s", podName)
-	return nil
+	switch workload.kind() {
+	case WorkloadDeployment:
+		err = scaleToZero(ctx, clientSet, namespace, workload.name(), true)
+	case WorkloadJob:
+		err = setJobSuspended(ctx, clientSet, namespace, workload.name(), true)
+	case WorkloadCronJob:
+		err = setCronJobSuspended(ctx, clientSet, namespace, workload.name(), true)
+	default:
+		return nil, fmt.Errorf("%w: pod %s is not managed by a deployment, job or cronjob", ErrUnsupportedWorkload, podName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause %s: %w", workload.kind(), err)
+	}
+
+	log.Printf("Successfully paused %s: %s", workload.kind(), workload.name())
+	return &ContainerWorkload{Kind: workload.kind(), Name: workload.name()}, nil
 }
 
-// RestartContainer 重启容器
-func (s *K8sService) RestartContainer(namespace, podName string) error {
-	if s.clientSet == nil {
-		return fmt.Errorf("kubernetes client not initialized")
+// ResumeContainer 恢复已暂停的容器，返回实际恢复的工作负载
+func (s *K8sService) ResumeContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) (*ContainerWorkload, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
 	}
 
-	// 删除现有的 Pod，让 Deployment 或 ReplicaSet 重新创建
-	err := s.clientSet.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{})
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
 	if err != nil {
-		return fmt.Errorf("failed to delete pod for restart: %w", err)
+		return nil, err
 	}
 
-	log.Printf("Successfully restarted pod: %s", podName)
-	return nil
+	switch workload.kind() {
+	case WorkloadDeployment:
+		if !deploymentPaused(workload.deployment) {
+			return nil, fmt.Errorf("%w: deployment %s", ErrContainerNotPaused, workload.name())
+		}
+		err = scaleToRemembered(ctx, clientSet, namespace, workload.name())
+	case WorkloadJob:
+		if !isSuspended(workload.job.Spec.Suspend) {
+			return nil, fmt.Errorf("%w: job %s", ErrContainerNotPaused, workload.name())
+		}
+		err = setJobSuspended(ctx, clientSet, namespace, workload.name(), false)
+	case WorkloadCronJob:
+		if !isSuspended(workload.cronJob.Spec.Suspend) {
+			return nil, fmt.Errorf("%w: cronjob %s", ErrContainerNotPaused, workload.name())
+		}
+		err = setCronJobSuspended(ctx, clientSet, namespace, workload.name(), false)
+	default:
+		return nil, fmt.Errorf("%w: pod %s is not managed by a deployment, job or cronjob", ErrUnsupportedWorkload, podName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume %s: %w", workload.kind(), err)
+	}
+
+	log.Printf("Successfully resumed %s: %s", workload.kind(), workload.name())
+	return &ContainerWorkload{Kind: workload.kind(), Name: workload.name()}, nil
 }
 
-// DeleteContainer 删除容器
-func (s *K8sService) DeleteContainer(namespace, podName string) error {
-	return s.StopContainer(namespace, podName)
+// RestartContainer 重启容器：对所属 Deployment 执行滚动重启
+func (s *K8sService) RestartContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) error {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return err
+	}
+	if workload.deployment == nil {
+		return fmt.Errorf("%w: %s %s cannot be restarted", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+
+	if err := rolloutRestart(ctx, clientSet, namespace, workload.deployment.Name); err != nil {
+		return fmt.Errorf("failed to restart deployment: %w", err)
+	}
+
+	log.Printf("Successfully restarted deployment: %s", workload.deployment.Name)
+	return nil
 }
 
-type CreateContainerRequest struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	Image     string `json:"image"`
-	Command   string `json:"command"`
-	Ports     string `json:"ports"`
-	Env       string `json:"env"`
-	Resources string `json:"resources"`
+// DeleteContainer 删除容器：删除所属的工作负载（独立 Pod 直接删除）及平台为其创建的 Service
+func (s *K8sService) DeleteContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string) (*DeletedContainer, error) {
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	deletePolicy := metav1.DeletePropagationForeground
+	options := metav1.DeleteOptions{PropagationPolicy: &deletePolicy}
+
+	switch workload.kind() {
+	case WorkloadDeployment:
+		err = clientSet.AppsV1().Deployments(namespace).Delete(ctx, workload.name(), options)
+	case WorkloadJob:
+		err = clientSet.BatchV1().Jobs(namespace).Delete(ctx, workload.name(), options)
+	case WorkloadCronJob:
+		err = clientSet.BatchV1().CronJobs(namespace).Delete(ctx, workload.name(), options)
+	default:
+		err = clientSet.CoreV1().Pods(namespace).Delete(ctx, workload.name(), options)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete %s: %w", workload.kind(), err)
+	}
+	log.Printf("Successfully deleted %s: %s", workload.kind(), workload.name())
+
+	deleted := &DeletedContainer{Kind: workload.kind(), Name: workload.name()}
+	deleted.Service, err = deleteExposedService(ctx, clientSet, namespace, workload)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to delete service: %w", err)
+	}
+	return deleted, nil
 }
 
 // 辅助函数
+
+// deriveContainerStatus 根据容器状态得出展示状态、重启次数和容器ID，运行中但未就绪时为 NotReady
+func deriveContainerStatus(statuses []corev1.ContainerStatus, name string) (string, int32, string) {
+	for _, containerStatus := range statuses {
+		if containerStatus.Name != name {
+			continue
+		}
+
+		status := "Unknown"
+		if containerStatus.State.Running != nil {
+			// 运行中但未通过就绪探针（或启动探针尚未成功）时不接收流量
+			status = "Running"
+			if !containerStatus.Ready {
+				status = "NotReady"
+			}
+		} else if containerStatus.State.Waiting != nil {
+			status = "Pending"
+		} else if containerStatus.State.Terminated != nil {
+			if containerStatus.State.Terminated.ExitCode == 0 {
+				status = "Succeeded"
+			} else {
+				status = "Failed"
+			}
+		}
+		return status, containerStatus.RestartCount, containerStatus.ContainerID
+	}
+	return "Unknown", 0, ""
+}
+
 func calculateAge(creationTime time.Time) string {
 	duration := time.Since(creationTime)
 
@@ -303,53 +610,3 @@ func calculateAge(creationTime time.Time) string {
 		return fmt.Sprintf("%.0fd", duration.Hours()/24)
 	}
 }
-
-func parseEnvVars(envStr string) map[string]string {
-	envMap := make(map[string]string)
-	// 简单解析，实际应该更robust
-	lines := strings.Split(envStr, "\n")
-	for _, line := range lines {
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			envMap[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-		}
-	}
-	return envMap
-}
-
-func parsePortMappings(portsStr string) []int32 {
-	var ports []int32
-	// 简单解析端口映射
-	parts := strings.Split(portsStr, ",")
-	for _, part := range parts {
-		portStr := strings.TrimSpace(part)
-		if portStr != "" {
-			if port, err := strconv.Atoi(portStr); err == nil {
-				ports = append(ports, int32(port))
-			}
-		}
-	}
-	return ports
-}
-
-func parseResources(resourcesStr string) corev1.ResourceRequirements {
-	requirements := corev1.ResourceRequirements{
-		Requests: make(corev1.ResourceList),
-		Limits:   make(corev1.ResourceList),
-	}
-
-	// 简单解析资源限制
-	parts := strings.Split(resourcesStr, ",")
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if strings.Contains(part, "cpu:") {
-			cpuValue := strings.TrimSpace(strings.TrimPrefix(part, "cpu:"))
-			requirements.Requests[corev1.ResourceCPU] = resource.MustParse(cpuValue)
-		} else if strings.Contains(part, "memory:") {
-			memValue := strings.TrimSpace(strings.TrimPrefix(part, "memory:"))
-			requirements.Requests[corev1.ResourceMemory] = resource.MustParse(memValue)
-		}
-	}
-
-	return requirements
-}
\ No newline at end of file
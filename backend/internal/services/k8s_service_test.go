@@ -0,0 +1,779 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/model"
+)
+
+// newFakeService 创建使用 fake clientset 的 K8sService
+func newFakeService(t *testing.T, objects ...runtime.Object) (*K8sService, *fake.Clientset) {
+	t.Helper()
+
+	clientSet := fake.NewSimpleClientset(objects...)
+	service := NewK8sServiceWithFactory(func(*rest.Config) (kubernetes.Interface, error) {
+		return clientSet, nil
+	})
+	t.Cleanup(service.pool.Close)
+	return service, clientSet
+}
+
+func newTestConnection() *model.K8sConnection {
+	connection := &model.K8sConnection{
+		Name:              "test-cluster",
+		Endpoint:          "https://kubernetes.example.com",
+		ConfigType:        ConfigTypeToken,
+		Token:             "test-token",
+		Namespace:         "default",
+		ImpersonationMode: ImpersonationNone,
+	}
+	connection.ID = 1
+	connection.UpdatedAt = time.Unix(1700000000, 0)
+	return connection
+}
+
+func newTestPod(name, namespace string, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            map[string]string{"app": name},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{
+				{Name: "app", Image: "nginx:1.25"},
+			},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: statuses},
+	}
+}
+
+// newTestDeployment 创建 Deployment 及其 ReplicaSet 和 Pod，保持与控制器一致的 ownerReferences
+func newTestDeployment(name, namespace string, replicas int32) (*appsv1.Deployment, *appsv1.ReplicaSet, *corev1.Pod) {
+	labels := map[string]string{"app": name, "managed": "container-platform"}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, UID: types.UID(name + "-uid")},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: "nginx"}}},
+			},
+		},
+	}
+
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name + "-7d9f8",
+			Namespace:       namespace,
+			UID:             types.UID(name + "-rs-uid"),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+	}
+
+	pod := newTestPod(name+"-7d9f8-x2k4p", namespace)
+	pod.Labels = labels
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(replicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}
+
+	return deployment, replicaSet, pod
+}
+
+func getDeployment(t *testing.T, clientSet kubernetes.Interface, namespace, name string) *appsv1.Deployment {
+	t.Helper()
+
+	deployment, err := clientSet.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get deployment %s: %v", name, err)
+	}
+	return deployment
+}
+
+func TestListContainers(t *testing.T) {
+	pod := newTestPod("web", "default", corev1.ContainerStatus{
+		Name:         "app",
+		RestartCount: 3,
+		ContainerID:  "containerd://abc",
+		Ready:        true,
+		State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	})
+	other := newTestPod("other", "kube-system")
+	service, _ := newFakeService(t, pod, other)
+
+	containers, err := service.ListContainers(context.Background(), newTestConnection(), "default")
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("ListContainers() returned %d containers, want 1", len(containers))
+	}
+
+	got := containers[0]
+	want := ContainerInfo{
+		Name:         "app",
+		Namespace:    "default",
+		Image:        "nginx:1.25",
+		Status:       "Running",
+		Ready:        true,
+		PodName:      "web",
+		RestartCount: 3,
+		Age:          "2h",
+		Node:         "node-1",
+		Labels:       map[string]string{"app": "web"},
+		ContainerID:  "containerd://abc",
+		ConnectionID: 1,
+		Cluster:      "test-cluster",
+		Workload:     WorkloadPod,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListContainers() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListContainersAllNamespaces(t *testing.T) {
+	service, _ := newFakeService(t, newTestPod("web", "default"), newTestPod("dns", "kube-system"))
+
+	containers, err := service.ListContainers(context.Background(), newTestConnection(), metav1.NamespaceAll)
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 2 {
+		t.Errorf("ListContainers() returned %d containers, want 2", len(containers))
+	}
+}
+
+func TestListContainersRequiresConnection(t *testing.T) {
+	service, _ := newFakeService(t)
+
+	if _, err := service.ListContainers(context.Background(), nil, "default"); err == nil {
+		t.Error("ListContainers() with nil connection succeeded, want error")
+	}
+}
+
+func TestCreateContainer(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	replicas := int32(3)
+	req := &CreateContainerRequest{
+		Name:      "web",
+		Namespace: "apps",
+		Image:     "nginx:1.25",
+		Command:   StringList{"nginx"},
+		Args:      StringList{"-g", "daemon off;"},
+		Ports:     PortList{{Name: "http", ContainerPort: 80}, {ContainerPort: 443}},
+		Env: EnvVarList{
+			{Name: "MODE", Value: "production"},
+			{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{Type: EnvSourceSecret, Name: "db", Key: "password"}},
+		},
+		Resources: &ResourceSpec{CPURequest: "500m", CPULimit: "1", MemoryRequest: "256Mi", MemoryLimit: "512Mi"},
+		Replicas:  &replicas,
+	}
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	deployment, err := clientSet.AppsV1().Deployments("apps").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("created deployment not found: %v", err)
+	}
+
+	if deployment.Labels["app"] != "web" || deployment.Labels["managed"] != "container-platform" {
+		t.Errorf("deployment labels = %v", deployment.Labels)
+	}
+	if deploymentReplicas(deployment) != 3 {
+		t.Errorf("replicas = %d, want 3", deploymentReplicas(deployment))
+	}
+	if !reflect.DeepEqual(deployment.Spec.Selector.MatchLabels, deployment.Spec.Template.Labels) {
+		t.Errorf("selector %v does not match template labels %v", deployment.Spec.Selector.MatchLabels, deployment.Spec.Template.Labels)
+	}
+
+	pod := deployment.Spec.Template
+	if pod.Spec.RestartPolicy != corev1.RestartPolicyAlways {
+		t.Errorf("restart policy = %s, want Always", pod.Spec.RestartPolicy)
+	}
+	if len(pod.Spec.Containers) != 1 {
+		t.Fatalf("pod has %d containers, want 1", len(pod.Spec.Containers))
+	}
+
+	container := pod.Spec.Containers[0]
+	if container.Name != "web" || container.Image != "nginx:1.25" {
+		t.Errorf("container = %s/%s, want web/nginx:1.25", container.Name, container.Image)
+	}
+
+	if !reflect.DeepEqual(container.Command, []string{"nginx"}) || !reflect.DeepEqual(container.Args, []string{"-g", "daemon off;"}) {
+		t.Errorf("container command = %v, args = %v", container.Command, container.Args)
+	}
+
+	wantPorts := []corev1.ContainerPort{
+		{Name: "http", ContainerPort: 80, Protocol: corev1.ProtocolTCP},
+		{ContainerPort: 443, Protocol: corev1.ProtocolTCP},
+	}
+	if !reflect.DeepEqual(container.Ports, wantPorts) {
+		t.Errorf("container ports = %v, want %v", container.Ports, wantPorts)
+	}
+
+	if len(container.Env) != 2 || container.Env[0].Value != "production" {
+		t.Fatalf("container env = %v", container.Env)
+	}
+	if ref := container.Env[1].ValueFrom; ref == nil || ref.SecretKeyRef == nil || ref.SecretKeyRef.Name != "db" || ref.SecretKeyRef.Key != "password" {
+		t.Errorf("secret env = %+v", container.Env[1])
+	}
+
+	wantResources := map[string]corev1.ResourceList{
+		"requests": {corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+		"limits":   {corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+	gotResources := map[string]corev1.ResourceList{"requests": container.Resources.Requests, "limits": container.Resources.Limits}
+	for kind, want := range wantResources {
+		for name, quantity := range want {
+			if actual := gotResources[kind][name]; actual.Cmp(quantity) != 0 {
+				t.Errorf("%s %s = %s, want %s", kind, name, actual.String(), quantity.String())
+			}
+		}
+	}
+}
+
+func TestCreateContainerDefaultsToOneReplica(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx"}
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	deployment, err := clientSet.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("created deployment not found: %v", err)
+	}
+	if deploymentReplicas(deployment) != 1 {
+		t.Errorf("replicas = %d, want 1", deploymentReplicas(deployment))
+	}
+}
+
+func TestCreateContainerPod(t *testing.T) {
+	service, clientSet := newFakeService(t)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", Kind: WorkloadPod}
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+
+	if _, err := clientSet.CoreV1().Pods("default").Get(context.Background(), "web-pod", metav1.GetOptions{}); err != nil {
+		t.Errorf("created pod not found: %v", err)
+	}
+}
+
+func TestCreateContainerInvalidRequest(t *testing.T) {
+	negative := int32(-1)
+	tests := []struct {
+		name string
+		req  *CreateContainerRequest
+	}{
+		{"negative replicas", &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", Replicas: &negative}},
+		{"unsupported kind", &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx", Kind: "statefulset"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newFakeService(t)
+
+			err := service.CreateContainer(context.Background(), newTestConnection(), tt.req)
+			if !errors.Is(err, ErrInvalidContainerRequest) {
+				t.Errorf("CreateContainer() error = %v, want ErrInvalidContainerRequest", err)
+			}
+		})
+	}
+}
+
+func TestCreateContainerAlreadyExists(t *testing.T) {
+	deployment, _, _ := newTestDeployment("web", "default", 1)
+	service, _ := newFakeService(t, deployment)
+
+	req := &CreateContainerRequest{Name: "web", Namespace: "default", Image: "nginx"}
+	err := service.CreateContainer(context.Background(), newTestConnection(), req)
+	if !apierrors.IsAlreadyExists(err) {
+		t.Errorf("CreateContainer() error = %v, want AlreadyExists", err)
+	}
+}
+
+func TestStopAndStartContainer(t *testing.T) {
+	ctx := context.Background()
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 3)
+	service, clientSet := newFakeService(t, deployment, replicaSet, pod)
+	connection := newTestConnection()
+
+	// 通过 Pod 名称停止
+	if err := service.StopContainer(ctx, connection, "default", pod.Name); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	stopped := getDeployment(t, clientSet, "default", "web")
+	if deploymentReplicas(stopped) != 0 {
+		t.Errorf("replicas after stop = %d, want 0", deploymentReplicas(stopped))
+	}
+	if stopped.Annotations[replicasAnnotation] != "3" {
+		t.Errorf("remembered replicas = %q, want 3", stopped.Annotations[replicasAnnotation])
+	}
+
+	// 停止后 Pod 已不存在，通过 Deployment 名称启动
+	if err := clientSet.CoreV1().Pods("default").Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete pod: %v", err)
+	}
+	if err := service.StartContainer(ctx, connection, "default", "web"); err != nil {
+		t.Fatalf("StartContainer() error = %v", err)
+	}
+	started := getDeployment(t, clientSet, "default", "web")
+	if deploymentReplicas(started) != 3 {
+		t.Errorf("replicas after start = %d, want 3", deploymentReplicas(started))
+	}
+
+	// 已在运行时启动不修改副本数
+	if err := service.StartContainer(ctx, connection, "default", "web"); err != nil {
+		t.Fatalf("StartContainer() error = %v", err)
+	}
+	if replicas := deploymentReplicas(getDeployment(t, clientSet, "default", "web")); replicas != 3 {
+		t.Errorf("replicas after second start = %d, want 3", replicas)
+	}
+}
+
+func TestStartContainerWithoutRememberedReplicas(t *testing.T) {
+	deployment, _, _ := newTestDeployment("web", "default", 0)
+	service, clientSet := newFakeService(t, deployment)
+
+	if err := service.StartContainer(context.Background(), newTestConnection(), "default", "web"); err != nil {
+		t.Fatalf("StartContainer() error = %v", err)
+	}
+	if replicas := deploymentReplicas(getDeployment(t, clientSet, "default", "web")); replicas != 1 {
+		t.Errorf("replicas after start = %d, want 1", replicas)
+	}
+}
+
+func TestRestartContainer(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 2)
+	service, clientSet := newFakeService(t, deployment, replicaSet, pod)
+
+	if err := service.RestartContainer(context.Background(), newTestConnection(), "default", pod.Name); err != nil {
+		t.Fatalf("RestartContainer() error = %v", err)
+	}
+
+	restarted := getDeployment(t, clientSet, "default", "web")
+	if restarted.Spec.Template.Annotations[restartedAtAnnotation] == "" {
+		t.Error("pod template is missing the restartedAt annotation")
+	}
+	if deploymentReplicas(restarted) != 2 {
+		t.Errorf("replicas after restart = %d, want 2", deploymentReplicas(restarted))
+	}
+	// 滚动重启由 Deployment 控制器替换 Pod，不直接删除
+	if _, err := clientSet.CoreV1().Pods("default").Get(context.Background(), pod.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("pod was deleted by restart: %v", err)
+	}
+}
+
+func TestDeleteContainer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deployment", func(t *testing.T) {
+		deployment, replicaSet, pod := newTestDeployment("web", "default", 1)
+		service, clientSet := newFakeService(t, deployment, replicaSet, pod)
+
+		deleted, err := service.DeleteContainer(ctx, newTestConnection(), "default", pod.Name)
+		if err != nil {
+			t.Fatalf("DeleteContainer() error = %v", err)
+		}
+		if *deleted != (DeletedContainer{Kind: WorkloadDeployment, Name: "web"}) {
+			t.Errorf("DeleteContainer() = %+v", deleted)
+		}
+		_, err = clientSet.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("deployment still exists after delete: %v", err)
+		}
+	})
+
+	t.Run("pod", func(t *testing.T) {
+		service, clientSet := newFakeService(t, newTestPod("web-pod", "default"))
+
+		if _, err := service.DeleteContainer(ctx, newTestConnection(), "default", "web-pod"); err != nil {
+			t.Fatalf("DeleteContainer() error = %v", err)
+		}
+		_, err := clientSet.CoreV1().Pods("default").Get(ctx, "web-pod", metav1.GetOptions{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("pod still exists after delete: %v", err)
+		}
+	})
+}
+
+func TestLifecycleUnsupportedForStandalonePod(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(*K8sService, context.Context, *model.K8sConnection, string, string) error
+	}{
+		{"start", (*K8sService).StartContainer},
+		{"stop", (*K8sService).StopContainer},
+		{"restart", (*K8sService).RestartContainer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, clientSet := newFakeService(t, newTestPod("web-pod", "default"))
+
+			err := tt.action(service, context.Background(), newTestConnection(), "default", "web-pod")
+			if !errors.Is(err, ErrUnsupportedWorkload) {
+				t.Errorf("%s error = %v, want ErrUnsupportedWorkload", tt.name, err)
+			}
+			if _, err := clientSet.CoreV1().Pods("default").Get(context.Background(), "web-pod", metav1.GetOptions{}); err != nil {
+				t.Errorf("pod was removed by %s: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestContainerLifecycleMissingContainer(t *testing.T) {
+	service, _ := newFakeService(t)
+
+	err := service.StopContainer(context.Background(), newTestConnection(), "default", "missing")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("StopContainer() error = %v, want NotFound", err)
+	}
+}
+
+func TestListContainersIncludesStoppedDeployments(t *testing.T) {
+	running, replicaSet, pod := newTestDeployment("web", "default", 2)
+	stopped, _, _ := newTestDeployment("worker", "default", 0)
+	service, _ := newFakeService(t, running, replicaSet, pod, stopped)
+
+	containers, err := service.ListContainers(context.Background(), newTestConnection(), "default")
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("ListContainers() returned %d containers, want 2", len(containers))
+	}
+
+	byWorkload := make(map[string]ContainerInfo)
+	for _, container := range containers {
+		byWorkload[container.WorkloadName] = container
+	}
+
+	web := byWorkload["web"]
+	if web.PodName != pod.Name || web.Workload != WorkloadDeployment || web.Replicas == nil || *web.Replicas != 2 {
+		t.Errorf("running container = %+v", web)
+	}
+
+	worker := byWorkload["worker"]
+	if worker.Status != "Stopped" || worker.PodName != "worker" || worker.Replicas == nil || *worker.Replicas != 0 {
+		t.Errorf("stopped container = %+v", worker)
+	}
+}
+
+func TestClientPoolReusesClient(t *testing.T) {
+	calls := 0
+	clientSet := fake.NewSimpleClientset()
+	pool := NewClientPool(time.Minute, func(*rest.Config) (kubernetes.Interface, error) {
+		calls++
+		return clientSet, nil
+	})
+	defer pool.Close()
+
+	connection := newTestConnection()
+	for i := 0; i < 3; i++ {
+		if _, _, err := pool.Get(context.Background(), connection, nil); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("factory called %d times, want 1", calls)
+	}
+
+	// 配置变更后重新创建
+	connection.UpdatedAt = connection.UpdatedAt.Add(time.Second)
+	if _, _, err := pool.Get(context.Background(), connection, nil); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("factory called %d times after update, want 2", calls)
+	}
+}
+
+func TestClientPoolRequiresPlatformUser(t *testing.T) {
+	service, _ := newFakeService(t)
+
+	connection := newTestConnection()
+	connection.ImpersonationMode = ImpersonationPlatformUser
+	if err := service.ConnectToCluster(context.Background(), connection); err != ErrPlatformUserRequired {
+		t.Errorf("ConnectToCluster() error = %v, want ErrPlatformUserRequired", err)
+	}
+
+	ctx := WithPlatformUser(context.Background(), &PlatformUser{ID: 1, Username: "alice"})
+	if err := service.ConnectToCluster(ctx, connection); err != nil {
+		t.Errorf("ConnectToCluster() with platform user error = %v", err)
+	}
+}
+
+func TestClientPoolHandshakeHonorsContext(t *testing.T) {
+	// 接受连接但从不响应的集群
+	release := make(chan struct{})
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer apiServer.Close()
+	defer close(release)
+
+	pool := NewClientPool(time.Minute, nil)
+	defer pool.Close()
+
+	connection := newTestConnection()
+	connection.Endpoint = apiServer.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, _, err := pool.Get(ctx, connection, nil); err == nil {
+		t.Fatal("Get() should fail when the handshake does not complete")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Get() returned after %v, want it to stop when ctx expires", elapsed)
+	}
+}
+
+func TestBuildRestConfigTimeout(t *testing.T) {
+	config, err := buildRestConfig(newTestConnection())
+	if err != nil {
+		t.Fatalf("buildRestConfig() error = %v", err)
+	}
+	if config.Timeout != defaultClusterRequestTimeout {
+		t.Errorf("Timeout = %v, want %v", config.Timeout, defaultClusterRequestTimeout)
+	}
+	if streaming := streamingConfig(config); streaming.Timeout != 0 || config.Timeout == 0 {
+		t.Errorf("streamingConfig() Timeout = %v, original = %v", streaming.Timeout, config.Timeout)
+	}
+}
+
+func TestDeriveContainerStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        corev1.ContainerState
+		ready        bool
+		wantStatus   string
+		wantRestarts int32
+	}{
+		{"running", corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}, true, "Running", 1},
+		{"not ready", corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}, false, "NotReady", 1},
+		{"waiting", corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}, false, "Pending", 1},
+		{"succeeded", corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}, false, "Succeeded", 1},
+		{"failed", corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137}}, false, "Failed", 1},
+		{"empty state", corev1.ContainerState{}, false, "Unknown", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := []corev1.ContainerStatus{
+				{Name: "sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{Name: "app", State: tt.state, Ready: tt.ready, RestartCount: 1, ContainerID: "docker://123"},
+			}
+
+			status, restarts, containerID := deriveContainerStatus(statuses, "app")
+			if status != tt.wantStatus || restarts != tt.wantRestarts || containerID != "docker://123" {
+				t.Errorf("deriveContainerStatus() = (%s, %d, %s), want (%s, %d, docker://123)",
+					status, restarts, containerID, tt.wantStatus, tt.wantRestarts)
+			}
+		})
+	}
+
+	t.Run("missing status", func(t *testing.T) {
+		status, restarts, containerID := deriveContainerStatus(nil, "app")
+		if status != "Unknown" || restarts != 0 || containerID != "" {
+			t.Errorf("deriveContainerStatus() = (%s, %d, %s), want (Unknown, 0, )", status, restarts, containerID)
+		}
+	})
+}
+
+func TestPauseAndResumeDeployment(t *testing.T) {
+	ctx := context.Background()
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 2)
+	service, clientSet := newFakeService(t, deployment, replicaSet, pod)
+	connection := newTestConnection()
+
+	if _, err := service.PauseContainer(ctx, connection, "default", pod.Name); err != nil {
+		t.Fatalf("PauseContainer() error = %v", err)
+	}
+	paused := getDeployment(t, clientSet, "default", "web")
+	if !deploymentPaused(paused) {
+		t.Fatalf("deployment is not paused: replicas=%d annotations=%v", deploymentReplicas(paused), paused.Annotations)
+	}
+	if paused.Annotations[replicasAnnotation] != "2" {
+		t.Errorf("remembered replicas = %q, want 2", paused.Annotations[replicasAnnotation])
+	}
+
+	if err := clientSet.CoreV1().Pods("default").Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete pod: %v", err)
+	}
+	containers, err := service.ListContainers(ctx, connection, "default")
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 1 || containers[0].Status != "Paused" {
+		t.Fatalf("ListContainers() = %+v, want one Paused container", containers)
+	}
+
+	if _, err := service.ResumeContainer(ctx, connection, "default", "web"); err != nil {
+		t.Fatalf("ResumeContainer() error = %v", err)
+	}
+	resumed := getDeployment(t, clientSet, "default", "web")
+	if deploymentReplicas(resumed) != 2 || deploymentPaused(resumed) {
+		t.Errorf("resumed deployment replicas=%d annotations=%v", deploymentReplicas(resumed), resumed.Annotations)
+	}
+}
+
+func TestStopIsNotReportedAsPaused(t *testing.T) {
+	ctx := context.Background()
+	deployment, _, _ := newTestDeployment("web", "default", 1)
+	service, _ := newFakeService(t, deployment)
+	connection := newTestConnection()
+
+	if _, err := service.PauseContainer(ctx, connection, "default", "web"); err != nil {
+		t.Fatalf("PauseContainer() error = %v", err)
+	}
+	// 停止会清除暂停标记
+	if err := service.StopContainer(ctx, connection, "default", "web"); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+
+	containers, err := service.ListContainers(ctx, connection, "default")
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 1 || containers[0].Status != "Stopped" {
+		t.Fatalf("ListContainers() = %+v, want one Stopped container", containers)
+	}
+
+	_, err = service.ResumeContainer(ctx, connection, "default", "web")
+	if !errors.Is(err, ErrContainerNotPaused) {
+		t.Errorf("ResumeContainer() error = %v, want ErrContainerNotPaused", err)
+	}
+}
+
+func TestPauseAndResumeJob(t *testing.T) {
+	ctx := context.Background()
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default", UID: "migrate-uid"},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "migrate", Image: "migrate:1"}}},
+			},
+		},
+	}
+	pod := newTestPod("migrate-abcde", "default")
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job"))}
+	service, clientSet := newFakeService(t, job, pod)
+	connection := newTestConnection()
+
+	if _, err := service.PauseContainer(ctx, connection, "default", pod.Name); err != nil {
+		t.Fatalf("PauseContainer() error = %v", err)
+	}
+	suspended, err := clientSet.BatchV1().Jobs("default").Get(ctx, "migrate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if !isSuspended(suspended.Spec.Suspend) {
+		t.Fatal("job is not suspended")
+	}
+
+	// 暂停后 Job 控制器删除 Pod，仍以 Paused 状态列出
+	if err := clientSet.CoreV1().Pods("default").Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete pod: %v", err)
+	}
+	containers, err := service.ListContainers(ctx, connection, "default")
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 1 || containers[0].Status != "Paused" || containers[0].Workload != WorkloadJob {
+		t.Fatalf("ListContainers() = %+v, want one Paused job", containers)
+	}
+
+	if _, err := service.ResumeContainer(ctx, connection, "default", "migrate"); err != nil {
+		t.Fatalf("ResumeContainer() error = %v", err)
+	}
+	resumed, err := clientSet.BatchV1().Jobs("default").Get(ctx, "migrate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if isSuspended(resumed.Spec.Suspend) {
+		t.Error("job is still suspended after resume")
+	}
+}
+
+func TestPauseCronJobFromPod(t *testing.T) {
+	ctx := context.Background()
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", UID: "backup-uid"},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "backup", Image: "backup:1"}}},
+					},
+				},
+			},
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "backup-28000000",
+			Namespace:       "default",
+			UID:             "backup-job-uid",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+	}
+	pod := newTestPod("backup-28000000-xyz", "default")
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job"))}
+	service, clientSet := newFakeService(t, cronJob, job, pod)
+	connection := newTestConnection()
+
+	containers, err := service.ListContainers(ctx, connection, "default")
+	if err != nil {
+		t.Fatalf("ListContainers() error = %v", err)
+	}
+	if len(containers) != 1 || containers[0].Workload != WorkloadCronJob || containers[0].WorkloadName != "backup" {
+		t.Fatalf("ListContainers() = %+v, want pod owned by cronjob backup", containers)
+	}
+
+	if _, err := service.PauseContainer(ctx, connection, "default", pod.Name); err != nil {
+		t.Fatalf("PauseContainer() error = %v", err)
+	}
+	suspended, err := clientSet.BatchV1().CronJobs("default").Get(ctx, "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get cronjob: %v", err)
+	}
+	if !isSuspended(suspended.Spec.Suspend) {
+		t.Error("cronjob is not suspended")
+	}
+}
+
+func TestPauseUnsupportedForStandalonePod(t *testing.T) {
+	service, _ := newFakeService(t, newTestPod("web-pod", "default"))
+
+	_, err := service.PauseContainer(context.Background(), newTestConnection(), "default", "web-pod")
+	if !errors.Is(err, ErrUnsupportedWorkload) {
+		t.Errorf("PauseContainer() error = %v, want ErrUnsupportedWorkload", err)
+	}
+}
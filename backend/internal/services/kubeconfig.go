@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"container-platform-backend/internal/model"
+)
+
+// KubeconfigContext kubeconfig 中的一个上下文
+type KubeconfigContext struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	Server    string `json:"server,omitempty"`
+	Current   bool   `json:"current"`
+}
+
+// ParseKubeconfig 解析 kubeconfig 内容，支持 YAML 和 JSON 格式
+func ParseKubeconfig(data string) (*api.Config, error) {
+	if data == "" {
+		return nil, fmt.Errorf("%w: kubeconfig is empty", ErrInvalidConnection)
+	}
+
+	// clientcmd.Load 基于 YAML 解析，JSON 作为 YAML 的子集同样适用
+	config, err := clientcmd.Load([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse kubeconfig: %v", ErrInvalidConnection, err)
+	}
+
+	if len(config.Contexts) == 0 {
+		return nil, fmt.Errorf("%w: kubeconfig contains no contexts", ErrInvalidConnection)
+	}
+
+	return config, nil
+}
+
+// ListKubeconfigContexts 列出 kubeconfig 中的所有上下文
+func ListKubeconfigContexts(data string) ([]KubeconfigContext, error) {
+	config, err := ParseKubeconfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]KubeconfigContext, 0, len(config.Contexts))
+	for name, context := range config.Contexts {
+		item := KubeconfigContext{
+			Name:      name,
+			Cluster:   context.Cluster,
+			User:      context.AuthInfo,
+			Namespace: context.Namespace,
+			Current:   name == config.CurrentContext,
+		}
+		if cluster, ok := config.Clusters[context.Cluster]; ok {
+			item.Server = cluster.Server
+		}
+		contexts = append(contexts, item)
+	}
+
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+
+	return contexts, nil
+}
+
+// kubeconfigRestConfig 在内存中根据 kubeconfig 和所选的上下文/集群/用户构建 rest.Config
+func kubeconfigRestConfig(connection *model.K8sConnection) (*rest.Config, error) {
+	config, err := ParseKubeconfig(connection.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if connection.ContextName != "" {
+		if _, ok := config.Contexts[connection.ContextName]; !ok {
+			return nil, fmt.Errorf("%w: context %q not found in kubeconfig", ErrInvalidConnection, connection.ContextName)
+		}
+	}
+	if connection.ClusterName != "" {
+		if _, ok := config.Clusters[connection.ClusterName]; !ok {
+			return nil, fmt.Errorf("%w: cluster %q not found in kubeconfig", ErrInvalidConnection, connection.ClusterName)
+		}
+	}
+	if connection.AuthInfoName != "" {
+		if _, ok := config.AuthInfos[connection.AuthInfoName]; !ok {
+			return nil, fmt.Errorf("%w: user %q not found in kubeconfig", ErrInvalidConnection, connection.AuthInfoName)
+		}
+	}
+
+	overrides := &clientcmd.ConfigOverrides{
+		Context: api.Context{
+			Cluster:  connection.ClusterName,
+			AuthInfo: connection.AuthInfoName,
+		},
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(*config, connection.ContextName, overrides, nil)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to build config from kubeconfig: %v", ErrInvalidConnection, err)
+	}
+
+	return restConfig, nil
+}
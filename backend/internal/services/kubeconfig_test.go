@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com:6443
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: dev-user
+  user:
+    token: dev-token
+- name: prod-user
+  user:
+    token: prod-token
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-user
+    namespace: dev
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: prod-user
+`
+
+func TestListKubeconfigContexts(t *testing.T) {
+	contexts, err := ListKubeconfigContexts(testKubeconfig)
+	if err != nil {
+		t.Fatalf("ListKubeconfigContexts() error = %v", err)
+	}
+
+	want := []KubeconfigContext{
+		{Name: "dev", Cluster: "dev-cluster", User: "dev-user", Namespace: "dev", Server: "https://dev.example.com:6443", Current: true},
+		{Name: "prod", Cluster: "prod-cluster", User: "prod-user", Server: "https://prod.example.com:6443"},
+	}
+	if !reflect.DeepEqual(contexts, want) {
+		t.Errorf("contexts = %+v, want %+v", contexts, want)
+	}
+}
+
+func TestParseKubeconfigInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":       "",
+		"malformed":   "clusters: [",
+		"no contexts": "apiVersion: v1\nkind: Config\nclusters: []\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseKubeconfig(data); !errors.Is(err, ErrInvalidConnection) {
+				t.Errorf("ParseKubeconfig() error = %v, want ErrInvalidConnection", err)
+			}
+		})
+	}
+}
+
+func TestKubeconfigRestConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		context    string
+		cluster    string
+		user       string
+		wantHost   string
+		wantToken  string
+		wantErrMsg string
+	}{
+		{name: "current context", wantHost: "https://dev.example.com:6443", wantToken: "dev-token"},
+		{name: "selected context", context: "prod", wantHost: "https://prod.example.com:6443", wantToken: "prod-token"},
+		{name: "cluster override", context: "dev", cluster: "prod-cluster", wantHost: "https://prod.example.com:6443", wantToken: "dev-token"},
+		{name: "user override", user: "prod-user", wantHost: "https://dev.example.com:6443", wantToken: "prod-token"},
+		{name: "unknown context", context: "staging", wantErrMsg: `context "staging" not found`},
+		{name: "unknown cluster", cluster: "staging-cluster", wantErrMsg: `cluster "staging-cluster" not found`},
+		{name: "unknown user", user: "admin", wantErrMsg: `user "admin" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connection := newTestConnection()
+			connection.ConfigType = ConfigTypeKubeconfig
+			connection.Config = testKubeconfig
+			connection.ContextName = tt.context
+			connection.ClusterName = tt.cluster
+			connection.AuthInfoName = tt.user
+
+			config, err := kubeconfigRestConfig(connection)
+			if tt.wantErrMsg != "" {
+				if !errors.Is(err, ErrInvalidConnection) || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Fatalf("kubeconfigRestConfig() error = %v, want %q", err, tt.wantErrMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("kubeconfigRestConfig() error = %v", err)
+			}
+			if config.Host != tt.wantHost || config.BearerToken != tt.wantToken {
+				t.Errorf("config = %s with token %q, want %s with token %q", config.Host, config.BearerToken, tt.wantHost, tt.wantToken)
+			}
+		})
+	}
+}
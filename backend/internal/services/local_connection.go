@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/k8s"
+	"container-platform-backend/internal/model"
+)
+
+// LocalConnectionName 内置连接的默认名称
+const LocalConnectionName = "local"
+
+// ErrBuiltInConnection 内置连接不允许删除或修改凭据来源
+var ErrBuiltInConnection = errors.New("built-in kubernetes connection cannot be deleted")
+
+// EnsureLocalConnection 注册或更新内置的 local 连接
+// configPath 为空时由 k8s.LoadConfig 依次回退到集群内配置和 ~/.kube/config，
+// 凭据不写入数据库，每次创建客户端时重新加载（集群内的 ServiceAccount token 会轮换）。
+func (s *ConnectionService) EnsureLocalConnection(configPath, namespace, endpoint string) (*model.K8sConnection, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	var connection model.K8sConnection
+	err := s.db.Where("built_in = ?", true).Order("id").First(&connection).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		connection = model.K8sConnection{
+			Name:              LocalConnectionName,
+			Endpoint:          endpoint,
+			ConfigType:        ConfigTypeLocal,
+			Config:            configPath,
+			Namespace:         namespace,
+			ImpersonationMode: ImpersonationNone,
+			BuiltIn:           true,
+		}
+
+		// 没有其他激活连接时设为默认连接
+		var active int64
+		if err := s.db.Model(&model.K8sConnection{}).Where("is_active = ?", true).Count(&active).Error; err != nil {
+			return nil, fmt.Errorf("failed to count active connections: %w", err)
+		}
+		connection.IsActive = active == 0
+
+		if err := s.db.Create(&connection).Error; err != nil {
+			return nil, fmt.Errorf("failed to create local connection: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get local connection: %w", err)
+	default:
+		// 名称、命名空间等用户可修改的设置保持不变，只同步凭据来源
+		if connection.Endpoint == endpoint && connection.Config == configPath && connection.ConfigType == ConfigTypeLocal {
+			return &connection, nil
+		}
+		connection.Endpoint = endpoint
+		connection.Config = configPath
+		connection.ConfigType = ConfigTypeLocal
+		if err := s.db.Save(&connection).Error; err != nil {
+			return nil, fmt.Errorf("failed to update local connection: %w", err)
+		}
+	}
+
+	return &connection, nil
+}
+
+// localRestConfig 内置连接的 REST 配置
+func localRestConfig(connection *model.K8sConnection) (*rest.Config, error) {
+	config, err := k8s.LoadConfig(connection.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local cluster config: %w", err)
+	}
+	return config, nil
+}
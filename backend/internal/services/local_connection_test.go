@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"container-platform-backend/internal/model"
+)
+
+func newBuiltInConnection() model.K8sConnection {
+	connection := model.K8sConnection{
+		Name: LocalConnectionName, Endpoint: "https://10.96.0.1:443", ConfigType: ConfigTypeLocal,
+		Config: "/etc/platform/kubeconfig", Namespace: "default", ImpersonationMode: ImpersonationNone,
+		BuiltIn: true, IsActive: true,
+	}
+	connection.ID = 7
+	return connection
+}
+
+func TestEnsureLocalConnection(t *testing.T) {
+	db, recorder := newRecordingDB(t, 0)
+	var mu sync.Mutex
+	var created *model.K8sConnection
+	recorder.results = func(query string) *recordingRows {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasPrefix(query, `INSERT INTO "k8s_connections"`):
+			connection := newBuiltInConnection()
+			created = &connection
+			return &recordingRows{columns: []string{"id"}, values: [][]driver.Value{{int64(connection.ID)}}}
+		case strings.Contains(query, "count("):
+			return &recordingRows{columns: []string{"count"}, values: [][]driver.Value{{int64(0)}}}
+		case strings.Contains(query, "built_in") && created != nil:
+			return connectionRows(*created)
+		}
+		return nil
+	}
+	service := NewConnectionService(db)
+
+	// 多次启动只注册一次
+	for i := 0; i < 2; i++ {
+		connection, err := service.EnsureLocalConnection("/etc/platform/kubeconfig", "", "https://10.96.0.1:443")
+		if err != nil {
+			t.Fatalf("EnsureLocalConnection() error = %v", err)
+		}
+		if !connection.BuiltIn || connection.ConfigType != ConfigTypeLocal || connection.Namespace != "default" || !connection.IsActive {
+			t.Errorf("connection = %+v", connection)
+		}
+	}
+	if inserts := recorder.matching(`INSERT INTO "k8s_connections"`); len(inserts) != 1 {
+		t.Errorf("got %d inserts, want 1", len(inserts))
+	}
+	if updates := recorder.matching(`UPDATE "k8s_connections"`); len(updates) != 0 {
+		t.Errorf("unchanged local connection should not be updated: %+v", updates)
+	}
+
+	// 服务端配置变化时同步凭据来源
+	connection, err := service.EnsureLocalConnection("", "", "https://10.96.0.1:443")
+	if err != nil {
+		t.Fatalf("EnsureLocalConnection() error = %v", err)
+	}
+	if connection.Config != "" || connection.ID != 7 {
+		t.Errorf("connection = %+v, want the config path cleared", connection)
+	}
+	if updates := recorder.matching(`UPDATE "k8s_connections"`); len(updates) != 1 {
+		t.Errorf("got %d updates, want 1", len(updates))
+	}
+}
+
+func TestDeleteBuiltInConnection(t *testing.T) {
+	service, recorder := newConnectionDB(t, newBuiltInConnection())
+
+	if err := service.DeleteConnection(7); !errors.Is(err, ErrBuiltInConnection) {
+		t.Fatalf("DeleteConnection() error = %v, want ErrBuiltInConnection", err)
+	}
+	// 软删除也是 UPDATE
+	if writes := append(recorder.matching("UPDATE"), recorder.matching("DELETE")...); len(writes) != 0 {
+		t.Errorf("built-in connection should not be deleted: %+v", writes)
+	}
+}
+
+func TestUpdateBuiltInConnectionKeepsCredentials(t *testing.T) {
+	builtIn := newBuiltInConnection()
+	service, _ := newConnectionDB(t, builtIn)
+
+	connection, err := service.UpdateConnection(7, &ConnectionRequest{
+		Name:       "in-cluster",
+		Namespace:  "apps",
+		Endpoint:   "https://attacker.example.com",
+		ConfigType: ConfigTypeToken,
+		Config:     "/root/.kube/config",
+		Token:      "stolen-token",
+	})
+	if err != nil {
+		t.Fatalf("UpdateConnection() error = %v", err)
+	}
+
+	// 名称、命名空间可修改，凭据来源保持服务端配置
+	if connection.Name != "in-cluster" || connection.Namespace != "apps" {
+		t.Errorf("name = %q, namespace = %q", connection.Name, connection.Namespace)
+	}
+	if connection.ConfigType != ConfigTypeLocal || connection.Config != builtIn.Config || connection.Endpoint != builtIn.Endpoint || connection.Token != "" {
+		t.Errorf("credentials were overwritten: %+v", connection)
+	}
+	if !connection.BuiltIn || !connection.IsActive {
+		t.Errorf("connection = %+v, want it to stay built-in and active", connection)
+	}
+}
+
+func TestValidateConnectionLocalType(t *testing.T) {
+	connection := newBuiltInConnection()
+	if err := validateConnection(&connection); err != nil {
+		t.Errorf("validateConnection() of built-in connection error = %v", err)
+	}
+
+	connection.BuiltIn = false
+	if err := validateConnection(&connection); !errors.Is(err, ErrInvalidConnection) {
+		t.Errorf("validateConnection() error = %v, want ErrInvalidConnection", err)
+	}
+
+	// 请求不能创建 local 类型的连接
+	service, recorder := newConnectionDB(t)
+	_, err := service.CreateConnection(&ConnectionRequest{Name: "local-2", ConfigType: ConfigTypeLocal, Config: "/etc/kubernetes/admin.conf"})
+	if !errors.Is(err, ErrInvalidConnection) {
+		t.Errorf("CreateConnection() error = %v, want ErrInvalidConnection", err)
+	}
+	if inserts := recorder.matching("INSERT"); len(inserts) != 0 {
+		t.Errorf("invalid connection should not be created: %+v", inserts)
+	}
+}
+
+func TestTestConnectionRejectsLocalConfig(t *testing.T) {
+	service := NewK8sServiceWithFactory(func(*rest.Config) (kubernetes.Interface, error) {
+		t.Fatal("local config should be rejected before creating a client")
+		return nil, nil
+	})
+	t.Cleanup(service.pool.Close)
+
+	tests := []model.K8sConnection{
+		{Name: "local", ConfigType: ConfigTypeLocal, Config: "/etc/kubernetes/admin.conf"},
+		{Name: "built-in", ConfigType: ConfigTypeLocal, BuiltIn: true},
+		{Name: "built-in token", ConfigType: ConfigTypeToken, Endpoint: "https://kubernetes.example.com", Token: "token", BuiltIn: true},
+	}
+	for _, connection := range tests {
+		if err := service.TestConnection(context.Background(), &connection); !errors.Is(err, ErrInvalidConnection) {
+			t.Errorf("TestConnection(%s) error = %v, want ErrInvalidConnection", connection.Name, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"container-platform-backend/internal/model"
+)
+
+// 命名空间权限等级，高等级包含低等级的权限
+const (
+	// NamespacePermissionRead 查看容器、日志和事件
+	NamespacePermissionRead = "read"
+	// NamespacePermissionWrite 在容器中执行命令、传输文件、转发端口等
+	NamespacePermissionWrite = "write"
+	// NamespacePermissionAdmin 命名空间管理员
+	NamespacePermissionAdmin = "admin"
+)
+
+// adminRole 平台管理员角色，拥有所有命名空间的权限
+const adminRole = "admin"
+
+// ErrNamespaceAccessDenied 平台用户没有该命名空间所需的权限
+var ErrNamespaceAccessDenied = errors.New("namespace access denied")
+
+// NamespaceAccessService 按 namespace_permissions 校验平台用户对命名空间的权限
+type NamespaceAccessService struct {
+	db *gorm.DB
+}
+
+// NewNamespaceAccessService 创建命名空间权限服务
+func NewNamespaceAccessService(db *gorm.DB) *NamespaceAccessService {
+	return &NamespaceAccessService{db: db}
+}
+
+// Authorize 校验 ctx 中的平台用户对命名空间至少拥有 level 权限，平台管理员直接放行
+func (s *NamespaceAccessService) Authorize(ctx context.Context, namespace, level string) error {
+	user, ok := PlatformUserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: authentication required", ErrNamespaceAccessDenied)
+	}
+	if user.Role == adminRole {
+		return nil
+	}
+
+	var levels []string
+	err := s.db.WithContext(ctx).Model(&model.NamespacePermission{}).
+		Joins("JOIN namespaces ON namespaces.id = namespace_permissions.namespace_id AND namespaces.deleted_at IS NULL").
+		Where("namespace_permissions.user_id = ? AND namespaces.name = ?", user.ID, namespace).
+		Where("(namespace_permissions.expires_at IS NULL OR namespace_permissions.expires_at > ?)", time.Now()).
+		Pluck("namespace_permissions.permission_level", &levels).Error
+	if err != nil {
+		return fmt.Errorf("failed to get namespace permissions: %w", err)
+	}
+
+	for _, granted := range levels {
+		if permissionAllows(granted, level) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: user %s requires %s permission on namespace %s", ErrNamespaceAccessDenied, user.Username, level, namespace)
+}
+
+// AuthorizePlatform 校验 ctx 中的平台用户是平台管理员，用于修改全局模板等不属于任何命名空间的资源
+func (s *NamespaceAccessService) AuthorizePlatform(ctx context.Context) error {
+	user, ok := PlatformUserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: authentication required", ErrNamespaceAccessDenied)
+	}
+	if user.Role != adminRole {
+		return fmt.Errorf("%w: user %s must be a platform administrator", ErrNamespaceAccessDenied, user.Username)
+	}
+	return nil
+}
+
+// permissionAllows 已授予的权限等级是否满足所需等级，未知等级不满足任何要求
+func permissionAllows(granted, required string) bool {
+	rank := map[string]int{
+		NamespacePermissionRead:  1,
+		NamespacePermissionWrite: 2,
+		NamespacePermissionAdmin: 3,
+	}
+	return rank[granted] > 0 && rank[granted] >= rank[required]
+}
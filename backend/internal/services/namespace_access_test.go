@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPermissionAllows(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{NamespacePermissionRead, NamespacePermissionRead, true},
+		{NamespacePermissionRead, NamespacePermissionWrite, false},
+		{NamespacePermissionWrite, NamespacePermissionRead, true},
+		{NamespacePermissionWrite, NamespacePermissionWrite, true},
+		{NamespacePermissionAdmin, NamespacePermissionWrite, true},
+		{NamespacePermissionWrite, NamespacePermissionAdmin, false},
+		{"owner", NamespacePermissionRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := permissionAllows(tt.granted, tt.required); got != tt.want {
+			t.Errorf("permissionAllows(%s, %s) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeWithoutPermissionTable(t *testing.T) {
+	// 未登录和管理员的判断不查询数据库
+	service := NewNamespaceAccessService(nil)
+
+	err := service.Authorize(context.Background(), "default", NamespacePermissionWrite)
+	if !errors.Is(err, ErrNamespaceAccessDenied) {
+		t.Errorf("Authorize() without user error = %v, want ErrNamespaceAccessDenied", err)
+	}
+
+	ctx := WithPlatformUser(context.Background(), &PlatformUser{ID: 1, Username: "root", Role: "admin"})
+	if err := service.Authorize(ctx, "default", NamespacePermissionAdmin); err != nil {
+		t.Errorf("Authorize() for admin error = %v", err)
+	}
+}
+
+func TestAuthorizePlatform(t *testing.T) {
+	service := NewNamespaceAccessService(nil)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"anonymous", context.Background(), ErrNamespaceAccessDenied},
+		{"user", WithPlatformUser(context.Background(), &PlatformUser{ID: 2, Username: "dev", Role: "user"}), ErrNamespaceAccessDenied},
+		{"admin", WithPlatformUser(context.Background(), &PlatformUser{ID: 1, Username: "root", Role: "admin"}), nil},
+	}
+	for _, tt := range tests {
+		if err := service.AuthorizePlatform(tt.ctx); !errors.Is(err, tt.want) {
+			t.Errorf("AuthorizePlatform() for %s error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"container-platform-backend/internal/model"
+)
+
+// OperationLogService 操作审计日志服务
+type OperationLogService struct {
+	db *gorm.DB
+}
+
+// NewOperationLogService 创建操作审计日志服务
+func NewOperationLogService(db *gorm.DB) *OperationLogService {
+	return &OperationLogService{db: db}
+}
+
+// NewOperationID 生成操作ID
+func NewOperationID() string {
+	return uuid.NewString()
+}
+
+// Record 写入一条操作日志，未设置的操作ID和开始时间会自动补全
+func (s *OperationLogService) Record(entry *model.OperationLog) error {
+	if entry.OperationID == "" {
+		entry.OperationID = NewOperationID()
+	}
+	if entry.StartedAt.IsZero() {
+		entry.StartedAt = time.Now()
+	}
+	if entry.Metadata == nil {
+		entry.Metadata = model.JSONB{}
+	}
+
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record operation log: %w", err)
+	}
+	return nil
+}
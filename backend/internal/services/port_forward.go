@@ -0,0 +1,435 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"container-platform-backend/internal/model"
+)
+
+// defaultPortForwardIdleTimeout 端口转发会话和连接的默认空闲超时时间
+const defaultPortForwardIdleTimeout = 10 * time.Minute
+
+var (
+	// ErrPodNotRunning Pod 未处于运行状态，无法转发端口
+	ErrPodNotRunning = errors.New("pod is not running")
+	// ErrPortForwardIdle 连接空闲超时后被关闭
+	ErrPortForwardIdle = errors.New("port forward closed after idle timeout")
+	// ErrPortForwardNotOwner 端口转发会话只能由创建者或平台管理员使用
+	ErrPortForwardNotOwner = errors.New("port forward session belongs to another user")
+)
+
+// PortForwardDialerFactory 创建 portforward 子资源的流连接器
+type PortForwardDialerFactory func(config *rest.Config, url *url.URL) (httpstream.Dialer, error)
+
+// newSPDYPortForwardDialer 使用 SPDY 协议连接 portforward 子资源
+func newSPDYPortForwardDialer(config *rest.Config, url *url.URL) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url), nil
+}
+
+// PortForwardTarget 端口转发的目标：Pod 中容器声明的 TCP 端口
+type PortForwardTarget struct {
+	Namespace string `json:"namespace"`
+	PodName   string `json:"podName"`
+	Container string `json:"container"`
+	Port      int32  `json:"port"`
+	PortName  string `json:"portName,omitempty"`
+}
+
+// ResolvePortForward 解析端口转发的目标，只允许转发容器声明的 TCP 端口
+// podName 也可以是 Deployment 或 Job 名称，此时转发到其最新创建的 Pod
+func (s *K8sService) ResolvePortForward(ctx context.Context, connection *model.K8sConnection, namespace, podName string, port int32) (*PortForwardTarget, error) {
+	if port < 1 || port > 65535 {
+		return nil, ValidationErrors{{Field: "port", Message: "must be between 1 and 65535"}}
+	}
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	pod := workload.pod
+	if pod == nil {
+		if pod, err = newestWorkloadPod(ctx, clientSet, namespace, workload); err != nil {
+			return nil, err
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, fmt.Errorf("%w: pod %s is %s", ErrPodNotRunning, pod.Name, pod.Status.Phase)
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.ContainerPort != port || (containerPort.Protocol != "" && containerPort.Protocol != corev1.ProtocolTCP) {
+				continue
+			}
+			return &PortForwardTarget{
+				Namespace: pod.Namespace,
+				PodName:   pod.Name,
+				Container: container.Name,
+				Port:      port,
+				PortName:  containerPort.Name,
+			}, nil
+		}
+	}
+	return nil, ValidationErrors{{Field: "port", Message: fmt.Sprintf("TCP port %d is not declared by any container in pod %s", port, pod.Name)}}
+}
+
+// DialPort 通过 portforward 子资源连接 Pod 端口，返回的流在 idleTimeout 内没有读写时自动关闭
+func (s *K8sService) DialPort(ctx context.Context, connection *model.K8sConnection, target *PortForwardTarget, idleTimeout time.Duration) (io.ReadWriteCloser, error) {
+	_, config, err := s.clientAndConfigFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: target.Namespace, Name: target.PodName}}
+	forwardURL, err := podSubresourceURL(config, pod, "portforward", nil)
+	if err != nil {
+		return nil, err
+	}
+	dialer, err := s.portForwarder(config, forwardURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port forward dialer: %w", err)
+	}
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s/%s port %d: %w", target.Namespace, target.PodName, target.Port, err)
+	}
+
+	stream, err := openPortForwardStream(streamConn, target.Port)
+	if err != nil {
+		streamConn.Close()
+		return nil, fmt.Errorf("failed to forward %s/%s port %d: %w", target.Namespace, target.PodName, target.Port, err)
+	}
+	if idleTimeout <= 0 {
+		return stream, nil
+	}
+	return newIdleStream(stream, idleTimeout), nil
+}
+
+// portForwardStream portforward 连接上的一对错误流和数据流，与 kubectl port-forward 的单个连接相同
+type portForwardStream struct {
+	conn   httpstream.Connection
+	data   httpstream.Stream
+	errors chan error
+}
+
+// openPortForwardStream 在连接上为 port 创建错误流和数据流
+func openPortForwardStream(conn httpstream.Connection, port int32) (*portForwardStream, error) {
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(int(port)))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error stream: %w", err)
+	}
+	// 只读取错误流
+	errorStream.Close()
+
+	stream := &portForwardStream{conn: conn, errors: make(chan error, 1)}
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			stream.errors <- fmt.Errorf("failed to read error stream: %w", err)
+		case len(message) > 0:
+			stream.errors <- errors.New(string(message))
+		}
+		close(stream.errors)
+	}()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	stream.data, err = conn.CreateStream(headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data stream: %w", err)
+	}
+	return stream, nil
+}
+
+func (s *portForwardStream) Read(p []byte) (int, error) {
+	n, err := s.data.Read(p)
+	if err == io.EOF {
+		// 远端异常结束时错误流中有原因
+		select {
+		case remoteErr, ok := <-s.errors:
+			if ok && remoteErr != nil {
+				return n, remoteErr
+			}
+		default:
+		}
+	}
+	return n, err
+}
+
+func (s *portForwardStream) Write(p []byte) (int, error) {
+	return s.data.Write(p)
+}
+
+// Close 关闭数据流和底层连接
+func (s *portForwardStream) Close() error {
+	s.data.Close()
+	s.conn.RemoveStreams(s.data)
+	return s.conn.Close()
+}
+
+// idleStream 在 timeout 内没有读写时关闭底层流
+type idleStream struct {
+	io.ReadWriteCloser
+	timeout    time.Duration
+	timer      *time.Timer
+	lastActive atomic.Int64
+	timedOut   atomic.Bool
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+func newIdleStream(stream io.ReadWriteCloser, timeout time.Duration) *idleStream {
+	s := &idleStream{ReadWriteCloser: stream, timeout: timeout}
+	s.lastActive.Store(time.Now().UnixNano())
+	s.timer = time.AfterFunc(timeout, s.expire)
+	return s
+}
+
+// expire 空闲时间未达到 timeout 时按剩余时间重新计时，否则关闭流
+func (s *idleStream) expire() {
+	idle := time.Since(time.Unix(0, s.lastActive.Load()))
+	if idle < s.timeout {
+		s.timer.Reset(s.timeout - idle)
+		return
+	}
+	s.timedOut.Store(true)
+	s.Close()
+}
+
+func (s *idleStream) Read(p []byte) (int, error) {
+	n, err := s.ReadWriteCloser.Read(p)
+	if n > 0 {
+		s.lastActive.Store(time.Now().UnixNano())
+	}
+	if err != nil && s.timedOut.Load() {
+		return n, ErrPortForwardIdle
+	}
+	return n, err
+}
+
+func (s *idleStream) Write(p []byte) (int, error) {
+	n, err := s.ReadWriteCloser.Write(p)
+	if n > 0 {
+		s.lastActive.Store(time.Now().UnixNano())
+	}
+	if err != nil && s.timedOut.Load() {
+		return n, ErrPortForwardIdle
+	}
+	return n, err
+}
+
+func (s *idleStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.timer.Stop()
+		s.closeErr = s.ReadWriteCloser.Close()
+	})
+	return s.closeErr
+}
+
+// PodProxy 创建通过 API Server 代理访问 Pod 端口的 HTTP 反向代理，请求路径即 Pod 中的路径
+// 支持 WebSocket 等协议升级，升级后的连接在 idleTimeout 内没有读写时关闭
+func (s *K8sService) PodProxy(ctx context.Context, connection *model.K8sConnection, target *PortForwardTarget, idleTimeout time.Duration) (*httputil.ReverseProxy, error) {
+	_, config, err := s.clientAndConfigFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proxy transport: %w", err)
+	}
+	base, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster endpoint: %w", err)
+	}
+	proxyPath := path.Join("/", base.Path, "api", "v1", "namespaces", target.Namespace, "pods",
+		fmt.Sprintf("%s:%d", target.PodName, target.Port), "proxy")
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.Out.URL.Scheme = base.Scheme
+			r.Out.URL.Host = base.Host
+			r.Out.URL.Path = proxyPath + "/" + strings.TrimPrefix(r.In.URL.Path, "/")
+			r.Out.URL.RawPath = ""
+			r.Out.Host = ""
+			// 平台的认证信息不能发往集群，客户端也不能自行指定模拟身份
+			r.Out.Header.Del("Authorization")
+			for name := range r.Out.Header {
+				if strings.HasPrefix(name, "Impersonate-") {
+					r.Out.Header.Del(name)
+				}
+			}
+		},
+		// Pod 返回的内容与平台同源，沙箱化后不能读取平台页面的存储或以平台身份发起请求，也不能写入平台域名的 Cookie
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Add("Content-Security-Policy", "sandbox")
+			resp.Header.Del("Set-Cookie")
+			return nil
+		},
+		Transport: &idleTimeoutTransport{transport: transport, timeout: idleTimeout},
+	}, nil
+}
+
+// idleTimeoutTransport 为协议升级后的连接加上空闲超时
+type idleTimeoutTransport struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+}
+
+func (t *idleTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || t.timeout <= 0 || resp.StatusCode != http.StatusSwitchingProtocols {
+		return resp, err
+	}
+	if body, ok := resp.Body.(io.ReadWriteCloser); ok {
+		resp.Body = newIdleStream(body, t.timeout)
+	}
+	return resp, nil
+}
+
+// PortForwardSession 已授权的端口转发会话，在空闲超时前可以多次用于 HTTP 代理和 TCP 隧道
+type PortForwardSession struct {
+	ID          string
+	Connection  *model.K8sConnection
+	Target      PortForwardTarget
+	User        *PlatformUser
+	OperationID string
+	CreatedAt   time.Time
+
+	lastUsed time.Time
+	active   int
+}
+
+// AuthorizeUser 校验 user 是会话的创建者或平台管理员
+func (s *PortForwardSession) AuthorizeUser(user *PlatformUser) error {
+	if user == nil {
+		return fmt.Errorf("%w: authentication required", ErrPortForwardNotOwner)
+	}
+	if user.Role == adminRole || (s.User != nil && s.User.ID == user.ID) {
+		return nil
+	}
+	return fmt.Errorf("%w: user %s did not create session %s", ErrPortForwardNotOwner, user.Username, s.ID)
+}
+
+// PortForwardSessionStore 保存端口转发会话，没有活动连接且超过空闲时间未使用的会话失效
+type PortForwardSessionStore struct {
+	mu          sync.Mutex
+	sessions    map[string]*PortForwardSession
+	idleTimeout time.Duration
+}
+
+// NewPortForwardSessionStore 创建端口转发会话存储，idleTimeout 为 0 时使用默认值
+func NewPortForwardSessionStore(idleTimeout time.Duration) *PortForwardSessionStore {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultPortForwardIdleTimeout
+	}
+	return &PortForwardSessionStore{sessions: make(map[string]*PortForwardSession), idleTimeout: idleTimeout}
+}
+
+// IdleTimeout 会话和连接的空闲超时时间
+func (s *PortForwardSessionStore) IdleTimeout() time.Duration {
+	return s.idleTimeout
+}
+
+// Create 保存会话并分配会话ID
+func (s *PortForwardSessionStore) Create(session *PortForwardSession) *PortForwardSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+
+	session.ID = uuid.NewString()
+	session.CreatedAt = now
+	session.lastUsed = now
+	s.sessions[session.ID] = session
+	return session
+}
+
+// Get 获取会话，不标记为使用中；会话不存在或已过期时返回 false
+func (s *PortForwardSessionStore) Get(id string) (*PortForwardSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(time.Now())
+	session, ok := s.sessions[id]
+	return session, ok
+}
+
+// Acquire 获取会话并标记为使用中，使用结束后需调用 Release；会话不存在或已过期时返回 false
+func (s *PortForwardSessionStore) Acquire(id string) (*PortForwardSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+
+	session, ok := s.sessions[id]
+	if ok {
+		session.active++
+		session.lastUsed = now
+	}
+	return session, ok
+}
+
+// Release 结束一次使用，空闲时间从此刻开始计算
+func (s *PortForwardSessionStore) Release(session *PortForwardSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session.active--
+	session.lastUsed = time.Now()
+}
+
+// Delete 删除会话，已建立的连接不受影响
+func (s *PortForwardSessionStore) Delete(id string) (*PortForwardSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if ok {
+		delete(s.sessions, id)
+	}
+	return session, ok
+}
+
+// evictExpired 删除没有活动连接且已空闲超时的会话，调用方需持有锁
+func (s *PortForwardSessionStore) evictExpired(now time.Time) {
+	for id, session := range s.sessions {
+		if session.active == 0 && now.Sub(session.lastUsed) > s.idleTimeout {
+			delete(s.sessions, id)
+		}
+	}
+}
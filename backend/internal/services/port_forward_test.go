@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolvePortForward(t *testing.T) {
+	deployment, replicaSet, pod := newTestDeployment("web", "default", 1)
+	pod.Status.Phase = corev1.PodRunning
+	pod.Spec.Containers[0].Ports = []corev1.ContainerPort{
+		{Name: "http", ContainerPort: 8080},
+		{Name: "dns", ContainerPort: 53, Protocol: corev1.ProtocolUDP},
+	}
+	pending := newTestPod("pending", "default")
+	pending.Status.Phase = corev1.PodPending
+	pending.Spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 80}}
+	service, _ := newFakeService(t, deployment, replicaSet, pod, pending)
+
+	target, err := service.ResolvePortForward(context.Background(), newTestConnection(), "default", "web", 8080)
+	if err != nil {
+		t.Fatalf("ResolvePortForward() error = %v", err)
+	}
+	want := PortForwardTarget{Namespace: "default", PodName: pod.Name, Container: "app", Port: 8080, PortName: "http"}
+	if *target != want {
+		t.Errorf("target = %+v, want %+v", *target, want)
+	}
+
+	for _, port := range []int32{9090, 53, 0} {
+		_, err := service.ResolvePortForward(context.Background(), newTestConnection(), "default", "web", port)
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) || validationErrs[0].Field != "port" {
+			t.Errorf("ResolvePortForward(%d) error = %v, want port validation error", port, err)
+		}
+	}
+
+	if _, err := service.ResolvePortForward(context.Background(), newTestConnection(), "default", "pending", 80); !errors.Is(err, ErrPodNotRunning) {
+		t.Errorf("ResolvePortForward() on pending pod error = %v, want ErrPodNotRunning", err)
+	}
+}
+
+func TestPodProxy(t *testing.T) {
+	var got *http.Request
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "pod"})
+		w.Write([]byte("ok"))
+	}))
+	defer apiServer.Close()
+
+	service, _ := newFakeService(t)
+	connection := newTestConnection()
+	connection.Endpoint = apiServer.URL
+
+	target := &PortForwardTarget{Namespace: "default", PodName: "web-7d9f8-x2k4p", Port: 8080}
+	proxy, err := service.PodProxy(context.Background(), connection, target, time.Minute)
+	if err != nil {
+		t.Fatalf("PodProxy() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/static/app.js?v=2", nil)
+	req.Header.Set("Authorization", "Bearer platform-token")
+	req.Header.Set("Impersonate-User", "admin")
+	recorder := httptest.NewRecorder()
+	proxy.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok" {
+		t.Fatalf("response = %d %q", recorder.Code, recorder.Body.String())
+	}
+	if got.URL.Path != "/api/v1/namespaces/default/pods/web-7d9f8-x2k4p:8080/proxy/static/app.js" || got.URL.RawQuery != "v=2" {
+		t.Errorf("proxied URL = %s", got.URL)
+	}
+	if auth := got.Header.Get("Authorization"); auth != "Bearer test-token" {
+		t.Errorf("Authorization = %q, want the connection token", auth)
+	}
+	if user := got.Header.Get("Impersonate-User"); user != "" {
+		t.Errorf("Impersonate-User = %q, want it stripped", user)
+	}
+	// Pod 的响应不能以平台的源执行脚本或写入 Cookie
+	if csp := recorder.Header().Get("Content-Security-Policy"); csp != "sandbox" {
+		t.Errorf("Content-Security-Policy = %q, want sandbox", csp)
+	}
+	if cookie := recorder.Header().Get("Set-Cookie"); cookie != "" {
+		t.Errorf("Set-Cookie = %q, want it stripped", cookie)
+	}
+}
+
+func TestIdleStream(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+	stream := newIdleStream(local, 100*time.Millisecond)
+
+	// 持续有数据传输时不会超时
+	go func() {
+		buf := make([]byte, 1)
+		for i := 0; i < 4; i++ {
+			time.Sleep(50 * time.Millisecond)
+			remote.Write([]byte("x"))
+		}
+		remote.Read(buf)
+	}()
+	buf := make([]byte, 1)
+	for i := 0; i < 4; i++ {
+		if _, err := stream.Read(buf); err != nil {
+			t.Fatalf("Read() error = %v before idle timeout", err)
+		}
+	}
+
+	if _, err := stream.Read(buf); !errors.Is(err, ErrPortForwardIdle) {
+		t.Errorf("Read() error = %v, want ErrPortForwardIdle", err)
+	}
+}
+
+func TestPortForwardSessionStore(t *testing.T) {
+	store := NewPortForwardSessionStore(50 * time.Millisecond)
+	idle := store.Create(&PortForwardSession{Target: PortForwardTarget{PodName: "idle"}})
+	busy := store.Create(&PortForwardSession{Target: PortForwardTarget{PodName: "busy"}})
+
+	session, ok := store.Acquire(busy.ID)
+	if !ok || session != busy {
+		t.Fatalf("Acquire() = %v, %v", session, ok)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := store.Acquire(idle.ID); ok {
+		t.Error("idle session should expire")
+	}
+	// 使用中的会话不会过期
+	session, ok = store.Acquire(busy.ID)
+	if !ok {
+		t.Fatal("session in use should not expire")
+	}
+	store.Release(session)
+	store.Release(session)
+
+	if got, ok := store.Get(busy.ID); !ok || got != busy {
+		t.Errorf("Get() = %v, %v", got, ok)
+	}
+	if _, ok := store.Delete(busy.ID); !ok {
+		t.Error("Delete() should remove the session")
+	}
+	if _, ok := store.Acquire(busy.ID); ok {
+		t.Error("deleted session should not be acquired")
+	}
+}
+
+func TestPortForwardSessionAuthorizeUser(t *testing.T) {
+	session := &PortForwardSession{ID: "s1", User: &PlatformUser{ID: 1, Username: "alice", Role: "developer"}}
+
+	tests := []struct {
+		name    string
+		user    *PlatformUser
+		wantErr bool
+	}{
+		{name: "creator", user: &PlatformUser{ID: 1, Username: "alice", Role: "developer"}},
+		{name: "admin", user: &PlatformUser{ID: 2, Username: "root", Role: adminRole}},
+		{name: "other user", user: &PlatformUser{ID: 3, Username: "bob", Role: "developer"}, wantErr: true},
+		{name: "anonymous", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := session.AuthorizeUser(tt.user)
+			if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, ErrPortForwardNotOwner)) {
+				t.Errorf("AuthorizeUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 没有记录创建者的会话只有管理员可以使用
+	anonymous := &PortForwardSession{ID: "s2"}
+	if err := anonymous.AuthorizeUser(&PlatformUser{ID: 1, Username: "alice"}); !errors.Is(err, ErrPortForwardNotOwner) {
+		t.Errorf("AuthorizeUser() error = %v, want ErrPortForwardNotOwner", err)
+	}
+}
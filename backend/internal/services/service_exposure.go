@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"container-platform-backend/internal/k8s"
+	"container-platform-backend/internal/model"
+)
+
+// ErrServiceConflict 同名 Service 已存在且不由平台管理
+var ErrServiceConflict = errors.New("service already exists and is not managed by the platform")
+
+// ExposeSpec 通过 Service 暴露容器端口
+type ExposeSpec struct {
+	// Service 类型：ClusterIP（默认）、NodePort 或 LoadBalancer
+	Type string `json:"type,omitempty"`
+	// 要暴露的端口，为空时暴露容器声明的全部端口
+	Ports []ServicePortSpec `json:"ports,omitempty"`
+}
+
+// ServicePortSpec 暴露的端口
+type ServicePortSpec struct {
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol,omitempty"`
+	// Service 端口，默认与容器端口相同
+	ServicePort int32 `json:"servicePort,omitempty"`
+	// 指定节点端口，仅 NodePort 和 LoadBalancer 类型有效，为空时由集群分配
+	NodePort int32 `json:"nodePort,omitempty"`
+}
+
+// ServiceExposure 容器对应的 Service 及集群分配的地址
+type ServiceExposure struct {
+	ServiceName  string        `json:"serviceName"`
+	Namespace    string        `json:"namespace"`
+	Type         string        `json:"type"`
+	ClusterIP    string        `json:"clusterIp,omitempty"`
+	ExternalIP   string        `json:"externalIp,omitempty"`
+	WorkloadName string        `json:"workloadName"`
+	Ports        []ExposedPort `json:"ports"`
+}
+
+// ExposedPort Service 端口与容器端口的对应关系
+type ExposedPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+	ServicePort   int32  `json:"servicePort"`
+	NodePort      int32  `json:"nodePort,omitempty"`
+}
+
+// serviceType 规范化 Service 类型，默认为 ClusterIP
+func (e *ExposeSpec) serviceType() corev1.ServiceType {
+	if e.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return corev1.ServiceType(e.Type)
+}
+
+// validate 校验暴露配置，declared 不为空时要求端口必须是容器声明的端口
+func (e *ExposeSpec) validate(errs *ValidationErrors, prefix string, declared []PortSpec) {
+	serviceType := e.serviceType()
+	switch serviceType {
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		errs.add(prefix+"type", "unsupported type %q, must be ClusterIP, NodePort or LoadBalancer", e.Type)
+	}
+
+	seen := make(map[string]bool)
+	for i, port := range e.Ports {
+		field := fmt.Sprintf("%sports[%d]", prefix, i)
+		protocol := portProtocol(port.Protocol)
+
+		for _, msg := range validation.IsValidPortNum(int(port.ContainerPort)) {
+			errs.add(field+".containerPort", "%s", msg)
+		}
+		if declared != nil && !declaresPort(declared, port.ContainerPort, protocol) {
+			errs.add(field+".containerPort", "port %d/%s is not declared by the container", port.ContainerPort, protocol)
+		}
+		if port.ServicePort != 0 {
+			for _, msg := range validation.IsValidPortNum(int(port.ServicePort)) {
+				errs.add(field+".servicePort", "%s", msg)
+			}
+		}
+		if port.NodePort != 0 {
+			if serviceType == corev1.ServiceTypeClusterIP {
+				errs.add(field+".nodePort", "is only allowed for NodePort and LoadBalancer services")
+			}
+			for _, msg := range validation.IsValidPortNum(int(port.NodePort)) {
+				errs.add(field+".nodePort", "%s", msg)
+			}
+		}
+
+		servicePort := port.ServicePort
+		if servicePort == 0 {
+			servicePort = port.ContainerPort
+		}
+		key := fmt.Sprintf("%d/%s", servicePort, protocol)
+		if seen[key] {
+			errs.add(field+".servicePort", "duplicate service port %s", key)
+		}
+		seen[key] = true
+	}
+}
+
+func declaresPort(ports []PortSpec, containerPort int32, protocol corev1.Protocol) bool {
+	for _, port := range ports {
+		if port.ContainerPort == containerPort && portProtocol(port.Protocol) == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// ExposeContainer 为容器所属的 Deployment 或独立 Pod 创建或更新同名 Service
+func (s *K8sService) ExposeContainer(ctx context.Context, connection *model.K8sConnection, namespace, podName string, spec *ExposeSpec) (*ServiceExposure, error) {
+	var errs ValidationErrors
+	spec.validate(&errs, "", nil)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	clientSet, err := s.clientFor(ctx, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := resolveWorkload(ctx, clientSet, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName, selector, containers, err := exposureTarget(workload)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := servicePorts(spec, containers)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				appLabel:     serviceName,
+				managedLabel: managedLabelValue,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     spec.serviceType(),
+			Selector: selector,
+			Ports:    ports,
+		},
+	}
+
+	client := k8s.NewClientForClientset(clientSet, nil, namespace)
+	existing, err := client.GetService(ctx, serviceName)
+	switch {
+	case apierrors.IsNotFound(err):
+		service, err = client.CreateService(ctx, service)
+	case err != nil:
+		return nil, err
+	default:
+		if existing.Labels[managedLabel] != managedLabelValue {
+			return nil, fmt.Errorf("%w: %s/%s", ErrServiceConflict, namespace, serviceName)
+		}
+		service, err = client.UpdateService(ctx, mergeService(existing, service))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Exposed %s %s via %s service %s", workload.kind(), workload.name(), service.Spec.Type, service.Name)
+	return serviceExposure(service, workload.name()), nil
+}
+
+// exposureTarget Service 名称、选择器和容器定义；名称与创建容器时的 app 标签一致
+func exposureTarget(workload *workloadRef) (string, map[string]string, []corev1.Container, error) {
+	switch workload.kind() {
+	case WorkloadDeployment:
+		deployment := workload.deployment
+		selector := deployment.Spec.Selector
+		if selector == nil || len(selector.MatchLabels) == 0 || len(selector.MatchExpressions) > 0 {
+			return "", nil, nil, fmt.Errorf("%w: deployment %s must select pods by matchLabels only", ErrUnsupportedWorkload, deployment.Name)
+		}
+		return deployment.Name, selector.MatchLabels, deployment.Spec.Template.Spec.Containers, nil
+	case WorkloadPod:
+		pod := workload.pod
+		if len(pod.Labels) == 0 {
+			return "", nil, nil, fmt.Errorf("%w: pod %s has no labels to select", ErrUnsupportedWorkload, pod.Name)
+		}
+		return exposedServiceName(workload), pod.Labels, pod.Spec.Containers, nil
+	default:
+		return "", nil, nil, fmt.Errorf("%w: cannot expose %s %s", ErrUnsupportedWorkload, workload.kind(), workload.name())
+	}
+}
+
+// exposedServiceName 工作负载对应的 Service 名称，独立 Pod 使用 app 标签（创建时不带 -pod 后缀）
+func exposedServiceName(workload *workloadRef) string {
+	switch workload.kind() {
+	case WorkloadDeployment:
+		return workload.deployment.Name
+	case WorkloadPod:
+		if name := workload.pod.Labels[appLabel]; name != "" {
+			return name
+		}
+		return workload.pod.Name
+	default:
+		return ""
+	}
+}
+
+// servicePorts 根据暴露配置和容器声明的端口构建 Service 端口
+func servicePorts(spec *ExposeSpec, containers []corev1.Container) ([]corev1.ServicePort, error) {
+	var declared []corev1.ContainerPort
+	for _, container := range containers {
+		declared = append(declared, container.Ports...)
+	}
+
+	requested := spec.Ports
+	if len(requested) == 0 {
+		for _, port := range declared {
+			requested = append(requested, ServicePortSpec{ContainerPort: port.ContainerPort, Protocol: string(port.Protocol)})
+		}
+	}
+	if len(requested) == 0 {
+		return nil, ValidationErrors{{Field: "ports", Message: "the container declares no ports to expose"}}
+	}
+
+	var errs ValidationErrors
+	var ports []corev1.ServicePort
+	for i, port := range requested {
+		protocol := portProtocol(port.Protocol)
+
+		var name string
+		found := false
+		for _, containerPort := range declared {
+			if containerPort.ContainerPort == port.ContainerPort && portProtocol(string(containerPort.Protocol)) == protocol {
+				name, found = containerPort.Name, true
+				break
+			}
+		}
+		if !found {
+			errs.add(fmt.Sprintf("ports[%d].containerPort", i), "port %d/%s is not declared by the container", port.ContainerPort, protocol)
+			continue
+		}
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port.ContainerPort)
+		}
+
+		servicePort := port.ServicePort
+		if servicePort == 0 {
+			servicePort = port.ContainerPort
+		}
+		ports = append(ports, corev1.ServicePort{
+			Name:       name,
+			Protocol:   protocol,
+			Port:       servicePort,
+			TargetPort: intstr.FromInt32(port.ContainerPort),
+			NodePort:   port.NodePort,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return ports, nil
+}
+
+// mergeService 在已有 Service 上应用新的定义，保留集群分配的 ClusterIP 和未显式指定的节点端口
+func mergeService(existing, desired *corev1.Service) *corev1.Service {
+	updated := existing.DeepCopy()
+	updated.Labels = desired.Labels
+	updated.Spec.Type = desired.Spec.Type
+	updated.Spec.Selector = desired.Spec.Selector
+
+	keepNodePorts := desired.Spec.Type != corev1.ServiceTypeClusterIP
+	ports := make([]corev1.ServicePort, 0, len(desired.Spec.Ports))
+	for _, port := range desired.Spec.Ports {
+		if keepNodePorts && port.NodePort == 0 {
+			for _, old := range existing.Spec.Ports {
+				if old.Port == port.Port && old.Protocol == port.Protocol {
+					port.NodePort = old.NodePort
+					break
+				}
+			}
+		}
+		if !keepNodePorts {
+			port.NodePort = 0
+		}
+		ports = append(ports, port)
+	}
+	updated.Spec.Ports = ports
+
+	if desired.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		updated.Spec.ExternalTrafficPolicy = ""
+		updated.Spec.HealthCheckNodePort = 0
+		updated.Spec.AllocateLoadBalancerNodePorts = nil
+	}
+	return updated
+}
+
+// serviceExposure 从 Service 读取集群分配的地址和端口
+func serviceExposure(service *corev1.Service, workloadName string) *ServiceExposure {
+	exposure := &ServiceExposure{
+		ServiceName:  service.Name,
+		Namespace:    service.Namespace,
+		Type:         string(service.Spec.Type),
+		ClusterIP:    service.Spec.ClusterIP,
+		WorkloadName: workloadName,
+	}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			exposure.ExternalIP = ingress.IP
+		} else {
+			exposure.ExternalIP = ingress.Hostname
+		}
+		break
+	}
+	for _, port := range service.Spec.Ports {
+		exposure.Ports = append(exposure.Ports, ExposedPort{
+			Name:          port.Name,
+			ContainerPort: port.TargetPort.IntVal,
+			Protocol:      string(port.Protocol),
+			ServicePort:   port.Port,
+			NodePort:      port.NodePort,
+		})
+	}
+	return exposure
+}
+
+// deleteExposedService 删除平台为工作负载创建的 Service，返回被删除的 Service 名称
+func deleteExposedService(ctx context.Context, clientSet kubernetes.Interface, namespace string, workload *workloadRef) (string, error) {
+	name := exposedServiceName(workload)
+	if name == "" {
+		return "", nil
+	}
+
+	client := k8s.NewClientForClientset(clientSet, nil, namespace)
+	service, err := client.GetService(ctx, name)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if service.Labels[managedLabel] != managedLabelValue {
+		return "", nil
+	}
+
+	if err := client.DeleteService(ctx, name); err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+func getService(t *testing.T, clientSet kubernetes.Interface, namespace, name string) *corev1.Service {
+	t.Helper()
+
+	service, err := clientSet.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get service %s: %v", name, err)
+	}
+	return service
+}
+
+// createExposableContainer 创建声明了 http 和 metrics 端口的 Deployment
+func createExposableContainer(t *testing.T, service *K8sService, kind string) {
+	t.Helper()
+
+	req := &CreateContainerRequest{
+		Name:      "web",
+		Namespace: "default",
+		Image:     "nginx",
+		Kind:      kind,
+		Ports:     PortList{{Name: "http", ContainerPort: 8080}, {ContainerPort: 9090}},
+	}
+	if err := service.CreateContainer(context.Background(), newTestConnection(), req); err != nil {
+		t.Fatalf("CreateContainer() error = %v", err)
+	}
+}
+
+func TestExposeContainer(t *testing.T) {
+	service, clientSet := newFakeService(t)
+	createExposableContainer(t, service, WorkloadDeployment)
+
+	spec := &ExposeSpec{Type: "NodePort", Ports: []ServicePortSpec{{ContainerPort: 8080, ServicePort: 80, NodePort: 30080}}}
+	exposure, err := service.ExposeContainer(context.Background(), newTestConnection(), "default", "web", spec)
+	if err != nil {
+		t.Fatalf("ExposeContainer() error = %v", err)
+	}
+
+	want := &ServiceExposure{
+		ServiceName:  "web",
+		Namespace:    "default",
+		Type:         "NodePort",
+		WorkloadName: "web",
+		Ports:        []ExposedPort{{Name: "http", ContainerPort: 8080, Protocol: "TCP", ServicePort: 80, NodePort: 30080}},
+	}
+	if !reflect.DeepEqual(exposure, want) {
+		t.Errorf("ExposeContainer() = %+v, want %+v", exposure, want)
+	}
+
+	created := getService(t, clientSet, "default", "web")
+	if !reflect.DeepEqual(created.Spec.Selector, map[string]string{"app": "web", "managed": "container-platform"}) {
+		t.Errorf("service selector = %v", created.Spec.Selector)
+	}
+	if created.Spec.Ports[0].TargetPort != intstr.FromInt32(8080) {
+		t.Errorf("service target port = %v, want 8080", created.Spec.Ports[0].TargetPort)
+	}
+}
+
+func TestExposeContainerAllPorts(t *testing.T) {
+	service, clientSet := newFakeService(t)
+	createExposableContainer(t, service, WorkloadPod)
+
+	exposure, err := service.ExposeContainer(context.Background(), newTestConnection(), "default", "web-pod", &ExposeSpec{})
+	if err != nil {
+		t.Fatalf("ExposeContainer() error = %v", err)
+	}
+	if exposure.ServiceName != "web" || exposure.WorkloadName != "web-pod" || exposure.Type != "ClusterIP" {
+		t.Errorf("ExposeContainer() = %+v", exposure)
+	}
+
+	var names []string
+	for _, port := range getService(t, clientSet, "default", "web").Spec.Ports {
+		names = append(names, port.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"http", "tcp-9090"}) {
+		t.Errorf("service port names = %v", names)
+	}
+}
+
+func TestExposeContainerUpdatesService(t *testing.T) {
+	ctx := context.Background()
+	service, clientSet := newFakeService(t)
+	createExposableContainer(t, service, WorkloadDeployment)
+
+	if _, err := service.ExposeContainer(ctx, newTestConnection(), "default", "web", &ExposeSpec{Type: "NodePort"}); err != nil {
+		t.Fatalf("ExposeContainer() error = %v", err)
+	}
+
+	// 模拟集群分配的地址和节点端口
+	existing := getService(t, clientSet, "default", "web")
+	existing.Spec.ClusterIP = "10.96.0.10"
+	existing.Spec.Ports[0].NodePort = 31000
+	existing.Spec.Ports[1].NodePort = 31001
+	if _, err := clientSet.CoreV1().Services("default").Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update service: %v", err)
+	}
+
+	spec := &ExposeSpec{Type: "LoadBalancer", Ports: []ServicePortSpec{{ContainerPort: 8080}, {ContainerPort: 9090, NodePort: 32000}}}
+	exposure, err := service.ExposeContainer(ctx, newTestConnection(), "default", "web", spec)
+	if err != nil {
+		t.Fatalf("ExposeContainer() error = %v", err)
+	}
+	if exposure.ClusterIP != "10.96.0.10" || exposure.Ports[0].NodePort != 31000 || exposure.Ports[1].NodePort != 32000 {
+		t.Errorf("ExposeContainer() = %+v, want cluster IP and node port 31000 kept", exposure)
+	}
+
+	exposure, err = service.ExposeContainer(ctx, newTestConnection(), "default", "web", &ExposeSpec{Type: "ClusterIP"})
+	if err != nil {
+		t.Fatalf("ExposeContainer() error = %v", err)
+	}
+	for _, port := range exposure.Ports {
+		if port.NodePort != 0 {
+			t.Errorf("ClusterIP service kept node port %d", port.NodePort)
+		}
+	}
+}
+
+func TestExposeContainerErrors(t *testing.T) {
+	unmanaged := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+	tests := []struct {
+		name    string
+		objects []*corev1.Service
+		spec    *ExposeSpec
+		check   func(error) bool
+	}{
+		{"unsupported type", nil, &ExposeSpec{Type: "ExternalName"}, func(err error) bool { return errors.Is(err, ErrInvalidContainerRequest) }},
+		{"node port on ClusterIP", nil, &ExposeSpec{Ports: []ServicePortSpec{{ContainerPort: 8080, NodePort: 30080}}}, func(err error) bool { return errors.Is(err, ErrInvalidContainerRequest) }},
+		{"undeclared port", nil, &ExposeSpec{Ports: []ServicePortSpec{{ContainerPort: 3000}}}, func(err error) bool { return errors.Is(err, ErrInvalidContainerRequest) }},
+		{"unmanaged service", []*corev1.Service{unmanaged}, &ExposeSpec{}, func(err error) bool { return errors.Is(err, ErrServiceConflict) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, clientSet := newFakeService(t)
+			createExposableContainer(t, service, WorkloadDeployment)
+			for _, object := range tt.objects {
+				if _, err := clientSet.CoreV1().Services("default").Create(context.Background(), object, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create service: %v", err)
+				}
+			}
+
+			_, err := service.ExposeContainer(context.Background(), newTestConnection(), "default", "web", tt.spec)
+			if !tt.check(err) {
+				t.Errorf("ExposeContainer() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateContainerWithExpose(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    CreateContainerRequest
+		fields []string
+	}{
+		{
+			name:   "no ports",
+			req:    CreateContainerRequest{Name: "web", Expose: &ExposeSpec{}},
+			fields: []string{"expose"},
+		},
+		{
+			name:   "undeclared port",
+			req:    CreateContainerRequest{Name: "web", Ports: PortList{{ContainerPort: 80}}, Expose: &ExposeSpec{Ports: []ServicePortSpec{{ContainerPort: 443}}}},
+			fields: []string{"expose.ports[0].containerPort"},
+		},
+		{
+			name:   "invalid service name",
+			req:    CreateContainerRequest{Name: "1web", Ports: PortList{{ContainerPort: 80}}, Expose: &ExposeSpec{}},
+			fields: []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Namespace, req.Image = "default", "nginx"
+
+			var validationErrs ValidationErrors
+			if !errors.As(req.Validate(), &validationErrs) {
+				t.Fatalf("Validate() succeeded, want field errors %v", tt.fields)
+			}
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Validate() fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestDeleteContainerRemovesService(t *testing.T) {
+	ctx := context.Background()
+	service, clientSet := newFakeService(t)
+	createExposableContainer(t, service, WorkloadDeployment)
+
+	if _, err := service.ExposeContainer(ctx, newTestConnection(), "default", "web", &ExposeSpec{}); err != nil {
+		t.Fatalf("ExposeContainer() error = %v", err)
+	}
+
+	deleted, err := service.DeleteContainer(ctx, newTestConnection(), "default", "web")
+	if err != nil {
+		t.Fatalf("DeleteContainer() error = %v", err)
+	}
+	if deleted.Service != "web" {
+		t.Errorf("deleted service = %q, want web", deleted.Service)
+	}
+	if _, err := clientSet.CoreV1().Services("default").Get(ctx, "web", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("service still exists after delete: %v", err)
+	}
+}
+
+func TestDeleteContainerKeepsUnmanagedService(t *testing.T) {
+	ctx := context.Background()
+	unmanaged := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	service, clientSet := newFakeService(t, unmanaged)
+	createExposableContainer(t, service, WorkloadDeployment)
+
+	deleted, err := service.DeleteContainer(ctx, newTestConnection(), "default", "web")
+	if err != nil {
+		t.Fatalf("DeleteContainer() error = %v", err)
+	}
+	if deleted.Service != "" {
+		t.Errorf("deleted service = %q, want none", deleted.Service)
+	}
+	getService(t, clientSet, "default", "web")
+}
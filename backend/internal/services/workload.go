@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// 容器的工作负载类型
+const (
+	// WorkloadDeployment 由 Deployment 管理，停止/启动通过伸缩实现（默认）
+	WorkloadDeployment = "deployment"
+	// WorkloadPod 独立的 Pod，仅支持创建和删除
+	WorkloadPod = "pod"
+	// WorkloadJob 由 Job 管理，暂停通过 suspend 实现
+	WorkloadJob = "job"
+	// WorkloadCronJob 由 CronJob 管理，暂停通过 suspend 实现
+	WorkloadCronJob = "cronjob"
+)
+
+const (
+	appLabel          = "app"
+	managedLabel      = "managed"
+	managedLabelValue = "container-platform"
+
+	// replicasAnnotation 停止/暂停前的副本数，启动/恢复时还原
+	replicasAnnotation = "container-platform/replicas"
+	// pausedAtAnnotation 暂停时间，用于区分暂停和停止
+	pausedAtAnnotation = "container-platform/paused-at"
+	// restartedAtAnnotation 与 kubectl rollout restart 相同的触发方式
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+	defaultReplicas int32 = 1
+)
+
+var (
+	// ErrUnsupportedWorkload 工作负载不支持该操作（如独立 Pod 无法停止后再启动）
+	ErrUnsupportedWorkload = errors.New("operation is not supported for this workload")
+	// ErrInvalidContainerRequest 创建容器请求无效
+	ErrInvalidContainerRequest = errors.New("invalid container request")
+	// ErrContainerNotPaused 容器未处于暂停状态
+	ErrContainerNotPaused = errors.New("container is not paused")
+)
+
+// ContainerWorkload 操作实际作用的工作负载
+type ContainerWorkload struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// DeletedContainer 被删除的工作负载及随之删除的 Service
+type DeletedContainer struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Service string `json:"service,omitempty"`
+}
+
+// workloadRef 容器所属的工作负载
+// 已停止/暂停的工作负载没有 Pod，此时 pod 为空；deployment、job、cronJob 至多一个非空。
+type workloadRef struct {
+	pod        *corev1.Pod
+	deployment *appsv1.Deployment
+	job        *batchv1.Job
+	cronJob    *batchv1.CronJob
+}
+
+// kind 工作负载类型
+func (w *workloadRef) kind() string {
+	switch {
+	case w.deployment != nil:
+		return WorkloadDeployment
+	case w.cronJob != nil:
+		return WorkloadCronJob
+	case w.job != nil:
+		return WorkloadJob
+	default:
+		return WorkloadPod
+	}
+}
+
+// name 工作负载名称
+func (w *workloadRef) name() string {
+	switch {
+	case w.deployment != nil:
+		return w.deployment.Name
+	case w.cronJob != nil:
+		return w.cronJob.Name
+	case w.job != nil:
+		return w.job.Name
+	default:
+		return w.pod.Name
+	}
+}
+
+// resolveWorkload 根据名称解析工作负载：先按 Pod 查找并沿 ownerReferences 找到所属的
+// Deployment/Job/CronJob，找不到 Pod 时依次按 Deployment、Job、CronJob 名称查找
+func resolveWorkload(ctx context.Context, clientSet kubernetes.Interface, namespace, name string) (*workloadRef, error) {
+	pod, err := clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return podWorkload(ctx, clientSet, pod)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	notFound := fmt.Errorf("container %s/%s not found: %w", namespace, name, err)
+
+	deployment, lookupErr := clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if lookupErr == nil {
+		return &workloadRef{deployment: deployment}, nil
+	}
+	if !apierrors.IsNotFound(lookupErr) {
+		return nil, fmt.Errorf("failed to get deployment: %w", lookupErr)
+	}
+
+	job, lookupErr := clientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if lookupErr == nil {
+		return &workloadRef{job: job}, nil
+	}
+	if !apierrors.IsNotFound(lookupErr) && !apierrors.IsForbidden(lookupErr) {
+		return nil, fmt.Errorf("failed to get job: %w", lookupErr)
+	}
+
+	cronJob, lookupErr := clientSet.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if lookupErr == nil {
+		return &workloadRef{cronJob: cronJob}, nil
+	}
+	if !apierrors.IsNotFound(lookupErr) && !apierrors.IsForbidden(lookupErr) {
+		return nil, fmt.Errorf("failed to get cronjob: %w", lookupErr)
+	}
+
+	return nil, notFound
+}
+
+// podWorkload 沿 Pod 的 ownerReferences 找到所属工作负载，属主已不存在时视为独立 Pod
+func podWorkload(ctx context.Context, clientSet kubernetes.Interface, pod *corev1.Pod) (*workloadRef, error) {
+	workload := &workloadRef{pod: pod}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return workload, nil
+	}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		replicaSet, err := clientSet.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return ignoreNotFound(workload, err, "replicaset")
+		}
+		owner = metav1.GetControllerOf(replicaSet)
+		if owner == nil || owner.Kind != "Deployment" {
+			return workload, nil
+		}
+		deployment, err := clientSet.AppsV1().Deployments(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return ignoreNotFound(workload, err, "deployment")
+		}
+		workload.deployment = deployment
+	case "Job":
+		job, err := clientSet.BatchV1().Jobs(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return ignoreNotFound(workload, err, "job")
+		}
+		// CronJob 创建的 Job 以 CronJob 作为暂停/删除的对象
+		if owner = metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+			cronJob, err := clientSet.BatchV1().CronJobs(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+			if err == nil {
+				workload.cronJob = cronJob
+				return workload, nil
+			}
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get cronjob: %w", err)
+			}
+		}
+		workload.job = job
+	}
+
+	return workload, nil
+}
+
+// ignoreNotFound 属主不存在时返回已解析的部分，其他错误原样返回
+func ignoreNotFound(workload *workloadRef, err error, kind string) (*workloadRef, error) {
+	if apierrors.IsNotFound(err) {
+		return workload, nil
+	}
+	return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+}
+
+// updateDeployment 读取最新的 Deployment 并修改，冲突时重试
+func updateDeployment(ctx context.Context, clientSet kubernetes.Interface, namespace, name string, mutate func(*appsv1.Deployment)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deployment, err := clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		mutate(deployment)
+		_, err = clientSet.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// scaleToZero 记录当前副本数并缩容到 0，paused 为 true 时标记为暂停，否则视为停止
+func scaleToZero(ctx context.Context, clientSet kubernetes.Interface, namespace, name string, paused bool) error {
+	return updateDeployment(ctx, clientSet, namespace, name, func(deployment *appsv1.Deployment) {
+		if deployment.Annotations == nil {
+			deployment.Annotations = make(map[string]string)
+		}
+		if replicas := deploymentReplicas(deployment); replicas > 0 {
+			deployment.Annotations[replicasAnnotation] = strconv.Itoa(int(replicas))
+		}
+		if paused {
+			if deployment.Annotations[pausedAtAnnotation] == "" {
+				deployment.Annotations[pausedAtAnnotation] = time.Now().Format(time.RFC3339)
+			}
+		} else {
+			delete(deployment.Annotations, pausedAtAnnotation)
+		}
+		zero := int32(0)
+		deployment.Spec.Replicas = &zero
+	})
+}
+
+// scaleToRemembered 恢复停止/暂停前记录的副本数并清除暂停标记，已在运行时不修改副本数
+func scaleToRemembered(ctx context.Context, clientSet kubernetes.Interface, namespace, name string) error {
+	return updateDeployment(ctx, clientSet, namespace, name, func(deployment *appsv1.Deployment) {
+		delete(deployment.Annotations, pausedAtAnnotation)
+		if deploymentReplicas(deployment) > 0 {
+			return
+		}
+		replicas := rememberedReplicas(deployment)
+		deployment.Spec.Replicas = &replicas
+	})
+}
+
+// rolloutRestart 修改 Pod 模板注解触发滚动重启
+func rolloutRestart(ctx context.Context, clientSet kubernetes.Interface, namespace, name string) error {
+	return updateDeployment(ctx, clientSet, namespace, name, func(deployment *appsv1.Deployment) {
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = make(map[string]string)
+		}
+		deployment.Spec.Template.Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+	})
+}
+
+// setJobSuspended 暂停/恢复 Job
+func setJobSuspended(ctx context.Context, clientSet kubernetes.Interface, namespace, name string, suspend bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		job, err := clientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		job.Spec.Suspend = &suspend
+		_, err = clientSet.BatchV1().Jobs(namespace).Update(ctx, job, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// setCronJobSuspended 暂停/恢复 CronJob 的调度
+func setCronJobSuspended(ctx context.Context, clientSet kubernetes.Interface, namespace, name string, suspend bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cronJob, err := clientSet.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		cronJob.Spec.Suspend = &suspend
+		_, err = clientSet.BatchV1().CronJobs(namespace).Update(ctx, cronJob, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// deploymentReplicas Deployment 期望的副本数，未设置时为 1
+func deploymentReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return defaultReplicas
+	}
+	return *deployment.Spec.Replicas
+}
+
+// rememberedReplicas 停止前记录的副本数，没有有效记录时为 1
+func rememberedReplicas(deployment *appsv1.Deployment) int32 {
+	value, err := strconv.Atoi(deployment.Annotations[replicasAnnotation])
+	if err != nil || value <= 0 {
+		return defaultReplicas
+	}
+	return int32(value)
+}
+
+// deploymentPaused Deployment 是否处于暂停状态
+func deploymentPaused(deployment *appsv1.Deployment) bool {
+	return deployment.Annotations[pausedAtAnnotation] != "" && deploymentReplicas(deployment) == 0
+}
+
+// isSuspended Job/CronJob 的 suspend 字段是否为 true
+func isSuspended(suspend *bool) bool {
+	return suspend != nil && *suspend
+}
+
+// workloadIndex 命名空间内的工作负载及 Pod 到工作负载的归属
+type workloadIndex struct {
+	deployments    []*appsv1.Deployment
+	jobs           []*batchv1.Job
+	cronJobs       []*batchv1.CronJob
+	byReplicaSet   map[string]*appsv1.Deployment
+	jobsByName     map[string]*batchv1.Job
+	cronJobsByName map[string]*batchv1.CronJob
+}
+
+// listWorkloads 列出命名空间内的 Deployment、ReplicaSet、Job 和 CronJob
+// 没有权限列出某类资源时跳过该类资源，容器列表仍按 Pod 返回。
+func listWorkloads(ctx context.Context, clientSet kubernetes.Interface, namespace string) *workloadIndex {
+	index := &workloadIndex{
+		byReplicaSet:   make(map[string]*appsv1.Deployment),
+		jobsByName:     make(map[string]*batchv1.Job),
+		cronJobsByName: make(map[string]*batchv1.CronJob),
+	}
+
+	if deployments, err := clientSet.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		log.Printf("Warning: failed to list deployments in namespace %q: %v", namespace, err)
+	} else {
+		byName := make(map[string]*appsv1.Deployment, len(deployments.Items))
+		for i := range deployments.Items {
+			deployment := &deployments.Items[i]
+			index.deployments = append(index.deployments, deployment)
+			byName[deployment.Namespace+"/"+deployment.Name] = deployment
+		}
+
+		if replicaSets, err := clientSet.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+			log.Printf("Warning: failed to list replicasets in namespace %q: %v", namespace, err)
+		} else {
+			for _, replicaSet := range replicaSets.Items {
+				owner := metav1.GetControllerOf(&replicaSet)
+				if owner == nil || owner.Kind != "Deployment" {
+					continue
+				}
+				if deployment, ok := byName[replicaSet.Namespace+"/"+owner.Name]; ok {
+					index.byReplicaSet[replicaSet.Namespace+"/"+replicaSet.Name] = deployment
+				}
+			}
+		}
+	}
+
+	if jobs, err := clientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		log.Printf("Warning: failed to list jobs in namespace %q: %v", namespace, err)
+	} else {
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			index.jobs = append(index.jobs, job)
+			index.jobsByName[job.Namespace+"/"+job.Name] = job
+		}
+	}
+
+	if cronJobs, err := clientSet.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{}); err != nil {
+		log.Printf("Warning: failed to list cronjobs in namespace %q: %v", namespace, err)
+	} else {
+		for i := range cronJobs.Items {
+			cronJob := &cronJobs.Items[i]
+			index.cronJobs = append(index.cronJobs, cronJob)
+			index.cronJobsByName[cronJob.Namespace+"/"+cronJob.Name] = cronJob
+		}
+	}
+
+	return index
+}
+
+// ownerOf Pod 所属的工作负载，独立 Pod 只返回 pod
+func (i *workloadIndex) ownerOf(pod *corev1.Pod) *workloadRef {
+	workload := &workloadRef{pod: pod}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return workload
+	}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		workload.deployment = i.byReplicaSet[pod.Namespace+"/"+owner.Name]
+	case "Job":
+		job, ok := i.jobsByName[pod.Namespace+"/"+owner.Name]
+		if !ok {
+			return workload
+		}
+		if jobOwner := metav1.GetControllerOf(job); jobOwner != nil && jobOwner.Kind == "CronJob" {
+			if cronJob, ok := i.cronJobsByName[pod.Namespace+"/"+jobOwner.Name]; ok {
+				workload.cronJob = cronJob
+				return workload
+			}
+		}
+		workload.job = job
+	}
+	return workload
+}